@@ -34,6 +34,7 @@ func (c *demoClient) showcase(ctx context.Context) error {
 		// c.animeSeasonal,
 		// c.animeSuggested,
 		// c.animeListForLoop, // Warning: Many requests.
+		// c.animeListIterator, // Warning: Many requests.
 		// c.updateMyAnimeListStatus,
 		// c.userAnimeList,
 		// c.deleteMyAnimeListItem,
@@ -125,7 +126,7 @@ func (c *demoClient) animeDetails(ctx context.Context) {
 	fmt.Printf("%s\n", a.Title)
 	fmt.Printf("ID: %d\n", a.ID)
 	fmt.Printf("English: %s\n", a.AlternativeTitles.En)
-	fmt.Printf("Type: %s\n", strings.ToUpper(a.MediaType))
+	fmt.Printf("Type: %s\n", a.MediaType)
 	fmt.Printf("Episodes: %d\n", a.NumEpisodes)
 	fmt.Printf("Premiered: %s %d\n", strings.Title(a.StartSeason.Season), a.StartSeason.Year)
 	fmt.Print("Studios: ")
@@ -135,7 +136,7 @@ func (c *demoClient) animeDetails(ctx context.Context) {
 		delim = " "
 	}
 	fmt.Println()
-	fmt.Printf("Source: %s\n", strings.Title(a.Source))
+	fmt.Printf("Source: %s\n", a.Source)
 	fmt.Print("Genres: ")
 	delim = ""
 	for _, g := range a.Genres {
@@ -168,7 +169,7 @@ func (c *demoClient) mangaDetails(ctx context.Context) {
 	fmt.Printf("%s\n", m.Title)
 	fmt.Printf("ID: %d\n", m.ID)
 	fmt.Printf("English: %s\n", m.AlternativeTitles.En)
-	fmt.Printf("Type: %s\n", strings.Title(m.MediaType))
+	fmt.Printf("Type: %s\n", m.MediaType)
 	fmt.Printf("Volumes: %d\n", m.NumVolumes)
 	fmt.Printf("Chapters: %d\n", m.NumChapters)
 	fmt.Print("Studios: ")
@@ -185,7 +186,7 @@ func (c *demoClient) mangaDetails(ctx context.Context) {
 		delim = " "
 	}
 	fmt.Println()
-	fmt.Printf("Status: %s\n", strings.Title(m.Status))
+	fmt.Printf("Status: %s\n", m.Status)
 }
 
 func (c *demoClient) animeListForLoop(ctx context.Context) {
@@ -215,6 +216,24 @@ func (c *demoClient) animeListForLoop(ctx context.Context) {
 	}
 }
 
+func (c *demoClient) animeListIterator(ctx context.Context) {
+	if c.err != nil {
+		return
+	}
+	it := c.Anime.ListIterator("kiseijuu",
+		mal.Fields{"rank", "popularity", "start_season"},
+		mal.Limit(100),
+	)
+	for it.Next(ctx) {
+		a := it.Value()
+		fmt.Printf("ID: %5d, Rank: %5d, Popularity: %5d %s (%d)\n", a.ID, a.Rank, a.Popularity, a.Title, a.StartSeason.Year)
+	}
+	if err := it.Err(); err != nil {
+		c.err = err
+		return
+	}
+}
+
 func (c *demoClient) userAnimeList(ctx context.Context) {
 	if c.err != nil {
 		return
@@ -262,8 +281,8 @@ func (c *demoClient) updateMyAnimeListStatus(ctx context.Context) {
 		mal.NumEpisodesWatched(73),
 		mal.Score(8),
 		mal.Comments("You wa shock!"),
-		mal.StartDate(time.Date(2022, 02, 20, 0, 0, 0, 0, time.UTC)),
-		mal.FinishDate(time.Time{}), // Remove an existing date.
+		mal.StartDate{Year: 2022, Month: time.February, Day: 20},
+		mal.FinishDate{}, // Remove an existing date.
 	)
 	if err != nil {
 		c.err = err
@@ -281,8 +300,8 @@ func (c *demoClient) updateMyMangaListStatus(ctx context.Context) {
 		mal.NumVolumesRead(1),
 		mal.NumChaptersRead(5),
 		mal.Comments("Migi"),
-		mal.StartDate(time.Date(2022, 02, 20, 0, 0, 0, 0, time.UTC)),
-		mal.FinishDate(time.Time{}), // Remove an existing date.
+		mal.StartDate{Year: 2022, Month: time.February, Day: 20},
+		mal.FinishDate{}, // Remove an existing date.
 	)
 	if err != nil {
 		c.err = err
@@ -1,19 +1,15 @@
 package main
 
 import (
-	"bufio"
 	"context"
-	"crypto/rand"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
-	"os/exec"
-	"runtime"
 
 	"github.com/nstratos/go-myanimelist/mal"
-	"golang.org/x/oauth2"
+	"github.com/nstratos/go-myanimelist/malauth"
 )
 
 func main() {
@@ -27,10 +23,13 @@ func main() {
 //
 //  1. Navigate to https://myanimelist.net/apiconfig or go to your MyAnimeList
 //     profile, click Edit Profile and select the API tab on the far right.
-//  2. Click Create ID and submit the form with your application details.
+//  2. Click Create ID and submit the form with your application details. Use
+//     http://localhost:8080/callback as the App Redirect URL or pass yours
+//     with the -redirect-url flag.
 const (
 	defaultClientID     = ""
 	defaultClientSecret = ""
+	defaultRedirectURL  = "http://localhost:8080/callback"
 )
 
 // Authorization Documentation:
@@ -41,17 +40,16 @@ func run() error {
 	var (
 		clientID     = flag.String("client-id", defaultClientID, "your registered MyAnimeList.net application client ID")
 		clientSecret = flag.String("client-secret", defaultClientSecret, "your registered MyAnimeList.net application client secret; optional if you chose App Type 'other'")
-		// state is a token to protect the user from CSRF attacks. In a web
-		// application, you should provide a non-empty string and validate that
-		// it matches the state query parameter on the redirect URL callback
-		// after the MyAnimeList authentication. It can stay empty here.
-		state = flag.String("state", "", "token to protect against CSRF attacks")
+		// redirectURL must match the App Redirect URL of your application. A
+		// local server listens on it to capture the authorization code after
+		// the user allows the application.
+		redirectURL = flag.String("redirect-url", defaultRedirectURL, "your registered MyAnimeList.net application redirect URL; must be a loopback http URL")
 	)
 	flag.Parse()
 
 	ctx := context.Background()
 
-	tokenClient, err := authenticate(ctx, *clientID, *clientSecret, *state)
+	tokenClient, err := authenticate(ctx, *clientID, *clientSecret, *redirectURL)
 	if err != nil {
 		return err
 	}
@@ -63,129 +61,49 @@ func run() error {
 	return c.showcase(ctx)
 }
 
-func authenticate(ctx context.Context, clientID, clientSecret, state string) (*http.Client, error) {
-	// Prepare the oauth2 configuration with your application ID, secret, the
-	// MyAnimeList authentication and token URLs as specified in:
-	//
-	// https://myanimelist.net/apiconfig/references/authorization
-	conf := &oauth2.Config{
-		ClientID:     clientID,
-		ClientSecret: clientSecret,
-		Endpoint: oauth2.Endpoint{
-			AuthURL:   "https://myanimelist.net/v1/oauth2/authorize",
-			TokenURL:  "https://myanimelist.net/v1/oauth2/token",
-			AuthStyle: oauth2.AuthStyleInParams,
-		},
-	}
-
-	oauth2Token, err := loadCachedToken()
-	if err == nil {
-		refreshedToken, err := conf.TokenSource(ctx, oauth2Token).Token()
-		if err == nil && (oauth2Token != refreshedToken) {
-			fmt.Println("Caching refreshed oauth2 token...")
-			if err := cacheToken(*refreshedToken); err != nil {
-				return nil, fmt.Errorf("caching refreshed oauth2 token: %s", err)
-			}
-			return conf.Client(ctx, refreshedToken), nil
-		}
-		return conf.Client(ctx, oauth2Token), nil
-	}
+// tokenDir is the directory where the oauth2 token is stored so that the
+// example can run multiple times without authenticating again.
+const (
+	tokenDir = "auth-example-tokens"
+	tokenKey = "example"
+)
 
-	// Generate a code verifier, a high-entropy cryptographic random string. It
-	// will be set as the code_challenge in the authentication URL. It should
-	// have a minimum length of 43 characters and a maximum length of 128
-	// characters.
-	const codeVerifierLength = 128
-	codeVerifier, err := generateCodeVerifier(codeVerifierLength)
-	if err != nil {
-		return nil, fmt.Errorf("generating code verifier: %v", err)
-	}
+func authenticate(ctx context.Context, clientID, clientSecret, redirectURL string) (*http.Client, error) {
+	conf := malauth.NewConfig(clientID, clientSecret, redirectURL)
 
-	// Produce the authentication URL where the user needs to be redirected and
-	// allow your application to access their MyAnimeList data.
-	authURL := conf.AuthCodeURL(state,
-		oauth2.SetAuthURLParam("code_challenge", codeVerifier),
-	)
-	err = openBrowser(authURL)
+	store, err := malauth.NewFileTokenStore(tokenDir)
 	if err != nil {
-		fmt.Println("Could not open browser.")
-	}
-
-	fmt.Printf("Your browser should open: %v\n", authURL)
-	fmt.Print("After authenticating, copy the code from the browser URL and paste it here: ")
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	code := scanner.Text()
-	if scanner.Err() != nil {
-		return nil, fmt.Errorf("reading code from terminal: %v", err)
+		return nil, err
 	}
 
-	// Exchange the authentication code for a token. MyAnimeList currently only
-	// supports the plain code_challenge_method so to verify the string, just
-	// make sure it is the same as the one you entered in the code_challenge.
-	token, err := conf.Exchange(ctx, code,
-		oauth2.SetAuthURLParam("code_verifier", codeVerifier),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("exchanging code for token: %v", err)
-	}
-	fmt.Println("Authentication was successful. Caching oauth2 token...")
-	if err := cacheToken(*token); err != nil {
-		return nil, fmt.Errorf("caching oauth2 token: %s", err)
-	}
-
-	return conf.Client(ctx, token), nil
-}
-
-const cacheName = "auth-example-token-cache.txt"
-
-func cacheToken(token oauth2.Token) error {
-	b, err := json.MarshalIndent(token, "", "   ")
-	if err != nil {
-		return fmt.Errorf("marshaling token %s: %v", token, err)
+	// The client refreshes the token when it expires and saves the refreshed
+	// token to the store.
+	client, err := malauth.NewClient(ctx, conf, store, tokenKey)
+	if err == nil {
+		return client, nil
 	}
-	err = os.WriteFile(cacheName, b, 0644)
-	if err != nil {
-		return fmt.Errorf("writing token %s to file %q: %v", token, cacheName, err)
+	if !errors.Is(err, malauth.ErrTokenNotFound) {
+		return nil, err
 	}
-	return nil
-}
 
-func loadCachedToken() (*oauth2.Token, error) {
-	b, err := os.ReadFile(cacheName)
+	// Open the authorization URL in the browser and wait for MyAnimeList to
+	// redirect the user back to the redirect URL after they allow the
+	// application.
+	token, err := malauth.Authenticate(ctx, conf, func(authURL string) error {
+		if err := malauth.OpenBrowser(authURL); err != nil {
+			fmt.Println("Could not open browser.")
+		}
+		fmt.Printf("Your browser should open: %v\n", authURL)
+		fmt.Println("Waiting for authorization...")
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("reading oauth2 token from cache file %q: %v", cacheName, err)
+		return nil, err
 	}
-	token := new(oauth2.Token)
-	if err := json.Unmarshal(b, token); err != nil {
-		return nil, fmt.Errorf("unmarshaling oauth2 token: %v", err)
+	fmt.Println("Authentication was successful. Storing oauth2 token...")
+	if err := store.Save(tokenKey, token); err != nil {
+		return nil, err
 	}
-	return token, nil
-}
 
-func generateCodeVerifier(length int) (string, error) {
-	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ" +
-		"abcdefghijklmnopqrstvuwxyz" +
-		"0123456789-._~"
-	bytes := make([]byte, length)
-	if _, err := rand.Read(bytes); err != nil {
-		return "", err
-	}
-	for i, b := range bytes {
-		bytes[i] = charset[b%byte(len(charset))]
-	}
-	return string(bytes), nil
-}
-
-func openBrowser(url string) error {
-	switch runtime.GOOS {
-	case "linux":
-		return exec.Command("xdg-open", url).Start()
-	case "windows":
-		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
-	case "darwin":
-		return exec.Command("open", url).Start()
-	default:
-		return fmt.Errorf("openBrowser: unsupported operating system: %v", runtime.GOOS)
-	}
+	return malauth.NewClient(ctx, conf, store, tokenKey)
 }
@@ -0,0 +1,218 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// AnimeFields are the fields to request when getting an anime list that will
+// be written with WriteAnimeList.
+var AnimeFields = mal.Fields{
+	"media_type",
+	"num_episodes",
+	"list_status{status,score,num_episodes_watched,is_rewatching,start_date,finish_date,priority,num_times_rewatched,rewatch_value,tags,comments}",
+}
+
+type animeDocument struct {
+	XMLName xml.Name     `xml:"myanimelist"`
+	MyInfo  animeInfo    `xml:"myinfo"`
+	Anime   []animeEntry `xml:"anime"`
+}
+
+type animeInfo struct {
+	UserID           int64  `xml:"user_id"`
+	UserName         string `xml:"user_name"`
+	ExportType       int    `xml:"user_export_type"`
+	TotalAnime       int    `xml:"user_total_anime"`
+	TotalWatching    int    `xml:"user_total_watching"`
+	TotalCompleted   int    `xml:"user_total_completed"`
+	TotalOnHold      int    `xml:"user_total_onhold"`
+	TotalDropped     int    `xml:"user_total_dropped"`
+	TotalPlanToWatch int    `xml:"user_total_plantowatch"`
+}
+
+// animeEntry is an anime element. The fields that can be empty in the files
+// that MyAnimeList produces are strings.
+type animeEntry struct {
+	ID              int    `xml:"series_animedb_id"`
+	Title           cdata  `xml:"series_title"`
+	Type            string `xml:"series_type"`
+	Episodes        int    `xml:"series_episodes"`
+	MyID            int    `xml:"my_id"`
+	WatchedEpisodes string `xml:"my_watched_episodes"`
+	StartDate       string `xml:"my_start_date"`
+	FinishDate      string `xml:"my_finish_date"`
+	Rated           string `xml:"my_rated"`
+	Score           string `xml:"my_score"`
+	Storage         string `xml:"my_storage"`
+	StorageValue    string `xml:"my_storage_value"`
+	Status          string `xml:"my_status"`
+	Comments        cdata  `xml:"my_comments"`
+	TimesWatched    string `xml:"my_times_watched"`
+	RewatchValue    string `xml:"my_rewatch_value"`
+	Priority        string `xml:"my_priority"`
+	Tags            cdata  `xml:"my_tags"`
+	Rewatching      string `xml:"my_rewatching"`
+	RewatchingEp    string `xml:"my_rewatching_ep"`
+	Discuss         string `xml:"my_discuss"`
+	SNS             string `xml:"my_sns"`
+	UpdateOnImport  string `xml:"update_on_import"`
+}
+
+var animeStatusLabels = map[mal.AnimeStatus]string{
+	mal.AnimeStatusWatching:    "Watching",
+	mal.AnimeStatusCompleted:   "Completed",
+	mal.AnimeStatusOnHold:      "On-Hold",
+	mal.AnimeStatusDropped:     "Dropped",
+	mal.AnimeStatusPlanToWatch: "Plan to Watch",
+}
+
+// animeStatusCodes are the numeric statuses of older export files.
+var animeStatusCodes = map[string]mal.AnimeStatus{
+	"1": mal.AnimeStatusWatching,
+	"2": mal.AnimeStatusCompleted,
+	"3": mal.AnimeStatusOnHold,
+	"4": mal.AnimeStatusDropped,
+	"6": mal.AnimeStatusPlanToWatch,
+}
+
+func parseAnimeStatus(s string) (mal.AnimeStatus, error) {
+	status := mal.AnimeStatus(normalize(s))
+	if _, ok := animeStatusLabels[status]; ok || status == "" {
+		return status, nil
+	}
+	if status, ok := animeStatusCodes[strings.TrimSpace(s)]; ok {
+		return status, nil
+	}
+	return "", errUnknownStatus
+}
+
+// WriteAnimeList writes the anime list of user to w as an export file. The
+// list needs to have the AnimeFields.
+func WriteAnimeList(w io.Writer, user mal.User, list []mal.UserAnime) error {
+	doc := animeDocument{
+		MyInfo: animeInfo{
+			UserID:     user.ID,
+			UserName:   user.Name,
+			ExportType: exportTypeAnime,
+			TotalAnime: len(list),
+		},
+		Anime: make([]animeEntry, len(list)),
+	}
+	for i, a := range list {
+		switch a.Status.Status {
+		case mal.AnimeStatusWatching:
+			doc.MyInfo.TotalWatching++
+		case mal.AnimeStatusCompleted:
+			doc.MyInfo.TotalCompleted++
+		case mal.AnimeStatusOnHold:
+			doc.MyInfo.TotalOnHold++
+		case mal.AnimeStatusDropped:
+			doc.MyInfo.TotalDropped++
+		case mal.AnimeStatusPlanToWatch:
+			doc.MyInfo.TotalPlanToWatch++
+		}
+		s := a.Status
+		rewatching := "0"
+		if s.IsRewatching {
+			rewatching = "1"
+		}
+		doc.Anime[i] = animeEntry{
+			ID:              a.Anime.ID,
+			Title:           cdata{a.Anime.Title},
+			Type:            a.Anime.MediaType.String(),
+			Episodes:        a.Anime.NumEpisodes,
+			WatchedEpisodes: strconv.Itoa(s.NumEpisodesWatched),
+			StartDate:       formatDate(s.StartDate),
+			FinishDate:      formatDate(s.FinishDate),
+			Score:           strconv.Itoa(s.Score),
+			StorageValue:    "0.00",
+			Status:          animeStatusLabels[s.Status],
+			Comments:        cdata{s.Comments},
+			TimesWatched:    strconv.Itoa(s.NumTimesRewatched),
+			RewatchValue:    formatRepeatValue(s.RewatchValue),
+			Priority:        formatPriority(s.Priority),
+			Tags:            cdata{formatTags(s.Tags)},
+			Rewatching:      rewatching,
+			RewatchingEp:    "0",
+			Discuss:         "1",
+			SNS:             "default",
+			// Importing the file updates entries that are already in
+			// the list.
+			UpdateOnImport: "1",
+		}
+	}
+	return write(w, doc)
+}
+
+// ReadAnimeList reads an anime export file from r. The returned entries have
+// the ID, title, media type and number of episodes of the anime and the status
+// of the anime in the list, except for UpdatedAt which exports do not include.
+func ReadAnimeList(r io.Reader) ([]mal.UserAnime, error) {
+	var doc animeDocument
+	if err := read(r, &doc, &doc.MyInfo.ExportType, exportTypeAnime); err != nil {
+		return nil, err
+	}
+	list := make([]mal.UserAnime, len(doc.Anime))
+	for i, e := range doc.Anime {
+		var p parser
+		status, err := parseAnimeStatus(e.Status)
+		if err != nil {
+			p.fail("my_status", e.Status, err)
+		}
+		list[i] = mal.UserAnime{
+			Anime: mal.Anime{
+				ID:          e.ID,
+				Title:       e.Title.Text,
+				MediaType:   mal.AnimeMediaType(normalize(e.Type)),
+				NumEpisodes: e.Episodes,
+			},
+			Status: mal.AnimeListStatus{
+				Status:             status,
+				Score:              p.int("my_score", e.Score),
+				NumEpisodesWatched: p.int("my_watched_episodes", e.WatchedEpisodes),
+				IsRewatching:       p.bool("my_rewatching", e.Rewatching),
+				Priority:           p.priority("my_priority", e.Priority),
+				NumTimesRewatched:  p.int("my_times_watched", e.TimesWatched),
+				RewatchValue:       p.repeatValue("my_rewatch_value", e.RewatchValue),
+				Tags:               parseTags(e.Tags.Text),
+				Comments:           e.Comments.Text,
+				StartDate:          p.date("my_start_date", e.StartDate),
+				FinishDate:         p.date("my_finish_date", e.FinishDate),
+			},
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("export: anime %d: %w", e.ID, p.err)
+		}
+	}
+	return list, nil
+}
+
+// AnimeUpdateOptions returns the options that make an entry of the anime list
+// of the authenticated user equal to s when passed to
+// AnimeService.UpdateMyListStatus. Every field is set, so existing values are
+// replaced, including tags, comments and dates which are removed if they are
+// empty in s.
+func AnimeUpdateOptions(s mal.AnimeListStatus) []mal.UpdateMyAnimeListStatusOption {
+	var opts []mal.UpdateMyAnimeListStatusOption
+	if s.Status != "" {
+		opts = append(opts, s.Status)
+	}
+	return append(opts,
+		mal.Score(s.Score),
+		mal.NumEpisodesWatched(s.NumEpisodesWatched),
+		mal.IsRewatching(s.IsRewatching),
+		mal.Priority(s.Priority),
+		mal.NumTimesRewatched(s.NumTimesRewatched),
+		mal.RewatchValue(s.RewatchValue),
+		mal.Tags(s.Tags),
+		mal.Comments(s.Comments),
+		mal.StartDate(s.StartDate),
+		mal.FinishDate(s.FinishDate),
+	)
+}
@@ -0,0 +1,234 @@
+// Package export reads and writes anime and manga lists in the XML format of
+// the "Export My List" feature of MyAnimeList, which is also the format that
+// its "Import List" feature accepts, and writes them as CSV and JSON Lines
+// flat files.
+//
+// A list that was requested with the AnimeFields or MangaFields of this package
+// can be written as an export file:
+//
+//	var list []mal.UserAnime
+//	it := c.User.AnimeListIterator("@me", export.AnimeFields, mal.Limit(1000))
+//	for it.Next(ctx) {
+//		list = append(list, it.Value())
+//	}
+//	// Check it.Err() ...
+//	user, _, err := c.User.MyInfo(ctx)
+//	// ...
+//	err = export.WriteAnimeList(w, *user, list)
+//
+// An export file can be read back and applied to the list of the
+// authenticated user:
+//
+//	list, err := export.ReadAnimeList(r)
+//	// ...
+//	for _, a := range list {
+//		_, _, err := c.Anime.UpdateMyListStatus(ctx, a.Anime.ID, export.AnimeUpdateOptions(a.Status)...)
+//		// ...
+//	}
+//
+// StreamAnimeList and StreamMangaList page through a whole list and write each
+// entry to a CSVWriter or JSONLinesWriter with the selected columns as soon as
+// its page arrives:
+//
+//	w, err := export.NewCSVWriter(f, export.ColumnID, export.ColumnTitle, export.ColumnScore)
+//	// ...
+//	err = export.StreamAnimeList(ctx, c, "@me", w)
+package export
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// The values of user_export_type that tell anime and manga exports apart.
+const (
+	exportTypeAnime = 1
+	exportTypeManga = 2
+)
+
+// cdata is text that is written as a CDATA section, like MyAnimeList does for
+// titles, comments and tags.
+type cdata struct {
+	Text string `xml:",cdata"`
+}
+
+// write writes the XML header and doc to w.
+func write(w io.Writer, doc interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// read decodes an export from r into doc and checks that its export type, if
+// present, is the wanted one.
+func read(r io.Reader, doc interface{}, exportType *int, want int) error {
+	if err := xml.NewDecoder(r).Decode(doc); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	if *exportType != 0 && *exportType != want {
+		return fmt.Errorf("export: file has export type %d, want %d", *exportType, want)
+	}
+	return nil
+}
+
+// formatDate formats d in the format of export files, where missing parts are
+// zero, e.g. 2017-10-00.
+func formatDate(d mal.Date) string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, int(d.Month), d.Day)
+}
+
+// parseDate parses a date of an export file.
+func parseDate(s string) (mal.Date, error) {
+	// Drop the missing day and month.
+	s = strings.TrimSuffix(strings.TrimSpace(s), "-00")
+	s = strings.TrimSuffix(s, "-00")
+	if s == "0000" {
+		s = ""
+	}
+	return mal.ParseDate(s)
+}
+
+// formatTags joins tags the way MyAnimeList does.
+func formatTags(tags []string) string { return strings.Join(tags, ", ") }
+
+// parseTags splits comma separated tags, dropping empty ones.
+func parseTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// normalize converts a label of an export file, such as "Plan to Watch" or
+// "One-shot", to the form of the API values, such as "plan_to_watch".
+func normalize(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(s)
+}
+
+var priorities = []string{"LOW", "MEDIUM", "HIGH"}
+
+func formatPriority(p int) string {
+	if p < 0 || p >= len(priorities) {
+		return priorities[0]
+	}
+	return priorities[p]
+}
+
+func parsePriority(s string) (int, error) {
+	for i, p := range priorities {
+		if strings.EqualFold(strings.TrimSpace(s), p) {
+			return i, nil
+		}
+	}
+	return parseInt(s)
+}
+
+// repeatValues are the labels of the rewatch and reread values 1-5.
+var repeatValues = []string{"Very Low", "Low", "Medium", "High", "Very High"}
+
+func formatRepeatValue(v int) string {
+	if v < 1 || v > len(repeatValues) {
+		return ""
+	}
+	return repeatValues[v-1]
+}
+
+func parseRepeatValue(s string) (int, error) {
+	for i, v := range repeatValues {
+		if strings.EqualFold(strings.TrimSpace(s), v) {
+			return i + 1, nil
+		}
+	}
+	return parseInt(s)
+}
+
+// parseInt parses an integer, with an empty string being zero.
+func parseInt(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// parseBool parses the flags of export files which can be 0 and 1 or NO and
+// YES, with an empty string being false.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "0", "no", "false":
+		return false, nil
+	case "1", "yes", "true":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid flag %q", s)
+}
+
+var errUnknownStatus = errors.New("unknown status")
+
+// parser parses the fields of an entry, keeping the first error.
+type parser struct {
+	err error
+}
+
+func (p *parser) fail(field, value string, err error) {
+	if p.err == nil {
+		p.err = fmt.Errorf("%s %q: %w", field, value, err)
+	}
+}
+
+func (p *parser) int(field, s string) int {
+	n, err := parseInt(s)
+	if err != nil {
+		p.fail(field, s, err)
+	}
+	return n
+}
+
+func (p *parser) bool(field, s string) bool {
+	b, err := parseBool(s)
+	if err != nil {
+		p.fail(field, s, err)
+	}
+	return b
+}
+
+func (p *parser) date(field, s string) mal.Date {
+	d, err := parseDate(s)
+	if err != nil {
+		p.fail(field, s, err)
+	}
+	return d
+}
+
+func (p *parser) priority(field, s string) int {
+	n, err := parsePriority(s)
+	if err != nil {
+		p.fail(field, s, err)
+	}
+	return n
+}
+
+func (p *parser) repeatValue(field, s string) int {
+	n, err := parseRepeatValue(s)
+	if err != nil {
+		p.fail(field, s, err)
+	}
+	return n
+}
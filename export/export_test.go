@@ -0,0 +1,288 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/mal"
+	"github.com/nstratos/go-myanimelist/maltest"
+)
+
+var testAnimeList = []mal.UserAnime{
+	{
+		Anime: mal.Anime{ID: 1, Title: "Cowboy Bebop", MediaType: mal.AnimeMediaTypeTV, NumEpisodes: 26},
+		Status: mal.AnimeListStatus{
+			Status:             mal.AnimeStatusCompleted,
+			Score:              9,
+			NumEpisodesWatched: 26,
+			Priority:           2,
+			NumTimesRewatched:  1,
+			RewatchValue:       5,
+			Tags:               []string{"space", "jazz"},
+			Comments:           "See you <space> cowboy & co.",
+			StartDate:          mal.Date{Year: 2017, Month: time.October, Day: 4},
+			FinishDate:         mal.Date{Year: 2017, Month: time.November},
+		},
+	},
+	{
+		Anime:  mal.Anime{ID: 5, Title: "Cowboy Bebop: Tengoku no Tobira", MediaType: mal.AnimeMediaTypeMovie, NumEpisodes: 1},
+		Status: mal.AnimeListStatus{Status: mal.AnimeStatusPlanToWatch},
+	},
+}
+
+func TestWriteAnimeList(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteAnimeList(&buf, mal.User{ID: 42, Name: "spike"}, testAnimeList[:1]); err != nil {
+		t.Fatalf("WriteAnimeList returned error: %v", err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<myanimelist>
+	<myinfo>
+		<user_id>42</user_id>
+		<user_name>spike</user_name>
+		<user_export_type>1</user_export_type>
+		<user_total_anime>1</user_total_anime>
+		<user_total_watching>0</user_total_watching>
+		<user_total_completed>1</user_total_completed>
+		<user_total_onhold>0</user_total_onhold>
+		<user_total_dropped>0</user_total_dropped>
+		<user_total_plantowatch>0</user_total_plantowatch>
+	</myinfo>
+	<anime>
+		<series_animedb_id>1</series_animedb_id>
+		<series_title><![CDATA[Cowboy Bebop]]></series_title>
+		<series_type>TV</series_type>
+		<series_episodes>26</series_episodes>
+		<my_id>0</my_id>
+		<my_watched_episodes>26</my_watched_episodes>
+		<my_start_date>2017-10-04</my_start_date>
+		<my_finish_date>2017-11-00</my_finish_date>
+		<my_rated></my_rated>
+		<my_score>9</my_score>
+		<my_storage></my_storage>
+		<my_storage_value>0.00</my_storage_value>
+		<my_status>Completed</my_status>
+		<my_comments><![CDATA[See you <space> cowboy & co.]]></my_comments>
+		<my_times_watched>1</my_times_watched>
+		<my_rewatch_value>Very High</my_rewatch_value>
+		<my_priority>HIGH</my_priority>
+		<my_tags><![CDATA[space, jazz]]></my_tags>
+		<my_rewatching>0</my_rewatching>
+		<my_rewatching_ep>0</my_rewatching_ep>
+		<my_discuss>1</my_discuss>
+		<my_sns>default</my_sns>
+		<update_on_import>1</update_on_import>
+	</anime>
+</myanimelist>
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteAnimeList output\nhave:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestAnimeListRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteAnimeList(&buf, mal.User{ID: 42, Name: "spike"}, testAnimeList); err != nil {
+		t.Fatalf("WriteAnimeList returned error: %v", err)
+	}
+	got, err := ReadAnimeList(&buf)
+	if err != nil {
+		t.Fatalf("ReadAnimeList returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, testAnimeList) {
+		t.Errorf("ReadAnimeList returned\nhave: %+v\n\nwant: %+v", got, testAnimeList)
+	}
+}
+
+func TestReadAnimeList(t *testing.T) {
+	// An export in the style of MyAnimeList, with empty and numeric values.
+	const in = `<?xml version="1.0" encoding="UTF-8" ?>
+<!--
+ Created by XML Export feature at MyAnimeList.net
+ Version 1.1.0
+-->
+<myanimelist>
+	<myinfo>
+		<user_id>42</user_id>
+		<user_name>spike</user_name>
+		<user_export_type>1</user_export_type>
+		<user_total_anime>1</user_total_anime>
+	</myinfo>
+	<anime>
+		<series_animedb_id>30</series_animedb_id>
+		<series_title><![CDATA[Shinseiki Evangelion]]></series_title>
+		<series_type>TV</series_type>
+		<series_episodes>26</series_episodes>
+		<my_id>0</my_id>
+		<my_watched_episodes>12</my_watched_episodes>
+		<my_start_date>2020-00-00</my_start_date>
+		<my_finish_date>0000-00-00</my_finish_date>
+		<my_rated></my_rated>
+		<my_score>0</my_score>
+		<my_status>3</my_status>
+		<my_comments><![CDATA[]]></my_comments>
+		<my_times_watched>0</my_times_watched>
+		<my_rewatch_value></my_rewatch_value>
+		<my_priority>Medium</my_priority>
+		<my_tags><![CDATA[mecha,, classic ]]></my_tags>
+		<my_rewatching>1</my_rewatching>
+		<update_on_import>0</update_on_import>
+	</anime>
+</myanimelist>`
+	got, err := ReadAnimeList(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("ReadAnimeList returned error: %v", err)
+	}
+	want := []mal.UserAnime{{
+		Anime: mal.Anime{ID: 30, Title: "Shinseiki Evangelion", MediaType: mal.AnimeMediaTypeTV, NumEpisodes: 26},
+		Status: mal.AnimeListStatus{
+			Status:             mal.AnimeStatusOnHold,
+			NumEpisodesWatched: 12,
+			IsRewatching:       true,
+			Priority:           1,
+			Tags:               []string{"mecha", "classic"},
+			StartDate:          mal.Date{Year: 2020},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadAnimeList returned\nhave: %+v\n\nwant: %+v", got, want)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		read func(s string) error
+	}{
+		{
+			name: "manga file read as anime",
+			in:   `<myanimelist><myinfo><user_export_type>2</user_export_type></myinfo></myanimelist>`,
+			read: func(s string) error { _, err := ReadAnimeList(strings.NewReader(s)); return err },
+		},
+		{
+			name: "anime file read as manga",
+			in:   `<myanimelist><myinfo><user_export_type>1</user_export_type></myinfo></myanimelist>`,
+			read: func(s string) error { _, err := ReadMangaList(strings.NewReader(s)); return err },
+		},
+		{
+			name: "invalid XML",
+			in:   `<myanimelist><anime>`,
+			read: func(s string) error { _, err := ReadAnimeList(strings.NewReader(s)); return err },
+		},
+		{
+			name: "unknown status",
+			in:   `<myanimelist><anime><series_animedb_id>1</series_animedb_id><my_status>Rewatching</my_status></anime></myanimelist>`,
+			read: func(s string) error { _, err := ReadAnimeList(strings.NewReader(s)); return err },
+		},
+		{
+			name: "invalid score",
+			in:   `<myanimelist><manga><manga_mangadb_id>1</manga_mangadb_id><my_score>ten</my_score></manga></myanimelist>`,
+			read: func(s string) error { _, err := ReadMangaList(strings.NewReader(s)); return err },
+		},
+		{
+			name: "invalid date",
+			in:   `<myanimelist><anime><series_animedb_id>1</series_animedb_id><my_start_date>2020-13-01</my_start_date></anime></myanimelist>`,
+			read: func(s string) error { _, err := ReadAnimeList(strings.NewReader(s)); return err },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.read(tt.in)
+			if err == nil {
+				t.Fatal("expected error, got none")
+			}
+			if !strings.HasPrefix(err.Error(), "export: ") {
+				t.Errorf("error %q does not have the package prefix", err)
+			}
+		})
+	}
+}
+
+var testMangaList = []mal.UserManga{
+	{
+		Manga: mal.Manga{ID: 2, Title: "Berserk", NumVolumes: 0, NumChapters: 0},
+		Status: mal.MangaListStatus{
+			Status:          mal.MangaStatusReading,
+			IsRereading:     true,
+			NumVolumesRead:  41,
+			NumChaptersRead: 364,
+			Score:           10,
+			NumTimesReread:  2,
+			RereadValue:     3,
+			Tags:            []string{"dark fantasy"},
+			Comments:        "Struggler",
+			StartDate:       mal.Date{Year: 2005},
+		},
+	},
+	{
+		Manga:  mal.Manga{ID: 21, Title: "Death Note", NumVolumes: 12, NumChapters: 108},
+		Status: mal.MangaListStatus{Status: mal.MangaStatusDropped, Priority: 1},
+	},
+}
+
+func TestMangaListRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMangaList(&buf, mal.User{ID: 42, Name: "guts"}, testMangaList); err != nil {
+		t.Fatalf("WriteMangaList returned error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<user_export_type>2</user_export_type>",
+		"<user_total_manga>2</user_total_manga>",
+		"<user_total_reading>1</user_total_reading>",
+		"<user_total_dropped>1</user_total_dropped>",
+		"<manga_title><![CDATA[Berserk]]></manga_title>",
+		"<my_status>Reading</my_status>",
+		"<my_rereading>YES</my_rereading>",
+		"<my_reread_value>Medium</my_reread_value>",
+		"<my_start_date>2005-00-00</my_start_date>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteMangaList output does not contain %q:\n%s", want, out)
+		}
+	}
+	got, err := ReadMangaList(&buf)
+	if err != nil {
+		t.Fatalf("ReadMangaList returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, testMangaList) {
+		t.Errorf("ReadMangaList returned\nhave: %+v\n\nwant: %+v", got, testMangaList)
+	}
+}
+
+func TestUpdateOptions(t *testing.T) {
+	s := maltest.NewServer()
+	defer s.Close()
+	s.AddAnime(mal.Anime{ID: 1, Title: "Cowboy Bebop", NumEpisodes: 26})
+	s.AddManga(mal.Manga{ID: 2, Title: "Berserk"})
+	s.AddUser(mal.User{Name: "alice"})
+	// Existing values are replaced.
+	s.SetAnimeListStatus("alice", 1, mal.AnimeListStatus{Status: mal.AnimeStatusDropped, Tags: []string{"old"}, Comments: "old"})
+
+	ctx := context.Background()
+	c := s.Client("alice")
+	want := testAnimeList[0].Status
+	if _, _, err := c.Anime.UpdateMyListStatus(ctx, 1, AnimeUpdateOptions(want)...); err != nil {
+		t.Fatalf("UpdateMyListStatus returned error: %v", err)
+	}
+	got, _ := s.AnimeListStatus("alice", 1)
+	got.UpdatedAt = time.Time{}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("anime list status after update\nhave: %+v\n\nwant: %+v", got, want)
+	}
+
+	wantManga := testMangaList[0].Status
+	if _, _, err := c.Manga.UpdateMyListStatus(ctx, 2, MangaUpdateOptions(wantManga)...); err != nil {
+		t.Fatalf("UpdateMyListStatus returned error: %v", err)
+	}
+	gotManga, _ := s.MangaListStatus("alice", 2)
+	gotManga.UpdatedAt = time.Time{}
+	if !reflect.DeepEqual(gotManga, wantManga) {
+		t.Errorf("manga list status after update\nhave: %+v\n\nwant: %+v", gotManga, wantManga)
+	}
+}
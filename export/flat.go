@@ -0,0 +1,368 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// Column is a column of the flat files written by CSVWriter and
+// JSONLinesWriter. In JSON Lines files it is the key of the value.
+type Column string
+
+// The columns of flat files.
+const (
+	ColumnID         Column = "id"
+	ColumnTitle      Column = "title"
+	ColumnMediaType  Column = "media_type"
+	ColumnGenres     Column = "genres"
+	ColumnStatus     Column = "status"
+	ColumnScore      Column = "score"
+	ColumnProgress   Column = "progress" // Episodes watched or chapters read.
+	ColumnStartDate  Column = "start_date"
+	ColumnFinishDate Column = "finish_date"
+	ColumnUpdatedAt  Column = "updated_at"
+	ColumnTags       Column = "tags"
+	ColumnComments   Column = "comments"
+)
+
+// DefaultColumns are the columns used when a writer is created without any.
+var DefaultColumns = []Column{
+	ColumnID,
+	ColumnTitle,
+	ColumnMediaType,
+	ColumnGenres,
+	ColumnStatus,
+	ColumnScore,
+	ColumnProgress,
+	ColumnStartDate,
+	ColumnFinishDate,
+	ColumnUpdatedAt,
+	ColumnTags,
+	ColumnComments,
+}
+
+var knownColumns = func() map[Column]bool {
+	m := make(map[Column]bool, len(DefaultColumns))
+	for _, c := range DefaultColumns {
+		m[c] = true
+	}
+	return m
+}()
+
+// checkColumns returns DefaultColumns if columns is empty or an error if it
+// has an unknown column.
+func checkColumns(columns []Column) ([]Column, error) {
+	if len(columns) == 0 {
+		return DefaultColumns, nil
+	}
+	for _, c := range columns {
+		if !knownColumns[c] {
+			return nil, fmt.Errorf("export: unknown column %q", c)
+		}
+	}
+	return columns, nil
+}
+
+// record is an entry of an anime or manga list with the values of the
+// columns.
+type record struct {
+	id         int
+	title      string
+	mediaType  string
+	genres     []string
+	status     string
+	score      int
+	progress   int
+	startDate  mal.Date
+	finishDate mal.Date
+	updatedAt  time.Time
+	tags       []string
+	comments   string
+}
+
+func genreNames(genres []mal.Genre) []string {
+	names := make([]string, len(genres))
+	for i, g := range genres {
+		names[i] = g.Name
+	}
+	return names
+}
+
+func animeRecord(a mal.UserAnime) record {
+	return record{
+		id:         a.Anime.ID,
+		title:      a.Anime.Title,
+		mediaType:  string(a.Anime.MediaType),
+		genres:     genreNames(a.Anime.Genres),
+		status:     string(a.Status.Status),
+		score:      a.Status.Score,
+		progress:   a.Status.NumEpisodesWatched,
+		startDate:  a.Status.StartDate,
+		finishDate: a.Status.FinishDate,
+		updatedAt:  a.Status.UpdatedAt,
+		tags:       a.Status.Tags,
+		comments:   a.Status.Comments,
+	}
+}
+
+func mangaRecord(m mal.UserManga) record {
+	return record{
+		id:         m.Manga.ID,
+		title:      m.Manga.Title,
+		mediaType:  string(m.Manga.MediaType),
+		genres:     genreNames(m.Manga.Genres),
+		status:     string(m.Status.Status),
+		score:      m.Status.Score,
+		progress:   m.Status.NumChaptersRead,
+		startDate:  m.Status.StartDate,
+		finishDate: m.Status.FinishDate,
+		updatedAt:  m.Status.UpdatedAt,
+		tags:       m.Status.Tags,
+		comments:   m.Status.Comments,
+	}
+}
+
+// value returns the value of column c. Lists are []string, dates and times
+// are strings which are empty if they are not set.
+func (r record) value(c Column) interface{} {
+	switch c {
+	case ColumnID:
+		return r.id
+	case ColumnTitle:
+		return r.title
+	case ColumnMediaType:
+		return r.mediaType
+	case ColumnGenres:
+		return nonNil(r.genres)
+	case ColumnStatus:
+		return r.status
+	case ColumnScore:
+		return r.score
+	case ColumnProgress:
+		return r.progress
+	case ColumnStartDate:
+		return r.startDate.String()
+	case ColumnFinishDate:
+		return r.finishDate.String()
+	case ColumnUpdatedAt:
+		if r.updatedAt.IsZero() {
+			return ""
+		}
+		return r.updatedAt.Format(time.RFC3339)
+	case ColumnTags:
+		return nonNil(r.tags)
+	case ColumnComments:
+		return r.comments
+	}
+	return nil
+}
+
+// nonNil returns an empty slice instead of nil so that it is encoded as an
+// empty JSON array.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// RowWriter writes the entries of anime and manga lists as the rows of a flat
+// file. It is implemented by CSVWriter and JSONLinesWriter.
+type RowWriter interface {
+	WriteAnime(a mal.UserAnime) error
+	WriteManga(m mal.UserManga) error
+	// Flush writes any buffered data to the underlying writer.
+	Flush() error
+}
+
+// CSVWriter writes list entries as CSV records, with a header record with the
+// names of the columns. Lists such as tags and genres are joined with commas.
+type CSVWriter struct {
+	w       *csv.Writer
+	columns []Column
+	header  bool // Whether the header has been written.
+}
+
+// NewCSVWriter returns a CSVWriter that writes the given columns to w, or the
+// DefaultColumns if none are given.
+func NewCSVWriter(w io.Writer, columns ...Column) (*CSVWriter, error) {
+	columns, err := checkColumns(columns)
+	if err != nil {
+		return nil, err
+	}
+	return &CSVWriter{w: csv.NewWriter(w), columns: columns}, nil
+}
+
+// WriteAnime writes an entry of an anime list.
+func (cw *CSVWriter) WriteAnime(a mal.UserAnime) error { return cw.write(animeRecord(a)) }
+
+// WriteManga writes an entry of a manga list.
+func (cw *CSVWriter) WriteManga(m mal.UserManga) error { return cw.write(mangaRecord(m)) }
+
+func (cw *CSVWriter) writeHeader() error {
+	if cw.header {
+		return nil
+	}
+	cw.header = true
+	names := make([]string, len(cw.columns))
+	for i, c := range cw.columns {
+		names[i] = string(c)
+	}
+	return cw.w.Write(names)
+}
+
+func (cw *CSVWriter) write(r record) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	fields := make([]string, len(cw.columns))
+	for i, c := range cw.columns {
+		switch v := r.value(c).(type) {
+		case int:
+			fields[i] = strconv.Itoa(v)
+		case []string:
+			fields[i] = formatTags(v)
+		case string:
+			fields[i] = v
+		}
+	}
+	return cw.w.Write(fields)
+}
+
+// Flush writes any buffered data to the underlying writer. The header is
+// written even if there were no entries.
+func (cw *CSVWriter) Flush() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// JSONLinesWriter writes list entries as JSON objects, one per line, with the
+// columns as keys in the given order. Lists such as tags and genres are JSON
+// arrays.
+type JSONLinesWriter struct {
+	w       *bufio.Writer
+	columns []Column
+}
+
+// NewJSONLinesWriter returns a JSONLinesWriter that writes the given columns
+// to w, or the DefaultColumns if none are given.
+func NewJSONLinesWriter(w io.Writer, columns ...Column) (*JSONLinesWriter, error) {
+	columns, err := checkColumns(columns)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLinesWriter{w: bufio.NewWriter(w), columns: columns}, nil
+}
+
+// WriteAnime writes an entry of an anime list.
+func (jw *JSONLinesWriter) WriteAnime(a mal.UserAnime) error { return jw.write(animeRecord(a)) }
+
+// WriteManga writes an entry of a manga list.
+func (jw *JSONLinesWriter) WriteManga(m mal.UserManga) error { return jw.write(mangaRecord(m)) }
+
+func (jw *JSONLinesWriter) write(r record) error {
+	// The object is built by hand since encoding/json sorts the keys of
+	// maps.
+	line := []byte{'{'}
+	for i, c := range jw.columns {
+		if i > 0 {
+			line = append(line, ',')
+		}
+		key, _ := json.Marshal(string(c))
+		value, err := json.Marshal(r.value(c))
+		if err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+		line = append(append(append(line, key...), ':'), value...)
+	}
+	line = append(line, '}', '\n')
+	_, err := jw.w.Write(line)
+	return err
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (jw *JSONLinesWriter) Flush() error { return jw.w.Flush() }
+
+// The fields that flat files need, which include all the list status fields.
+// Unlike AnimeFields and MangaFields they include updated_at, which the XML
+// exports leave out.
+var (
+	animeRowFields = mal.Fields{
+		"media_type",
+		"num_episodes",
+		"genres",
+		"list_status{status,score,num_episodes_watched,is_rewatching,start_date,finish_date,priority,num_times_rewatched,rewatch_value,tags,comments,updated_at}",
+	}
+	mangaRowFields = mal.Fields{
+		"media_type",
+		"num_volumes",
+		"num_chapters",
+		"genres",
+		"list_status{status,score,num_volumes_read,num_chapters_read,is_rereading,start_date,finish_date,priority,num_times_reread,reread_value,tags,comments,updated_at}",
+	}
+)
+
+// pageSize is the number of entries requested per page when streaming a list.
+const pageSize = 1000
+
+// StreamAnimeList pages through the whole anime list of the user indicated by
+// username (or use @me) and writes each entry to rw as soon as its page
+// arrives, so the list is never held in memory. It accepts the same options
+// as UserService.AnimeList, for example to only write the entries with an
+// AnimeStatus. Pages of 1000 entries are requested unless the Limit option is
+// passed. A Fields option replaces the fields that the columns need. rw is
+// flushed at the end, even if an error occurred.
+func StreamAnimeList(ctx context.Context, c *mal.Client, username string, rw RowWriter, options ...mal.AnimeListOption) (err error) {
+	defer flush(rw, &err)
+	options = append([]mal.AnimeListOption{animeRowFields, mal.Limit(pageSize)}, options...)
+	it := c.User.AnimeListIterator(username, options...)
+	for it.Next(ctx) {
+		if err := rw.WriteAnime(it.Value()); err != nil {
+			return err
+		}
+	}
+	if err := it.Err(); err != nil {
+		return fmt.Errorf("export: getting anime list: %w", err)
+	}
+	return nil
+}
+
+// StreamMangaList pages through the whole manga list of the user indicated by
+// username (or use @me) and writes each entry to rw as soon as its page
+// arrives, so the list is never held in memory. It accepts the same options
+// as UserService.MangaList, for example to only write the entries with a
+// MangaStatus. Pages of 1000 entries are requested unless the Limit option is
+// passed. A Fields option replaces the fields that the columns need. rw is
+// flushed at the end, even if an error occurred.
+func StreamMangaList(ctx context.Context, c *mal.Client, username string, rw RowWriter, options ...mal.MangaListOption) (err error) {
+	defer flush(rw, &err)
+	options = append([]mal.MangaListOption{mangaRowFields, mal.Limit(pageSize)}, options...)
+	it := c.User.MangaListIterator(username, options...)
+	for it.Next(ctx) {
+		if err := rw.WriteManga(it.Value()); err != nil {
+			return err
+		}
+	}
+	if err := it.Err(); err != nil {
+		return fmt.Errorf("export: getting manga list: %w", err)
+	}
+	return nil
+}
+
+// flush flushes rw and sets *err to the error of Flush if it is nil.
+func flush(rw RowWriter, err *error) {
+	if ferr := rw.Flush(); *err == nil {
+		*err = ferr
+	}
+}
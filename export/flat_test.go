@@ -0,0 +1,167 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/mal"
+	"github.com/nstratos/go-myanimelist/maltest"
+)
+
+var flatAnime = mal.UserAnime{
+	Anime: mal.Anime{
+		ID:        1,
+		Title:     `Cowboy "Bebop", Session 1`,
+		MediaType: mal.AnimeMediaTypeTV,
+		Genres:    []mal.Genre{{ID: 1, Name: "Action"}, {ID: 24, Name: "Sci-Fi"}},
+	},
+	Status: mal.AnimeListStatus{
+		Status:             mal.AnimeStatusWatching,
+		Score:              9,
+		NumEpisodesWatched: 5,
+		StartDate:          mal.Date{Year: 2017, Month: time.October},
+		UpdatedAt:          time.Date(2022, 2, 20, 10, 0, 0, 0, time.UTC),
+		Tags:               []string{"space", "jazz"},
+		Comments:           "line one\nline two",
+	},
+}
+
+var flatManga = mal.UserManga{
+	Manga:  mal.Manga{ID: 2, Title: "Berserk", MediaType: mal.MangaMediaTypeManga},
+	Status: mal.MangaListStatus{Status: mal.MangaStatusReading, NumVolumesRead: 41, NumChaptersRead: 364},
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewCSVWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewCSVWriter returned error: %v", err)
+	}
+	if err := w.WriteAnime(flatAnime); err != nil {
+		t.Fatalf("WriteAnime returned error: %v", err)
+	}
+	if err := w.WriteManga(flatManga); err != nil {
+		t.Fatalf("WriteManga returned error: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	want := `id,title,media_type,genres,status,score,progress,start_date,finish_date,updated_at,tags,comments
+1,"Cowboy ""Bebop"", Session 1",tv,"Action, Sci-Fi",watching,9,5,2017-10,,2022-02-20T10:00:00Z,"space, jazz","line one
+line two"
+2,Berserk,manga,,reading,0,364,,,,,
+`
+	if got := buf.String(); got != want {
+		t.Errorf("CSVWriter output\nhave:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCSVWriterHeaderOnly(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewCSVWriter(&buf, ColumnTitle, ColumnScore)
+	if err != nil {
+		t.Fatalf("NewCSVWriter returned error: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	if got, want := buf.String(), "title,score\n"; got != want {
+		t.Errorf("CSVWriter output = %q, want %q", got, want)
+	}
+}
+
+func TestJSONLinesWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewJSONLinesWriter(&buf, ColumnTitle, ColumnID, ColumnGenres, ColumnTags, ColumnProgress, ColumnFinishDate, ColumnUpdatedAt)
+	if err != nil {
+		t.Fatalf("NewJSONLinesWriter returned error: %v", err)
+	}
+	if err := w.WriteAnime(flatAnime); err != nil {
+		t.Fatalf("WriteAnime returned error: %v", err)
+	}
+	if err := w.WriteManga(flatManga); err != nil {
+		t.Fatalf("WriteManga returned error: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	want := `{"title":"Cowboy \"Bebop\", Session 1","id":1,"genres":["Action","Sci-Fi"],"tags":["space","jazz"],"progress":5,"finish_date":"","updated_at":"2022-02-20T10:00:00Z"}
+{"title":"Berserk","id":2,"genres":[],"tags":[],"progress":364,"finish_date":"","updated_at":""}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("JSONLinesWriter output\nhave:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnknownColumn(t *testing.T) {
+	if _, err := NewCSVWriter(&bytes.Buffer{}, ColumnID, "rank"); err == nil {
+		t.Error("NewCSVWriter expected error for unknown column")
+	}
+	if _, err := NewJSONLinesWriter(&bytes.Buffer{}, "rank"); err == nil {
+		t.Error("NewJSONLinesWriter expected error for unknown column")
+	}
+}
+
+func TestStreamAnimeList(t *testing.T) {
+	s := maltest.NewServer()
+	defer s.Close()
+	s.AddAnime(
+		mal.Anime{ID: 1, Title: "One", MediaType: mal.AnimeMediaTypeTV, Genres: []mal.Genre{{ID: 1, Name: "Action"}}},
+		mal.Anime{ID: 2, Title: "Two", MediaType: mal.AnimeMediaTypeMovie},
+		mal.Anime{ID: 3, Title: "Three", MediaType: mal.AnimeMediaTypeOVA},
+	)
+	s.AddUser(mal.User{Name: "alice"})
+	updatedAt := time.Date(2022, 2, 20, 10, 0, 0, 0, time.UTC)
+	s.SetAnimeListStatus("alice", 1, mal.AnimeListStatus{Status: mal.AnimeStatusCompleted, Score: 8, Tags: []string{"fav"}, Comments: "great", UpdatedAt: updatedAt})
+	s.SetAnimeListStatus("alice", 2, mal.AnimeListStatus{Status: mal.AnimeStatusCompleted, NumEpisodesWatched: 1, UpdatedAt: updatedAt.Add(time.Hour)})
+	s.SetAnimeListStatus("alice", 3, mal.AnimeListStatus{Status: mal.AnimeStatusDropped})
+
+	var buf bytes.Buffer
+	w, err := NewCSVWriter(&buf, ColumnID, ColumnTitle, ColumnMediaType, ColumnGenres, ColumnScore, ColumnProgress, ColumnTags, ColumnComments, ColumnUpdatedAt)
+	if err != nil {
+		t.Fatalf("NewCSVWriter returned error: %v", err)
+	}
+	// A small page size makes the stream request several pages.
+	err = StreamAnimeList(context.Background(), s.Client("alice"), "@me", w, mal.AnimeStatusCompleted, mal.Limit(1))
+	if err != nil {
+		t.Fatalf("StreamAnimeList returned error: %v", err)
+	}
+	want := `id,title,media_type,genres,score,progress,tags,comments,updated_at
+1,One,tv,Action,8,0,fav,great,2022-02-20T10:00:00Z
+2,Two,movie,,0,1,,,2022-02-20T11:00:00Z
+`
+	if got := buf.String(); got != want {
+		t.Errorf("StreamAnimeList output\nhave:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestStreamMangaList(t *testing.T) {
+	s := maltest.NewServer()
+	defer s.Close()
+	s.AddManga(mal.Manga{ID: 2, Title: "Berserk", MediaType: mal.MangaMediaTypeManga, Genres: []mal.Genre{{ID: 1, Name: "Action"}}})
+	s.AddUser(mal.User{Name: "alice"})
+	s.SetMangaListStatus("alice", 2, mal.MangaListStatus{Status: mal.MangaStatusReading, NumChaptersRead: 364, Tags: []string{"dark"}, UpdatedAt: time.Date(2022, 2, 20, 10, 0, 0, 0, time.UTC)})
+
+	var buf bytes.Buffer
+	w, err := NewJSONLinesWriter(&buf, ColumnID, ColumnMediaType, ColumnGenres, ColumnStatus, ColumnProgress, ColumnTags, ColumnUpdatedAt)
+	if err != nil {
+		t.Fatalf("NewJSONLinesWriter returned error: %v", err)
+	}
+	if err := StreamMangaList(context.Background(), s.Client("alice"), "alice", w); err != nil {
+		t.Fatalf("StreamMangaList returned error: %v", err)
+	}
+	want := `{"id":2,"media_type":"manga","genres":["Action"],"status":"reading","progress":364,"tags":["dark"],"updated_at":"2022-02-20T10:00:00Z"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("StreamMangaList output\nhave:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Errors of the list are returned after the entries so far are flushed.
+	buf.Reset()
+	err = StreamMangaList(context.Background(), s.Client("alice"), "nobody", w)
+	if err == nil || !strings.HasPrefix(err.Error(), "export: getting manga list") {
+		t.Errorf("StreamMangaList returned error %v, want a manga list error", err)
+	}
+}
@@ -0,0 +1,220 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// MangaFields are the fields to request when getting a manga list that will
+// be written with WriteMangaList.
+var MangaFields = mal.Fields{
+	"num_volumes",
+	"num_chapters",
+	"list_status{status,score,num_volumes_read,num_chapters_read,is_rereading,start_date,finish_date,priority,num_times_reread,reread_value,tags,comments}",
+}
+
+type mangaDocument struct {
+	XMLName xml.Name     `xml:"myanimelist"`
+	MyInfo  mangaInfo    `xml:"myinfo"`
+	Manga   []mangaEntry `xml:"manga"`
+}
+
+type mangaInfo struct {
+	UserID          int64  `xml:"user_id"`
+	UserName        string `xml:"user_name"`
+	ExportType      int    `xml:"user_export_type"`
+	TotalManga      int    `xml:"user_total_manga"`
+	TotalReading    int    `xml:"user_total_reading"`
+	TotalCompleted  int    `xml:"user_total_completed"`
+	TotalOnHold     int    `xml:"user_total_onhold"`
+	TotalDropped    int    `xml:"user_total_dropped"`
+	TotalPlanToRead int    `xml:"user_total_plantoread"`
+}
+
+// mangaEntry is a manga element. The fields that can be empty in the files
+// that MyAnimeList produces are strings.
+type mangaEntry struct {
+	ID              int    `xml:"manga_mangadb_id"`
+	Title           cdata  `xml:"manga_title"`
+	Volumes         int    `xml:"manga_volumes"`
+	Chapters        int    `xml:"manga_chapters"`
+	MyID            int    `xml:"my_id"`
+	ReadVolumes     string `xml:"my_read_volumes"`
+	ReadChapters    string `xml:"my_read_chapters"`
+	StartDate       string `xml:"my_start_date"`
+	FinishDate      string `xml:"my_finish_date"`
+	ScanlationGroup cdata  `xml:"my_scanalation_group"`
+	Score           string `xml:"my_score"`
+	Storage         string `xml:"my_storage"`
+	RetailVolumes   string `xml:"my_retail_volumes"`
+	Status          string `xml:"my_status"`
+	Comments        cdata  `xml:"my_comments"`
+	TimesRead       string `xml:"my_times_read"`
+	Tags            cdata  `xml:"my_tags"`
+	Priority        string `xml:"my_priority"`
+	RereadValue     string `xml:"my_reread_value"`
+	Rereading       string `xml:"my_rereading"`
+	Discuss         string `xml:"my_discuss"`
+	SNS             string `xml:"my_sns"`
+	UpdateOnImport  string `xml:"update_on_import"`
+}
+
+var mangaStatusLabels = map[mal.MangaStatus]string{
+	mal.MangaStatusReading:    "Reading",
+	mal.MangaStatusCompleted:  "Completed",
+	mal.MangaStatusOnHold:     "On-Hold",
+	mal.MangaStatusDropped:    "Dropped",
+	mal.MangaStatusPlanToRead: "Plan to Read",
+}
+
+// mangaStatusCodes are the numeric statuses of older export files.
+var mangaStatusCodes = map[string]mal.MangaStatus{
+	"1": mal.MangaStatusReading,
+	"2": mal.MangaStatusCompleted,
+	"3": mal.MangaStatusOnHold,
+	"4": mal.MangaStatusDropped,
+	"6": mal.MangaStatusPlanToRead,
+}
+
+func parseMangaStatus(s string) (mal.MangaStatus, error) {
+	status := mal.MangaStatus(normalize(s))
+	if _, ok := mangaStatusLabels[status]; ok || status == "" {
+		return status, nil
+	}
+	if status, ok := mangaStatusCodes[strings.TrimSpace(s)]; ok {
+		return status, nil
+	}
+	return "", errUnknownStatus
+}
+
+// WriteMangaList writes the manga list of user to w as an export file. The
+// list needs to have the MangaFields.
+func WriteMangaList(w io.Writer, user mal.User, list []mal.UserManga) error {
+	doc := mangaDocument{
+		MyInfo: mangaInfo{
+			UserID:     user.ID,
+			UserName:   user.Name,
+			ExportType: exportTypeManga,
+			TotalManga: len(list),
+		},
+		Manga: make([]mangaEntry, len(list)),
+	}
+	for i, m := range list {
+		switch m.Status.Status {
+		case mal.MangaStatusReading:
+			doc.MyInfo.TotalReading++
+		case mal.MangaStatusCompleted:
+			doc.MyInfo.TotalCompleted++
+		case mal.MangaStatusOnHold:
+			doc.MyInfo.TotalOnHold++
+		case mal.MangaStatusDropped:
+			doc.MyInfo.TotalDropped++
+		case mal.MangaStatusPlanToRead:
+			doc.MyInfo.TotalPlanToRead++
+		}
+		s := m.Status
+		rereading := "NO"
+		if s.IsRereading {
+			rereading = "YES"
+		}
+		doc.Manga[i] = mangaEntry{
+			ID:            m.Manga.ID,
+			Title:         cdata{m.Manga.Title},
+			Volumes:       m.Manga.NumVolumes,
+			Chapters:      m.Manga.NumChapters,
+			ReadVolumes:   strconv.Itoa(s.NumVolumesRead),
+			ReadChapters:  strconv.Itoa(s.NumChaptersRead),
+			StartDate:     formatDate(s.StartDate),
+			FinishDate:    formatDate(s.FinishDate),
+			Score:         strconv.Itoa(s.Score),
+			RetailVolumes: "0",
+			Status:        mangaStatusLabels[s.Status],
+			Comments:      cdata{s.Comments},
+			TimesRead:     strconv.Itoa(s.NumTimesReread),
+			Tags:          cdata{formatTags(s.Tags)},
+			Priority:      formatPriority(s.Priority),
+			RereadValue:   formatRepeatValue(s.RereadValue),
+			Rereading:     rereading,
+			Discuss:       "YES",
+			SNS:           "default",
+			// Importing the file updates entries that are already in
+			// the list.
+			UpdateOnImport: "1",
+		}
+	}
+	return write(w, doc)
+}
+
+// ReadMangaList reads a manga export file from r. The returned entries have
+// the ID, title and number of volumes and chapters of the manga and the status
+// of the manga in the list, except for UpdatedAt which exports do not include.
+func ReadMangaList(r io.Reader) ([]mal.UserManga, error) {
+	var doc mangaDocument
+	if err := read(r, &doc, &doc.MyInfo.ExportType, exportTypeManga); err != nil {
+		return nil, err
+	}
+	list := make([]mal.UserManga, len(doc.Manga))
+	for i, e := range doc.Manga {
+		var p parser
+		status, err := parseMangaStatus(e.Status)
+		if err != nil {
+			p.fail("my_status", e.Status, err)
+		}
+		list[i] = mal.UserManga{
+			Manga: mal.Manga{
+				ID:          e.ID,
+				Title:       e.Title.Text,
+				NumVolumes:  e.Volumes,
+				NumChapters: e.Chapters,
+			},
+			Status: mal.MangaListStatus{
+				Status:          status,
+				IsRereading:     p.bool("my_rereading", e.Rereading),
+				NumVolumesRead:  p.int("my_read_volumes", e.ReadVolumes),
+				NumChaptersRead: p.int("my_read_chapters", e.ReadChapters),
+				Score:           p.int("my_score", e.Score),
+				Priority:        p.priority("my_priority", e.Priority),
+				NumTimesReread:  p.int("my_times_read", e.TimesRead),
+				RereadValue:     p.repeatValue("my_reread_value", e.RereadValue),
+				Tags:            parseTags(e.Tags.Text),
+				Comments:        e.Comments.Text,
+				StartDate:       p.date("my_start_date", e.StartDate),
+				FinishDate:      p.date("my_finish_date", e.FinishDate),
+			},
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("export: manga %d: %w", e.ID, p.err)
+		}
+	}
+	return list, nil
+}
+
+// MangaUpdateOptions returns the options that make an entry of the manga list
+// of the authenticated user equal to s when passed to
+// MangaService.UpdateMyListStatus. Every field is set, so existing values are
+// replaced, including tags, comments and dates which are removed if they are
+// empty in s.
+func MangaUpdateOptions(s mal.MangaListStatus) []mal.UpdateMyMangaListStatusOption {
+	var opts []mal.UpdateMyMangaListStatusOption
+	if s.Status != "" {
+		opts = append(opts, s.Status)
+	}
+	return append(opts,
+		mal.IsRereading(s.IsRereading),
+		mal.NumVolumesRead(s.NumVolumesRead),
+		mal.NumChaptersRead(s.NumChaptersRead),
+		mal.Score(s.Score),
+		mal.Priority(s.Priority),
+		mal.NumTimesReread(s.NumTimesReread),
+		mal.RereadValue(s.RereadValue),
+		mal.Tags(s.Tags),
+		mal.Comments(s.Comments),
+		mal.StartDate(s.StartDate),
+		mal.FinishDate(s.FinishDate),
+	)
+}
@@ -0,0 +1,103 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/listdiff"
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// AnimeFields are the fields requested by Snapshot for the anime list. Lists
+// passed to SnapshotAnime should have them too.
+var AnimeFields = mal.Fields{
+	"list_status{status,score,num_episodes_watched,is_rewatching,updated_at,start_date,finish_date,priority,num_times_rewatched,rewatch_value,tags,comments}",
+}
+
+// SnapshotAnime records the changes of the whole anime list since the previous
+// snapshot at time at, which cannot be before the previous snapshot. Entries
+// that are not in list are recorded as deleted. It returns the number of
+// changes.
+func (s *Store) SnapshotAnime(at time.Time, list []mal.UserAnime) (int, error) {
+	entries := make([]entry, len(list))
+	for i, a := range list {
+		status, err := json.Marshal(a.Status)
+		if err != nil {
+			return 0, fmt.Errorf("history: anime %d: %w", a.Anime.ID, err)
+		}
+		entries[i] = entry{id: a.Anime.ID, title: a.Anime.Title, status: status}
+	}
+	return s.snapshot("anime", at, entries)
+}
+
+func userAnime(r record) (mal.UserAnime, error) {
+	a := mal.UserAnime{Anime: mal.Anime{ID: r.ID, Title: r.Title}}
+	if err := json.Unmarshal(r.Status, &a.Status); err != nil {
+		return a, fmt.Errorf("history: anime %d: %w", r.ID, err)
+	}
+	return a, nil
+}
+
+// AnimeListAt returns the anime list as it was at t according to the
+// snapshots, sorted by ID. The anime have only their ID and title.
+func (s *Store) AnimeListAt(t time.Time) ([]mal.UserAnime, error) {
+	state, err := s.stateAt("anime", t)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]record, 0, len(state))
+	for _, r := range state {
+		records = append(records, r)
+	}
+	sortRecords(records)
+	list := make([]mal.UserAnime, len(records))
+	for i, r := range records {
+		if list[i], err = userAnime(r); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// AnimeChange is a change of an entry of the anime list found by a snapshot.
+type AnimeChange struct {
+	Time   time.Time
+	Action Action
+
+	// Anime is the entry after the change or, for deletions, before it.
+	Anime mal.UserAnime
+
+	// Diff holds the differences from the previous state for updates. It is
+	// nil for adds and deletions and for updates of values that listdiff
+	// does not compare, such as the priority.
+	Diff *listdiff.Change
+}
+
+// AnimeChanges returns the changes of the entry of anime id in the order they
+// were recorded.
+func (s *Store) AnimeChanges(id int) ([]AnimeChange, error) {
+	records, err := s.changes("anime", id)
+	if err != nil {
+		return nil, err
+	}
+	var changes []AnimeChange
+	var prev *record
+	var prevAnime mal.UserAnime
+	for i, r := range records {
+		ch := AnimeChange{Time: r.Time, Action: action(prev, r), Anime: prevAnime}
+		if !r.deleted() {
+			if ch.Anime, err = userAnime(r); err != nil {
+				return nil, err
+			}
+		}
+		if ch.Action == ActionUpdate {
+			if d := listdiff.Anime([]mal.UserAnime{prevAnime}, []mal.UserAnime{ch.Anime}); len(d.Changed) > 0 {
+				ch.Diff = &d.Changed[0]
+			}
+		}
+		changes = append(changes, ch)
+		prev, prevAnime = &records[i], ch.Anime
+	}
+	return changes, nil
+}
@@ -0,0 +1,359 @@
+// Package history keeps the changes of anime and manga lists over time in an
+// append-only log file, so that it can tell what a list looked like at any
+// point in time and how an entry changed.
+//
+// Each snapshot of a list records only the entries that were added, changed or
+// deleted since the previous snapshot, one JSON object per line. A Store can
+// take a snapshot periodically:
+//
+//	s, err := history.Open("alice.log")
+//	// ...
+//	defer s.Close()
+//	go s.Watch(ctx, c, "alice", 6*time.Hour)
+//
+// and answer queries at any time:
+//
+//	list, err := s.AnimeListAt(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+//	// ...
+//	changes, err := s.AnimeChanges(1)
+//	// ...
+//	for _, ch := range changes {
+//		fmt.Println(ch.Time.Format("2006-01-02"), ch.Action, ch.Anime.Status.NumEpisodesWatched)
+//	}
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// Action is how an entry of a list changed.
+type Action string
+
+// The actions of changes.
+const (
+	ActionAdd    Action = "add"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// record is a line of the log. Status is nil for deletions.
+type record struct {
+	Time   time.Time       `json:"time"`
+	Kind   string          `json:"kind"`
+	ID     int             `json:"id"`
+	Title  string          `json:"title,omitempty"`
+	Status json.RawMessage `json:"status,omitempty"`
+}
+
+func (r record) deleted() bool { return r.Status == nil }
+
+type key struct {
+	kind string
+	id   int
+}
+
+// logFile is the file of a Store. It is an *os.File except in tests.
+type logFile interface {
+	io.ReadWriteSeeker
+	io.ReaderAt
+	io.Closer
+	Sync() error
+	Truncate(size int64) error
+}
+
+// Store is a history log file. It is safe for concurrent use.
+type Store struct {
+	mu   sync.Mutex
+	f    logFile
+	size int64 // The size of the complete lines of the file.
+	last time.Time
+
+	// broken is set when a failed write could not be undone, since writing
+	// after a partial line would corrupt the log.
+	broken error
+
+	// current holds the latest record of each entry that is in the list.
+	current map[key]record
+}
+
+// Open opens the log file at path, creating it if it does not exist. The file
+// is only readable by its owner since it holds the lists of a user. A partial
+// line at the end of the file, left by an interrupted write, is removed.
+func Open(path string) (*Store, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("history: %w", err)
+	}
+	s := &Store{f: f, current: make(map[key]record)}
+	if err := s.load(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// load reads the whole file to find the latest state of the lists.
+func (s *Store) load() error {
+	br := bufio.NewReader(s.f)
+	for {
+		line, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			// Drop the partial line, if any.
+			if err := s.f.Truncate(s.size); err != nil {
+				return fmt.Errorf("history: %w", err)
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("history: %w", err)
+		}
+		r, err := decode(line, s.size)
+		if err != nil {
+			return err
+		}
+		s.size += int64(len(line))
+		s.last = r.Time
+		s.apply(r)
+	}
+	if _, err := s.f.Seek(s.size, io.SeekStart); err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	return nil
+}
+
+func decode(line []byte, offset int64) (record, error) {
+	var r record
+	if err := json.Unmarshal(line, &r); err != nil {
+		return r, fmt.Errorf("history: invalid record at offset %d: %w", offset, err)
+	}
+	return r, nil
+}
+
+func (s *Store) apply(r record) {
+	k := key{r.Kind, r.ID}
+	if r.deleted() {
+		delete(s.current, k)
+		return
+	}
+	s.current[k] = r
+}
+
+// Close closes the log file.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+// entry is an entry of a snapshot with its encoded status.
+type entry struct {
+	id     int
+	title  string
+	status json.RawMessage
+}
+
+// snapshot appends the records that turn the latest state of the list of kind
+// into entries and returns their number.
+func (s *Store) snapshot(kind string, at time.Time, entries []entry) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.broken != nil {
+		return 0, fmt.Errorf("history: log is unusable after a failed write: %w", s.broken)
+	}
+	if at.Before(s.last) {
+		return 0, fmt.Errorf("history: snapshot time %v is before the last snapshot at %v", at, s.last)
+	}
+	var records []record
+	inList := make(map[int]bool, len(entries))
+	for _, e := range entries {
+		inList[e.id] = true
+		old, ok := s.current[key{kind, e.id}]
+		if ok && bytes.Equal(old.Status, e.status) {
+			continue
+		}
+		records = append(records, record{Time: at, Kind: kind, ID: e.id, Title: e.title, Status: e.status})
+	}
+	for k, old := range s.current {
+		if k.kind == kind && !inList[k.id] {
+			records = append(records, record{Time: at, Kind: kind, ID: k.id, Title: old.Title})
+		}
+	}
+	sortRecords(records)
+
+	var buf bytes.Buffer
+	for _, r := range records {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return 0, fmt.Errorf("history: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if err := s.write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	s.size += int64(buf.Len())
+	s.last = at
+	for _, r := range records {
+		s.apply(r)
+	}
+	return len(records), nil
+}
+
+// write appends the lines b to the file. The lines are written at once so that
+// an interrupted process leaves at most a partial line, which Open removes. If
+// the write fails, the file is truncated back to its last complete line.
+func (s *Store) write(b []byte) error {
+	_, err := s.f.Write(b)
+	if err == nil {
+		err = s.f.Sync()
+	}
+	if err == nil {
+		return nil
+	}
+	if terr := s.f.Truncate(s.size); terr != nil {
+		s.broken = terr
+	} else if _, serr := s.f.Seek(s.size, io.SeekStart); serr != nil {
+		s.broken = serr
+	}
+	return fmt.Errorf("history: %w", err)
+}
+
+func sortRecords(records []record) {
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+}
+
+// replay calls fn with each record of the file, in the order they were
+// written, until fn returns false.
+func (s *Store) replay(fn func(r record) bool) error {
+	s.mu.Lock()
+	size := s.size
+	s.mu.Unlock()
+	// Records are only appended, so the first size bytes do not change.
+	br := bufio.NewReader(io.NewSectionReader(s.f, 0, size))
+	var offset int64
+	for {
+		line, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("history: %w", err)
+		}
+		r, err := decode(line, offset)
+		if err != nil {
+			return err
+		}
+		offset += int64(len(line))
+		if !fn(r) {
+			return nil
+		}
+	}
+}
+
+// stateAt returns the latest record of each entry of the list of kind that
+// was in the list at t.
+func (s *Store) stateAt(kind string, t time.Time) (map[int]record, error) {
+	state := make(map[int]record)
+	err := s.replay(func(r record) bool {
+		if r.Time.After(t) {
+			return false
+		}
+		if r.Kind == kind {
+			if r.deleted() {
+				delete(state, r.ID)
+			} else {
+				state[r.ID] = r
+			}
+		}
+		return true
+	})
+	return state, err
+}
+
+// changes returns the records of entry id of the list of kind.
+func (s *Store) changes(kind string, id int) ([]record, error) {
+	var records []record
+	err := s.replay(func(r record) bool {
+		if r.Kind == kind && r.ID == id {
+			records = append(records, r)
+		}
+		return true
+	})
+	return records, err
+}
+
+// action returns the action of r given the previous record of its entry.
+func action(prev *record, r record) Action {
+	switch {
+	case r.deleted():
+		return ActionDelete
+	case prev == nil || prev.deleted():
+		return ActionAdd
+	}
+	return ActionUpdate
+}
+
+// Snapshot gets the whole anime and manga lists of the user indicated by
+// username (or use @me) and records their changes, at the current time. Nothing
+// is recorded if getting either list fails.
+func (s *Store) Snapshot(ctx context.Context, c *mal.Client, username string) error {
+	var anime []mal.UserAnime
+	ait := c.User.AnimeListIterator(username, AnimeFields, mal.Limit(pageSize))
+	for ait.Next(ctx) {
+		anime = append(anime, ait.Value())
+	}
+	if err := ait.Err(); err != nil {
+		return fmt.Errorf("history: getting anime list: %w", err)
+	}
+	var manga []mal.UserManga
+	mit := c.User.MangaListIterator(username, MangaFields, mal.Limit(pageSize))
+	for mit.Next(ctx) {
+		manga = append(manga, mit.Value())
+	}
+	if err := mit.Err(); err != nil {
+		return fmt.Errorf("history: getting manga list: %w", err)
+	}
+	now := time.Now()
+	if _, err := s.SnapshotAnime(now, anime); err != nil {
+		return err
+	}
+	_, err := s.SnapshotManga(now, manga)
+	return err
+}
+
+// pageSize is the number of entries requested per page when getting a list.
+const pageSize = 1000
+
+// Watch takes a snapshot of the lists of the user indicated by username (or
+// use @me) right away and then every interval, until ctx is done or a snapshot
+// fails. It returns the error of the snapshot or of ctx, or an error if
+// interval is not positive.
+func (s *Store) Watch(ctx context.Context, c *mal.Client, username string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("history: invalid watch interval %v", interval)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Snapshot(ctx, c, username); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/listdiff"
+	"github.com/nstratos/go-myanimelist/mal"
+	"github.com/nstratos/go-myanimelist/maltest"
+)
+
+func openStore(t *testing.T, path string) *Store {
+	t.Helper()
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func day(d int) time.Time { return time.Date(2022, time.January, d, 12, 0, 0, 0, time.UTC) }
+
+func bebop(status mal.AnimeStatus, watched, score int) mal.UserAnime {
+	return mal.UserAnime{
+		Anime:  mal.Anime{ID: 1, Title: "Cowboy Bebop"},
+		Status: mal.AnimeListStatus{Status: status, NumEpisodesWatched: watched, Score: score},
+	}
+}
+
+var trigun = mal.UserAnime{
+	Anime:  mal.Anime{ID: 6, Title: "Trigun"},
+	Status: mal.AnimeListStatus{Status: mal.AnimeStatusPlanToWatch, Tags: []string{"later"}},
+}
+
+func TestStoreAnime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.log")
+	s := openStore(t, path)
+
+	snapshots := []struct {
+		at   time.Time
+		list []mal.UserAnime
+		want int
+	}{
+		{day(1), []mal.UserAnime{bebop(mal.AnimeStatusWatching, 10, 0), trigun}, 2},
+		{day(2), []mal.UserAnime{bebop(mal.AnimeStatusWatching, 10, 0), trigun}, 0},
+		{day(3), []mal.UserAnime{bebop(mal.AnimeStatusCompleted, 26, 9), trigun}, 1},
+		{day(5), []mal.UserAnime{bebop(mal.AnimeStatusCompleted, 26, 9)}, 1},
+	}
+	for _, snap := range snapshots {
+		n, err := s.SnapshotAnime(snap.at, snap.list)
+		if err != nil {
+			t.Fatalf("SnapshotAnime at %v returned error: %v", snap.at, err)
+		}
+		if n != snap.want {
+			t.Errorf("SnapshotAnime at %v returned %d changes, want %d", snap.at, n, snap.want)
+		}
+	}
+	if _, err := s.SnapshotAnime(day(4), nil); err == nil {
+		t.Error("SnapshotAnime before the last snapshot returned no error")
+	}
+
+	listTests := []struct {
+		at   time.Time
+		want []mal.UserAnime
+	}{
+		{day(1).Add(-time.Hour), []mal.UserAnime{}},
+		{day(2), []mal.UserAnime{bebop(mal.AnimeStatusWatching, 10, 0), trigun}},
+		{day(4), []mal.UserAnime{bebop(mal.AnimeStatusCompleted, 26, 9), trigun}},
+		{day(6), []mal.UserAnime{bebop(mal.AnimeStatusCompleted, 26, 9)}},
+	}
+	// A reopened store answers the same.
+	reopened := openStore(t, path)
+	for _, store := range []*Store{s, reopened} {
+		for _, tt := range listTests {
+			got, err := store.AnimeListAt(tt.at)
+			if err != nil {
+				t.Fatalf("AnimeListAt(%v) returned error: %v", tt.at, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AnimeListAt(%v) returned\n%+v\nwant\n%+v", tt.at, got, tt.want)
+			}
+		}
+	}
+
+	changes, err := s.AnimeChanges(1)
+	if err != nil {
+		t.Fatalf("AnimeChanges returned error: %v", err)
+	}
+	want := []AnimeChange{
+		{Time: day(1), Action: ActionAdd, Anime: bebop(mal.AnimeStatusWatching, 10, 0)},
+		{
+			Time:   day(3),
+			Action: ActionUpdate,
+			Anime:  bebop(mal.AnimeStatusCompleted, 26, 9),
+			Diff: &listdiff.Change{
+				ID:       1,
+				Title:    "Cowboy Bebop",
+				Status:   &listdiff.StringChange{From: "watching", To: "completed"},
+				Score:    &listdiff.IntChange{From: 0, To: 9, Delta: 9},
+				Progress: &listdiff.IntChange{From: 10, To: 26, Delta: 16},
+			},
+		},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("AnimeChanges(1) returned\n%+v\nwant\n%+v", changes, want)
+	}
+
+	changes, err = s.AnimeChanges(6)
+	if err != nil {
+		t.Fatalf("AnimeChanges returned error: %v", err)
+	}
+	var actions []Action
+	for _, ch := range changes {
+		actions = append(actions, ch.Action)
+		if !reflect.DeepEqual(ch.Anime, trigun) {
+			t.Errorf("AnimeChanges(6) returned anime %+v for %s, want %+v", ch.Anime, ch.Action, trigun)
+		}
+	}
+	if want := []Action{ActionAdd, ActionDelete}; !reflect.DeepEqual(actions, want) {
+		t.Errorf("AnimeChanges(6) returned actions %v, want %v", actions, want)
+	}
+}
+
+func TestStoreManga(t *testing.T) {
+	s := openStore(t, filepath.Join(t.TempDir(), "history.log"))
+	berserk := mal.UserManga{
+		Manga:  mal.Manga{ID: 2, Title: "Berserk"},
+		Status: mal.MangaListStatus{Status: mal.MangaStatusReading, NumChaptersRead: 100},
+	}
+	if _, err := s.SnapshotManga(day(1), []mal.UserManga{berserk}); err != nil {
+		t.Fatalf("SnapshotManga returned error: %v", err)
+	}
+	// Snapshots of the anime list do not touch the manga list.
+	if _, err := s.SnapshotAnime(day(2), nil); err != nil {
+		t.Fatalf("SnapshotAnime returned error: %v", err)
+	}
+	berserk.Status.Priority = 2
+	if _, err := s.SnapshotManga(day(3), []mal.UserManga{berserk}); err != nil {
+		t.Fatalf("SnapshotManga returned error: %v", err)
+	}
+
+	list, err := s.MangaListAt(day(3))
+	if err != nil {
+		t.Fatalf("MangaListAt returned error: %v", err)
+	}
+	if want := []mal.UserManga{berserk}; !reflect.DeepEqual(list, want) {
+		t.Errorf("MangaListAt returned %+v, want %+v", list, want)
+	}
+	changes, err := s.MangaChanges(2)
+	if err != nil {
+		t.Fatalf("MangaChanges returned error: %v", err)
+	}
+	if len(changes) != 2 || changes[1].Action != ActionUpdate || changes[1].Diff != nil {
+		t.Errorf("MangaChanges returned %+v, want an add and an update without Diff", changes)
+	}
+}
+
+func TestOpenPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.log")
+	s := openStore(t, path)
+	if _, err := s.SnapshotAnime(day(1), []mal.UserAnime{trigun}); err != nil {
+		t.Fatalf("SnapshotAnime returned error: %v", err)
+	}
+	s.Close()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time":"2022-01-02T00:00:00Z","kind":"an`)
+	f.Close()
+
+	s = openStore(t, path)
+	if _, err := s.SnapshotAnime(day(3), nil); err != nil {
+		t.Fatalf("SnapshotAnime after reopening returned error: %v", err)
+	}
+	changes, err := s.AnimeChanges(6)
+	if err != nil {
+		t.Fatalf("AnimeChanges returned error: %v", err)
+	}
+	if len(changes) != 2 || changes[1].Action != ActionDelete {
+		t.Errorf("AnimeChanges returned %+v, want an add and a delete", changes)
+	}
+
+	if err := os.WriteFile(path, []byte("not json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Error("Open of a file with an invalid record returned no error")
+	}
+}
+
+func TestStoreSnapshot(t *testing.T) {
+	srv := maltest.NewServer()
+	defer srv.Close()
+	srv.AddAnime(mal.Anime{ID: 1, Title: "Cowboy Bebop"})
+	srv.AddManga(mal.Manga{ID: 2, Title: "Berserk"})
+	srv.AddUser(mal.User{Name: "alice"})
+	srv.SetAnimeListStatus("alice", 1, mal.AnimeListStatus{Status: mal.AnimeStatusWatching, NumEpisodesWatched: 3, Tags: []string{"space"}})
+	srv.SetMangaListStatus("alice", 2, mal.MangaListStatus{Status: mal.MangaStatusReading, NumVolumesRead: 4})
+
+	s := openStore(t, filepath.Join(t.TempDir(), "history.log"))
+	ctx := context.Background()
+	c := srv.Client("alice")
+	if err := s.Snapshot(ctx, c, "@me"); err != nil {
+		t.Fatalf("Snapshot returned error: %v", err)
+	}
+
+	anime, err := s.AnimeListAt(time.Now())
+	if err != nil {
+		t.Fatalf("AnimeListAt returned error: %v", err)
+	}
+	if len(anime) != 1 || anime[0].Anime.Title != "Cowboy Bebop" || anime[0].Status.NumEpisodesWatched != 3 ||
+		!reflect.DeepEqual(anime[0].Status.Tags, []string{"space"}) {
+		t.Errorf("AnimeListAt after Snapshot returned %+v", anime)
+	}
+	manga, err := s.MangaListAt(time.Now())
+	if err != nil {
+		t.Fatalf("MangaListAt returned error: %v", err)
+	}
+	if len(manga) != 1 || manga[0].Status.NumVolumesRead != 4 {
+		t.Errorf("MangaListAt after Snapshot returned %+v", manga)
+	}
+
+	// A failed snapshot records nothing.
+	srv.SetAnimeListStatus("alice", 1, mal.AnimeListStatus{Status: mal.AnimeStatusCompleted})
+	srv.FailNext(1, "GET", "/users/@me/mangalist", 500, "")
+	if err := s.Snapshot(ctx, c, "@me"); err == nil {
+		t.Fatal("Snapshot with a failing manga list returned no error")
+	}
+	changes, err := s.AnimeChanges(1)
+	if err != nil {
+		t.Fatalf("AnimeChanges returned error: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Errorf("AnimeChanges after a failed Snapshot returned %d changes, want 1", len(changes))
+	}
+
+	// Watch stops when ctx is done.
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := s.Watch(ctx, c, "@me", time.Hour); err == nil {
+		t.Error("Watch with a canceled context returned no error")
+	}
+	if err := s.Watch(context.Background(), c, "@me", 0); err == nil {
+		t.Error("Watch with a zero interval returned no error")
+	}
+}
+
+// failingFile writes only the first n bytes of the next write and then fails.
+type failingFile struct {
+	logFile
+	n int
+}
+
+func (f *failingFile) Write(b []byte) (int, error) {
+	if f.n < 0 {
+		return f.logFile.Write(b)
+	}
+	n, _ := f.logFile.Write(b[:f.n])
+	f.n = -1
+	return n, errors.New("disk full")
+}
+
+func TestStoreWriteFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.log")
+	s := openStore(t, path)
+	if _, err := s.SnapshotAnime(day(1), []mal.UserAnime{trigun}); err != nil {
+		t.Fatalf("SnapshotAnime returned error: %v", err)
+	}
+	s.f = &failingFile{logFile: s.f, n: 10}
+	if _, err := s.SnapshotAnime(day(2), []mal.UserAnime{bebop(mal.AnimeStatusWatching, 1, 0)}); err == nil {
+		t.Fatal("SnapshotAnime with a failing write returned no error")
+	}
+	if _, err := s.SnapshotAnime(day(3), []mal.UserAnime{trigun, bebop(mal.AnimeStatusWatching, 2, 0)}); err != nil {
+		t.Fatalf("SnapshotAnime after a failed write returned error: %v", err)
+	}
+	s.Close()
+
+	s = openStore(t, path)
+	list, err := s.AnimeListAt(day(3))
+	if err != nil {
+		t.Fatalf("AnimeListAt returned error: %v", err)
+	}
+	want := []mal.UserAnime{bebop(mal.AnimeStatusWatching, 2, 0), trigun}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("AnimeListAt returned\n%+v\nwant\n%+v", list, want)
+	}
+	changes, err := s.AnimeChanges(1)
+	if err != nil {
+		t.Fatalf("AnimeChanges returned error: %v", err)
+	}
+	if len(changes) != 1 || !changes[0].Time.Equal(day(3)) {
+		t.Errorf("AnimeChanges returned %+v, want only the add of the successful snapshot", changes)
+	}
+}
+
+func TestOpenMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.log")
+	openStore(t, path)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("log file has mode %v, want %v", perm, os.FileMode(0o600))
+	}
+}
@@ -0,0 +1,103 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/listdiff"
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// MangaFields are the fields requested by Snapshot for the manga list. Lists
+// passed to SnapshotManga should have them too.
+var MangaFields = mal.Fields{
+	"list_status{status,score,num_volumes_read,num_chapters_read,is_rereading,updated_at,start_date,finish_date,priority,num_times_reread,reread_value,tags,comments}",
+}
+
+// SnapshotManga records the changes of the whole manga list since the previous
+// snapshot at time at, which cannot be before the previous snapshot. Entries
+// that are not in list are recorded as deleted. It returns the number of
+// changes.
+func (s *Store) SnapshotManga(at time.Time, list []mal.UserManga) (int, error) {
+	entries := make([]entry, len(list))
+	for i, m := range list {
+		status, err := json.Marshal(m.Status)
+		if err != nil {
+			return 0, fmt.Errorf("history: manga %d: %w", m.Manga.ID, err)
+		}
+		entries[i] = entry{id: m.Manga.ID, title: m.Manga.Title, status: status}
+	}
+	return s.snapshot("manga", at, entries)
+}
+
+func userManga(r record) (mal.UserManga, error) {
+	m := mal.UserManga{Manga: mal.Manga{ID: r.ID, Title: r.Title}}
+	if err := json.Unmarshal(r.Status, &m.Status); err != nil {
+		return m, fmt.Errorf("history: manga %d: %w", r.ID, err)
+	}
+	return m, nil
+}
+
+// MangaListAt returns the manga list as it was at t according to the
+// snapshots, sorted by ID. The manga have only their ID and title.
+func (s *Store) MangaListAt(t time.Time) ([]mal.UserManga, error) {
+	state, err := s.stateAt("manga", t)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]record, 0, len(state))
+	for _, r := range state {
+		records = append(records, r)
+	}
+	sortRecords(records)
+	list := make([]mal.UserManga, len(records))
+	for i, r := range records {
+		if list[i], err = userManga(r); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// MangaChange is a change of an entry of the manga list found by a snapshot.
+type MangaChange struct {
+	Time   time.Time
+	Action Action
+
+	// Manga is the entry after the change or, for deletions, before it.
+	Manga mal.UserManga
+
+	// Diff holds the differences from the previous state for updates. It is
+	// nil for adds and deletions and for updates of values that listdiff
+	// does not compare, such as the priority.
+	Diff *listdiff.Change
+}
+
+// MangaChanges returns the changes of the entry of manga id in the order they
+// were recorded.
+func (s *Store) MangaChanges(id int) ([]MangaChange, error) {
+	records, err := s.changes("manga", id)
+	if err != nil {
+		return nil, err
+	}
+	var changes []MangaChange
+	var prev *record
+	var prevManga mal.UserManga
+	for i, r := range records {
+		ch := MangaChange{Time: r.Time, Action: action(prev, r), Manga: prevManga}
+		if !r.deleted() {
+			if ch.Manga, err = userManga(r); err != nil {
+				return nil, err
+			}
+		}
+		if ch.Action == ActionUpdate {
+			if d := listdiff.Manga([]mal.UserManga{prevManga}, []mal.UserManga{ch.Manga}); len(d.Changed) > 0 {
+				ch.Diff = &d.Changed[0]
+			}
+		}
+		changes = append(changes, ch)
+		prev, prevManga = &records[i], ch.Manga
+	}
+	return changes, nil
+}
@@ -0,0 +1,259 @@
+// Package ical generates iCalendar (RFC 5545) feeds with the broadcast
+// schedules of anime, so that users can subscribe to the shows they watch in
+// their calendar app.
+//
+// Each airing show becomes one weekly recurring event in Japan Standard Time,
+// which calendar apps convert to the time zone of the user:
+//
+//	c := mal.NewClient(oauth2Client)
+//	err := ical.WriteWatchlist(ctx, w, c, "@me", ical.Options{Name: "My anime"})
+//
+// Shows that have finished airing or that have no broadcast schedule are left
+// out.
+package ical
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// Fields are the anime fields that Write needs. They must be requested when
+// getting the anime that are passed to Write.
+var Fields = mal.Fields{
+	"status",
+	"broadcast",
+	"start_date",
+	"end_date",
+	"num_episodes",
+	"average_episode_duration",
+}
+
+const (
+	prodID = "-//go-myanimelist//ical//EN"
+	tzid   = "Asia/Tokyo"
+
+	defaultUIDDomain = "go-myanimelist"
+
+	// defaultDuration is the duration of the events of anime without an
+	// average episode duration.
+	defaultDuration = 30 * time.Minute
+)
+
+// Options configure the generated calendar.
+type Options struct {
+	// Name is the name of the calendar shown by calendar apps. Defaults to
+	// "MyAnimeList".
+	Name string
+
+	// Now returns the current time, used for the timestamp of the events and
+	// as the start of shows whose episodes are not known and that have
+	// already started. Defaults to time.Now.
+	Now func() time.Time
+
+	// UIDDomain is the domain part of the unique identifiers of the events,
+	// e.g. anime-1@go-myanimelist. RFC 5545 expects a domain that belongs to
+	// the generator of the calendar, so applications should set one of their
+	// own. Defaults to "go-myanimelist".
+	UIDDomain string
+}
+
+// WriteWatchlist gets the anime that the user indicated by username (or use
+// @me) is watching or plans to watch and writes a calendar with the ones that
+// are airing or have not aired yet to w.
+func WriteWatchlist(ctx context.Context, w io.Writer, c *mal.Client, username string, opts Options) error {
+	var anime []mal.Anime
+	for _, status := range []mal.AnimeStatus{mal.AnimeStatusWatching, mal.AnimeStatusPlanToWatch} {
+		it := c.User.AnimeListIterator(username, status, Fields, mal.Limit(1000))
+		for it.Next(ctx) {
+			anime = append(anime, it.Value().Anime)
+		}
+		if err := it.Err(); err != nil {
+			return fmt.Errorf("ical: getting %s anime list: %w", status, err)
+		}
+	}
+	return Write(w, anime, opts)
+}
+
+// Write writes a calendar to w with a weekly recurring event for each anime
+// that is airing or has not aired yet and has a broadcast schedule. The anime
+// need to have the Fields.
+func Write(w io.Writer, anime []mal.Anime, opts Options) error {
+	if opts.Name == "" {
+		opts.Name = "MyAnimeList"
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.UIDDomain == "" {
+		opts.UIDDomain = defaultUIDDomain
+	}
+	now := opts.Now()
+
+	cw := &contentWriter{w: bufio.NewWriter(w)}
+	cw.line("BEGIN:VCALENDAR")
+	cw.line("VERSION:2.0")
+	cw.line("PRODID:" + prodID)
+	cw.line("CALSCALE:GREGORIAN")
+	cw.line("METHOD:PUBLISH")
+	cw.line("X-WR-CALNAME:" + escape(opts.Name))
+	cw.line("X-WR-TIMEZONE:" + tzid)
+	// Japan has not observed daylight saving time since 1951 so a single
+	// standard time observance describes the zone.
+	cw.line("BEGIN:VTIMEZONE")
+	cw.line("TZID:" + tzid)
+	cw.line("BEGIN:STANDARD")
+	cw.line("DTSTART:19700101T000000")
+	cw.line("TZOFFSETFROM:+0900")
+	cw.line("TZOFFSETTO:+0900")
+	cw.line("TZNAME:JST")
+	cw.line("END:STANDARD")
+	cw.line("END:VTIMEZONE")
+	seen := make(map[int]bool)
+	for _, a := range anime {
+		if seen[a.ID] {
+			continue
+		}
+		seen[a.ID] = true
+		writeEvent(cw, a, now, opts.UIDDomain)
+	}
+	cw.line("END:VCALENDAR")
+	if cw.err != nil {
+		return cw.err
+	}
+	return cw.w.Flush()
+}
+
+// writeEvent writes the event of a, if it is airing and has a schedule.
+func writeEvent(cw *contentWriter, a mal.Anime, now time.Time, uidDomain string) {
+	if a.Status == mal.AnimeAiringStatusFinished {
+		return
+	}
+	var (
+		start time.Time
+		rule  = "FREQ=WEEKLY"
+	)
+	if times := a.EpisodeAirTimes(mal.JST); len(times) > 0 {
+		start = times[0]
+		rule += fmt.Sprintf(";COUNT=%d", len(times))
+	} else {
+		// The start date or the number of episodes is unknown so the
+		// event starts with the first broadcast after now, but not before
+		// the start date, and repeats until the end date, if it is known.
+		start = a.Broadcast.Next(firstAfter(a, now), mal.JST)
+		if start.IsZero() {
+			return
+		}
+		if end := a.EndDate; end.Day != 0 {
+			// The end of the last day in Japan.
+			until := time.Date(end.Year, end.Month, end.Day+1, 0, 0, 0, 0, mal.JST).Add(-time.Second)
+			rule += ";UNTIL=" + until.UTC().Format("20060102T150405Z")
+		}
+	}
+	duration := time.Duration(a.AverageEpisodeDuration) * time.Second
+	if duration <= 0 {
+		duration = defaultDuration
+	}
+	link := fmt.Sprintf("https://myanimelist.net/anime/%d", a.ID)
+	description := link
+	if a.NumEpisodes > 0 {
+		description = fmt.Sprintf("Episodes: %d\n%s", a.NumEpisodes, link)
+	}
+
+	cw.line("BEGIN:VEVENT")
+	cw.line(fmt.Sprintf("UID:anime-%d@%s", a.ID, uidDomain))
+	cw.line("DTSTAMP:" + now.UTC().Format("20060102T150405Z"))
+	cw.line("DTSTART;TZID=" + tzid + ":" + start.Format("20060102T150405"))
+	cw.line("DURATION:" + formatDuration(duration))
+	cw.line("RRULE:" + rule)
+	cw.line("SUMMARY:" + escape(a.Title))
+	cw.line("DESCRIPTION:" + escape(description))
+	cw.line("URL:" + link)
+	cw.line("END:VEVENT")
+}
+
+// firstAfter returns the time after which the first broadcast of a that is
+// shown can air: now or, if the start date of a is later, the start of that
+// date in Japan. A missing month or day of the start date is taken as the
+// first one.
+func firstAfter(a mal.Anime, now time.Time) time.Time {
+	if a.StartDate.IsZero() {
+		return now
+	}
+	d := a.StartDate.Time()
+	// A start time past midnight belongs to the previous day, as in
+	// Anime.EpisodeAirTimes.
+	hour, _, _ := a.Broadcast.Clock()
+	overflow := time.Duration(hour/24) * 24 * time.Hour
+	start := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, mal.JST).Add(overflow - time.Nanosecond)
+	if start.After(now) {
+		return start
+	}
+	return now
+}
+
+// formatDuration formats d as an RFC 5545 duration in whole minutes.
+func formatDuration(d time.Duration) string {
+	m := int((d + time.Minute - 1) / time.Minute)
+	if m >= 60 {
+		if m%60 == 0 {
+			return fmt.Sprintf("PT%dH", m/60)
+		}
+		return fmt.Sprintf("PT%dH%dM", m/60, m%60)
+	}
+	return fmt.Sprintf("PT%dM", m)
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+// escape escapes a TEXT property value.
+func escape(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// contentWriter writes content lines, folding them at 75 octets as required
+// by RFC 5545, and keeps the first error.
+type contentWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+const maxLineOctets = 75
+
+func (cw *contentWriter) line(s string) {
+	if cw.err != nil {
+		return
+	}
+	limit := maxLineOctets
+	for len(s) > limit {
+		// Do not split a multi-byte character.
+		i := limit
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		cw.write(s[:i] + "\r\n ")
+		s = s[i:]
+		// Continuation lines start with a space which counts towards
+		// the limit.
+		limit = maxLineOctets - 1
+	}
+	cw.write(s + "\r\n")
+}
+
+func (cw *contentWriter) write(s string) {
+	if cw.err == nil {
+		_, cw.err = cw.w.WriteString(s)
+	}
+}
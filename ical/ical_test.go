@@ -0,0 +1,175 @@
+package ical
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/mal"
+	"github.com/nstratos/go-myanimelist/maltest"
+)
+
+var testNow = func() time.Time { return time.Date(2022, 1, 5, 3, 0, 0, 0, time.UTC) }
+
+func TestWrite(t *testing.T) {
+	anime := []mal.Anime{
+		{
+			ID:                     1,
+			Title:                  "Show; with, special\\chars",
+			Status:                 mal.AnimeAiringStatusCurrentlyAiring,
+			StartDate:              mal.Date{Year: 2022, Month: time.January, Day: 8},
+			NumEpisodes:            12,
+			AverageEpisodeDuration: 1440,
+			Broadcast:              mal.Broadcast{DayOfTheWeek: "saturday", StartTime: "01:30"},
+		},
+		{
+			ID:        2,
+			Title:     "Long Runner",
+			Status:    mal.AnimeAiringStatusCurrentlyAiring,
+			StartDate: mal.Date{Year: 1999, Month: time.October},
+			EndDate:   mal.Date{Year: 2022, Month: time.March, Day: 31},
+			Broadcast: mal.Broadcast{DayOfTheWeek: "sunday", StartTime: "23:15"},
+		},
+		// The number of episodes is unknown, so the event starts with the
+		// premiere instead of the next broadcast and has no end.
+		{
+			ID:        5,
+			Title:     "Upcoming",
+			Status:    mal.AnimeAiringStatusNotYetAired,
+			StartDate: mal.Date{Year: 2022, Month: time.April, Day: 9},
+			Broadcast: mal.Broadcast{DayOfTheWeek: "saturday", StartTime: "23:00"},
+		},
+		// Left out: finished, no schedule.
+		{ID: 3, Title: "Finished", Status: mal.AnimeAiringStatusFinished, Broadcast: mal.Broadcast{DayOfTheWeek: "monday", StartTime: "10:00"}},
+		{ID: 4, Title: "Unscheduled", Status: mal.AnimeAiringStatusNotYetAired},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, anime, Options{Now: testNow}); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//go-myanimelist//ical//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:MyAnimeList",
+		"X-WR-TIMEZONE:Asia/Tokyo",
+		"BEGIN:VTIMEZONE",
+		"TZID:Asia/Tokyo",
+		"BEGIN:STANDARD",
+		"DTSTART:19700101T000000",
+		"TZOFFSETFROM:+0900",
+		"TZOFFSETTO:+0900",
+		"TZNAME:JST",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:anime-1@go-myanimelist",
+		"DTSTAMP:20220105T030000Z",
+		"DTSTART;TZID=Asia/Tokyo:20220108T013000",
+		"DURATION:PT24M",
+		"RRULE:FREQ=WEEKLY;COUNT=12",
+		`SUMMARY:Show\; with\, special\\chars`,
+		`DESCRIPTION:Episodes: 12\nhttps://myanimelist.net/anime/1`,
+		"URL:https://myanimelist.net/anime/1",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:anime-2@go-myanimelist",
+		"DTSTAMP:20220105T030000Z",
+		"DTSTART;TZID=Asia/Tokyo:20220109T231500",
+		"DURATION:PT30M",
+		"RRULE:FREQ=WEEKLY;UNTIL=20220331T145959Z",
+		"SUMMARY:Long Runner",
+		"DESCRIPTION:https://myanimelist.net/anime/2",
+		"URL:https://myanimelist.net/anime/2",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:anime-5@go-myanimelist",
+		"DTSTAMP:20220105T030000Z",
+		"DTSTART;TZID=Asia/Tokyo:20220409T230000",
+		"DURATION:PT30M",
+		"RRULE:FREQ=WEEKLY",
+		"SUMMARY:Upcoming",
+		"DESCRIPTION:https://myanimelist.net/anime/5",
+		"URL:https://myanimelist.net/anime/5",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	if got := buf.String(); got != want {
+		t.Errorf("Write output\nhave:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteUIDDomain(t *testing.T) {
+	var buf bytes.Buffer
+	anime := []mal.Anime{{ID: 7, Broadcast: mal.Broadcast{DayOfTheWeek: "monday", StartTime: "12:00"}}}
+	if err := Write(&buf, anime, Options{Now: testNow, UIDDomain: "calendar.example.com"}); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	if want := "\r\nUID:anime-7@calendar.example.com\r\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("Write output does not contain %q:\n%s", want, buf.String())
+	}
+}
+
+func TestContentLineFolding(t *testing.T) {
+	var buf bytes.Buffer
+	title := strings.Repeat("進撃の巨人 ", 20)
+	if err := Write(&buf, []mal.Anime{{
+		ID:        1,
+		Title:     title,
+		Broadcast: mal.Broadcast{DayOfTheWeek: "sunday", StartTime: "00:10"},
+	}}, Options{Now: testNow}); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	var unfolded strings.Builder
+	for i, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line %d has %d octets, want at most 75", i, len(line))
+		}
+		if !strings.HasPrefix(line, " ") && unfolded.Len() > 0 {
+			unfolded.WriteString("\n")
+		}
+		unfolded.WriteString(strings.TrimPrefix(line, " "))
+	}
+	if !strings.Contains(unfolded.String(), "\nSUMMARY:"+title+"\n") {
+		t.Errorf("unfolded output does not contain the summary:\n%s", unfolded.String())
+	}
+}
+
+func TestWriteWatchlist(t *testing.T) {
+	s := maltest.NewServer()
+	defer s.Close()
+	s.AddAnime(
+		mal.Anime{ID: 1, Title: "Watching", Status: "currently_airing", StartDate: mal.Date{Year: 2022, Month: time.January, Day: 8}, NumEpisodes: 2, Broadcast: mal.Broadcast{DayOfTheWeek: "saturday", StartTime: "01:30"}},
+		mal.Anime{ID: 2, Title: "Planned", Status: "not_yet_aired", StartDate: mal.Date{Year: 2022, Month: time.April, Day: 3}, NumEpisodes: 12, Broadcast: mal.Broadcast{DayOfTheWeek: "sunday", StartTime: "22:00"}},
+		mal.Anime{ID: 3, Title: "Completed", Status: "currently_airing", NumEpisodes: 12, Broadcast: mal.Broadcast{DayOfTheWeek: "sunday", StartTime: "22:00"}},
+	)
+	s.AddUser(mal.User{Name: "alice"})
+	s.SetAnimeListStatus("alice", 1, mal.AnimeListStatus{Status: mal.AnimeStatusWatching})
+	s.SetAnimeListStatus("alice", 2, mal.AnimeListStatus{Status: mal.AnimeStatusPlanToWatch})
+	s.SetAnimeListStatus("alice", 3, mal.AnimeListStatus{Status: mal.AnimeStatusCompleted})
+
+	var buf bytes.Buffer
+	if err := WriteWatchlist(context.Background(), &buf, s.Client("alice"), "@me", Options{Name: "Alice", Now: testNow}); err != nil {
+		t.Fatalf("WriteWatchlist returned error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"X-WR-CALNAME:Alice\r\n",
+		"SUMMARY:Watching\r\nDESCRIPTION:Episodes: 2",
+		"DTSTART;TZID=Asia/Tokyo:20220108T013000\r\n",
+		"SUMMARY:Planned\r\n",
+		"DTSTART;TZID=Asia/Tokyo:20220403T220000\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteWatchlist output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Completed") {
+		t.Errorf("WriteWatchlist output contains an anime that is not watched or planned:\n%s", out)
+	}
+}
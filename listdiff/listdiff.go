@@ -0,0 +1,313 @@
+// Package listdiff compares two anime or manga lists, such as the lists of two
+// users or the list of the same user at two points in time, and reports the
+// entries that are only in one of them and the changes of the entries that are
+// in both:
+//
+//	d := listdiff.Anime(aliceList, bobList)
+//	d.NameA, d.NameB = "alice", "bob"
+//	fmt.Print(d)
+//
+// A Diff can also be rendered as JSON with WriteJSON.
+package listdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// Diff is the difference between list A and list B. The changes go from A to
+// B, so when comparing snapshots of the same list, A should be the older one.
+type Diff struct {
+	// Kind is "anime" or "manga".
+	Kind string `json:"kind"`
+
+	// NameA and NameB are the names of the lists in the text output, e.g.
+	// the names of the users. They default to "a" and "b".
+	NameA string `json:"a,omitempty"`
+	NameB string `json:"b,omitempty"`
+
+	// OnlyInA and OnlyInB are the entries that are only in one list.
+	OnlyInA []Entry `json:"only_in_a"`
+	OnlyInB []Entry `json:"only_in_b"`
+
+	// Changed are the entries that are in both lists with different values.
+	Changed []Change `json:"changed"`
+}
+
+// Entry is an entry that is only in one of the lists.
+type Entry struct {
+	ID       int      `json:"id"`
+	Title    string   `json:"title"`
+	Status   string   `json:"status"`
+	Score    int      `json:"score"`
+	Progress int      `json:"progress"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// Change holds the differences of an entry that is in both lists. The fields
+// of the values that did not change are nil.
+type Change struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+
+	Status *StringChange `json:"status,omitempty"`
+	Score  *IntChange    `json:"score,omitempty"`
+
+	// Progress is the change of the episodes watched or chapters read and
+	// Volumes of the volumes read, which is only set for manga.
+	Progress *IntChange `json:"progress,omitempty"`
+	Volumes  *IntChange `json:"volumes,omitempty"`
+
+	TagsAdded   []string      `json:"tags_added,omitempty"`
+	TagsRemoved []string      `json:"tags_removed,omitempty"`
+	Comments    *StringChange `json:"comments,omitempty"`
+}
+
+// StringChange is the change of a string value.
+type StringChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// IntChange is the change of an integer value.
+type IntChange struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Delta int `json:"delta"` // To - From.
+}
+
+// entry is an entry of either kind of list.
+type entry struct {
+	id       int
+	title    string
+	status   string
+	score    int
+	progress int
+	volumes  int
+	tags     []string
+	comments string
+}
+
+func animeEntries(list []mal.UserAnime) []entry {
+	entries := make([]entry, len(list))
+	for i, a := range list {
+		entries[i] = entry{
+			id:       a.Anime.ID,
+			title:    a.Anime.Title,
+			status:   string(a.Status.Status),
+			score:    a.Status.Score,
+			progress: a.Status.NumEpisodesWatched,
+			tags:     a.Status.Tags,
+			comments: a.Status.Comments,
+		}
+	}
+	return entries
+}
+
+func mangaEntries(list []mal.UserManga) []entry {
+	entries := make([]entry, len(list))
+	for i, m := range list {
+		entries[i] = entry{
+			id:       m.Manga.ID,
+			title:    m.Manga.Title,
+			status:   string(m.Status.Status),
+			score:    m.Status.Score,
+			progress: m.Status.NumChaptersRead,
+			volumes:  m.Status.NumVolumesRead,
+			tags:     m.Status.Tags,
+			comments: m.Status.Comments,
+		}
+	}
+	return entries
+}
+
+// Anime compares the anime lists a and b. The lists need the list status
+// fields that are compared, including tags and comments.
+func Anime(a, b []mal.UserAnime) *Diff {
+	return diff("anime", animeEntries(a), animeEntries(b))
+}
+
+// Manga compares the manga lists a and b. The lists need the list status
+// fields that are compared, including tags and comments.
+func Manga(a, b []mal.UserManga) *Diff {
+	return diff("manga", mangaEntries(a), mangaEntries(b))
+}
+
+func diff(kind string, a, b []entry) *Diff {
+	d := &Diff{
+		Kind:    kind,
+		OnlyInA: []Entry{},
+		OnlyInB: []Entry{},
+		Changed: []Change{},
+	}
+	inA := make(map[int]entry, len(a))
+	for _, e := range a {
+		inA[e.id] = e
+	}
+	inB := make(map[int]bool, len(b))
+	for _, eb := range b {
+		inB[eb.id] = true
+		ea, ok := inA[eb.id]
+		if !ok {
+			d.OnlyInB = append(d.OnlyInB, eb.public())
+			continue
+		}
+		if c, changed := compare(ea, eb); changed {
+			d.Changed = append(d.Changed, c)
+		}
+	}
+	for _, e := range a {
+		if !inB[e.id] {
+			d.OnlyInA = append(d.OnlyInA, e.public())
+		}
+	}
+	sort.Slice(d.OnlyInA, func(i, j int) bool { return d.OnlyInA[i].ID < d.OnlyInA[j].ID })
+	sort.Slice(d.OnlyInB, func(i, j int) bool { return d.OnlyInB[i].ID < d.OnlyInB[j].ID })
+	sort.Slice(d.Changed, func(i, j int) bool { return d.Changed[i].ID < d.Changed[j].ID })
+	return d
+}
+
+func (e entry) public() Entry {
+	return Entry{ID: e.id, Title: e.title, Status: e.status, Score: e.score, Progress: e.progress, Tags: e.tags}
+}
+
+// compare returns the change from a to b and whether anything changed. The
+// title is taken from b.
+func compare(a, b entry) (Change, bool) {
+	c := Change{ID: b.id, Title: b.title}
+	if a.status != b.status {
+		c.Status = &StringChange{From: a.status, To: b.status}
+	}
+	c.Score = intChange(a.score, b.score)
+	c.Progress = intChange(a.progress, b.progress)
+	c.Volumes = intChange(a.volumes, b.volumes)
+	c.TagsAdded = missing(b.tags, a.tags)
+	c.TagsRemoved = missing(a.tags, b.tags)
+	if a.comments != b.comments {
+		c.Comments = &StringChange{From: a.comments, To: b.comments}
+	}
+	changed := c.Status != nil || c.Score != nil || c.Progress != nil || c.Volumes != nil ||
+		len(c.TagsAdded) > 0 || len(c.TagsRemoved) > 0 || c.Comments != nil
+	return c, changed
+}
+
+func intChange(from, to int) *IntChange {
+	if from == to {
+		return nil
+	}
+	return &IntChange{From: from, To: to, Delta: to - from}
+}
+
+// missing returns the tags of s that are not in t, in the order of s.
+func missing(s, t []string) []string {
+	in := make(map[string]bool, len(t))
+	for _, tag := range t {
+		in[tag] = true
+	}
+	var out []string
+	for _, tag := range s {
+		if !in[tag] {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// Empty reports whether the lists are the same as far as the compared values
+// are concerned.
+func (d *Diff) Empty() bool {
+	return len(d.OnlyInA) == 0 && len(d.OnlyInB) == 0 && len(d.Changed) == 0
+}
+
+// WriteJSON writes d to w as an indented JSON object.
+func (d *Diff) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// WriteText writes d to w in a human readable form, e.g.:
+//
+//	Only in alice (1):
+//	  anime 9 "Trigun": dropped, score 6, progress 3
+//	Changed (1):
+//	  anime 1 "Cowboy Bebop": status watching -> completed, progress 20 -> 26 (+6), tags +jazz
+//
+// Sections without entries are left out.
+func (d *Diff) WriteText(w io.Writer) error {
+	_, err := io.WriteString(w, d.String())
+	return err
+}
+
+// String returns the text form of d. See WriteText.
+func (d *Diff) String() string {
+	nameA, nameB := d.NameA, d.NameB
+	if nameA == "" {
+		nameA = "a"
+	}
+	if nameB == "" {
+		nameB = "b"
+	}
+	var b strings.Builder
+	for _, section := range []struct {
+		name    string
+		entries []Entry
+	}{
+		{nameA, d.OnlyInA},
+		{nameB, d.OnlyInB},
+	} {
+		if len(section.entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "Only in %s (%d):\n", section.name, len(section.entries))
+		for _, e := range section.entries {
+			fmt.Fprintf(&b, "  %s %d %q: %s, score %d, progress %d\n", d.Kind, e.ID, e.Title, e.Status, e.Score, e.Progress)
+		}
+	}
+	if len(d.Changed) > 0 {
+		fmt.Fprintf(&b, "Changed (%d):\n", len(d.Changed))
+		for _, c := range d.Changed {
+			fmt.Fprintf(&b, "  %s %d %q: %s\n", d.Kind, c.ID, c.Title, strings.Join(c.parts(), ", "))
+		}
+	}
+	return b.String()
+}
+
+// parts returns the text form of each change of c.
+func (c Change) parts() []string {
+	var parts []string
+	if c.Status != nil {
+		parts = append(parts, fmt.Sprintf("status %s -> %s", c.Status.From, c.Status.To))
+	}
+	for _, ic := range []struct {
+		name   string
+		change *IntChange
+	}{
+		{"score", c.Score},
+		{"progress", c.Progress},
+		{"volumes", c.Volumes},
+	} {
+		if ic.change != nil {
+			parts = append(parts, fmt.Sprintf("%s %d -> %d (%+d)", ic.name, ic.change.From, ic.change.To, ic.change.Delta))
+		}
+	}
+	if len(c.TagsAdded) > 0 || len(c.TagsRemoved) > 0 {
+		var tags []string
+		for _, t := range c.TagsAdded {
+			tags = append(tags, "+"+t)
+		}
+		for _, t := range c.TagsRemoved {
+			tags = append(tags, "-"+t)
+		}
+		parts = append(parts, "tags "+strings.Join(tags, " "))
+	}
+	if c.Comments != nil {
+		parts = append(parts, fmt.Sprintf("comments %q -> %q", c.Comments.From, c.Comments.To))
+	}
+	return parts
+}
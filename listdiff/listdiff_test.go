@@ -0,0 +1,173 @@
+package listdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+func TestAnime(t *testing.T) {
+	a := []mal.UserAnime{
+		{
+			Anime: mal.Anime{ID: 9, Title: "Trigun"},
+			Status: mal.AnimeListStatus{
+				Status:             mal.AnimeStatusDropped,
+				Score:              6,
+				NumEpisodesWatched: 3,
+			},
+		},
+		{
+			Anime: mal.Anime{ID: 1, Title: "Cowboy Bebop"},
+			Status: mal.AnimeListStatus{
+				Status:             mal.AnimeStatusWatching,
+				Score:              8,
+				NumEpisodesWatched: 20,
+				Tags:               []string{"space", "classic"},
+				Comments:           "so far so good",
+			},
+		},
+		{
+			Anime:  mal.Anime{ID: 5, Title: "Unchanged"},
+			Status: mal.AnimeListStatus{Status: mal.AnimeStatusCompleted, Score: 7, Tags: []string{"x"}},
+		},
+	}
+	b := []mal.UserAnime{
+		{
+			Anime:  mal.Anime{ID: 5, Title: "Unchanged"},
+			Status: mal.AnimeListStatus{Status: mal.AnimeStatusCompleted, Score: 7, Tags: []string{"x"}},
+		},
+		{
+			Anime: mal.Anime{ID: 1, Title: "Cowboy Bebop"},
+			Status: mal.AnimeListStatus{
+				Status:             mal.AnimeStatusCompleted,
+				Score:              9,
+				NumEpisodesWatched: 26,
+				Tags:               []string{"classic", "jazz"},
+				Comments:           "great",
+			},
+		},
+		{
+			Anime:  mal.Anime{ID: 30, Title: "Neon Genesis Evangelion"},
+			Status: mal.AnimeListStatus{Status: mal.AnimeStatusPlanToWatch},
+		},
+	}
+
+	got := Anime(a, b)
+	want := &Diff{
+		Kind:    "anime",
+		OnlyInA: []Entry{{ID: 9, Title: "Trigun", Status: "dropped", Score: 6, Progress: 3}},
+		OnlyInB: []Entry{{ID: 30, Title: "Neon Genesis Evangelion", Status: "plan_to_watch"}},
+		Changed: []Change{{
+			ID:          1,
+			Title:       "Cowboy Bebop",
+			Status:      &StringChange{From: "watching", To: "completed"},
+			Score:       &IntChange{From: 8, To: 9, Delta: 1},
+			Progress:    &IntChange{From: 20, To: 26, Delta: 6},
+			TagsAdded:   []string{"jazz"},
+			TagsRemoved: []string{"space"},
+			Comments:    &StringChange{From: "so far so good", To: "great"},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Anime returned\n%+v\nwant\n%+v", got, want)
+	}
+	if got.Empty() {
+		t.Errorf("Empty returned true, want false")
+	}
+
+	got.NameA, got.NameB = "alice", "bob"
+	wantText := `Only in alice (1):
+  anime 9 "Trigun": dropped, score 6, progress 3
+Only in bob (1):
+  anime 30 "Neon Genesis Evangelion": plan_to_watch, score 0, progress 0
+Changed (1):
+  anime 1 "Cowboy Bebop": status watching -> completed, score 8 -> 9 (+1), progress 20 -> 26 (+6), tags +jazz -space, comments "so far so good" -> "great"
+`
+	var buf bytes.Buffer
+	if err := got.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText returned error: %v", err)
+	}
+	if buf.String() != wantText {
+		t.Errorf("WriteText wrote\n%s\nwant\n%s", buf.String(), wantText)
+	}
+}
+
+func TestManga(t *testing.T) {
+	a := []mal.UserManga{{
+		Manga:  mal.Manga{ID: 2, Title: "Berserk"},
+		Status: mal.MangaListStatus{Status: mal.MangaStatusReading, NumChaptersRead: 100, NumVolumesRead: 10},
+	}}
+	b := []mal.UserManga{{
+		Manga:  mal.Manga{ID: 2, Title: "Berserk"},
+		Status: mal.MangaListStatus{Status: mal.MangaStatusReading, NumChaptersRead: 90, NumVolumesRead: 12},
+	}}
+
+	got := Manga(a, b)
+	want := []Change{{
+		ID:       2,
+		Title:    "Berserk",
+		Progress: &IntChange{From: 100, To: 90, Delta: -10},
+		Volumes:  &IntChange{From: 10, To: 12, Delta: 2},
+	}}
+	if got.Kind != "manga" {
+		t.Errorf("Manga returned kind %q, want %q", got.Kind, "manga")
+	}
+	if !reflect.DeepEqual(got.Changed, want) {
+		t.Errorf("Manga returned changes %+v, want %+v", got.Changed, want)
+	}
+	wantText := "Changed (1):\n  manga 2 \"Berserk\": progress 100 -> 90 (-10), volumes 10 -> 12 (+2)\n"
+	if s := got.String(); s != wantText {
+		t.Errorf("String returned %q, want %q", s, wantText)
+	}
+}
+
+func TestDiffEmpty(t *testing.T) {
+	list := []mal.UserAnime{{
+		Anime:  mal.Anime{ID: 1},
+		Status: mal.AnimeListStatus{Status: mal.AnimeStatusWatching, Tags: []string{"a", "b"}},
+	}}
+	reordered := []mal.UserAnime{{
+		Anime:  mal.Anime{ID: 1},
+		Status: mal.AnimeListStatus{Status: mal.AnimeStatusWatching, Tags: []string{"b", "a"}},
+	}}
+	d := Anime(list, reordered)
+	if !d.Empty() {
+		t.Errorf("Empty returned false for %+v, want true", d)
+	}
+	if s := d.String(); s != "" {
+		t.Errorf("String returned %q, want empty", s)
+	}
+}
+
+func TestDiffWriteJSON(t *testing.T) {
+	d := Anime(
+		[]mal.UserAnime{{Anime: mal.Anime{ID: 1, Title: "A"}, Status: mal.AnimeListStatus{Score: 5}}},
+		[]mal.UserAnime{{Anime: mal.Anime{ID: 1, Title: "A"}, Status: mal.AnimeListStatus{Score: 7}}},
+	)
+	var buf bytes.Buffer
+	if err := d.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON returned error: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("WriteJSON wrote invalid JSON: %v", err)
+	}
+	want := map[string]interface{}{
+		"kind":      "anime",
+		"only_in_a": []interface{}{},
+		"only_in_b": []interface{}{},
+		"changed": []interface{}{
+			map[string]interface{}{
+				"id":    float64(1),
+				"title": "A",
+				"score": map[string]interface{}{"from": float64(5), "to": float64(7), "delta": float64(2)},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WriteJSON wrote %s, want %v", buf.String(), want)
+	}
+}
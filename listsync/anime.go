@@ -0,0 +1,128 @@
+package listsync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// animeFields are the list status fields that are compared.
+var animeFields = mal.Fields{
+	"list_status{status,score,num_episodes_watched,is_rewatching,start_date,finish_date,priority,num_times_rewatched,rewatch_value,tags,comments}",
+}
+
+// PlanAnime gets the anime list of the authenticated user and returns the plan
+// that makes it match desired, which maps anime IDs to their desired status.
+func PlanAnime(ctx context.Context, c *mal.Client, desired map[int]mal.AnimeListStatus, opts Options) (*Plan, error) {
+	var current []mal.UserAnime
+	it := c.User.AnimeListIterator("@me", animeFields, mal.Limit(1000))
+	for it.Next(ctx) {
+		current = append(current, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("listsync: getting anime list: %w", err)
+	}
+	return NewAnimePlan(current, desired, opts), nil
+}
+
+// NewAnimePlan returns the plan that makes the current anime list match
+// desired, which maps anime IDs to their desired status. The current list
+// needs to have all the list status fields. UpdatedAt is not compared. Desired
+// entries that are not in the list and only have zero values are not added.
+func NewAnimePlan(current []mal.UserAnime, desired map[int]mal.AnimeListStatus, opts Options) *Plan {
+	p := &Plan{opts: opts}
+	inList := make(map[int]bool, len(current))
+	for _, a := range current {
+		id := a.Anime.ID
+		inList[id] = true
+		want, ok := desired[id]
+		if !ok {
+			if !opts.KeepUnlisted {
+				p.Items = append(p.Items, Item{
+					Action: ActionDelete,
+					Kind:   "anime",
+					ID:     id,
+					Title:  a.Anime.Title,
+					apply: func(ctx context.Context, c *mal.Client) error {
+						_, err := c.Anime.DeleteMyListItem(ctx, id)
+						return err
+					},
+				})
+			}
+			continue
+		}
+		if it, ok := animeUpdate(ActionUpdate, id, a.Anime.Title, a.Status, want); ok {
+			p.Items = append(p.Items, it)
+		}
+	}
+	for id, want := range desired {
+		if inList[id] {
+			continue
+		}
+		// A desired status with only zero values has nothing to send.
+		if it, ok := animeUpdate(ActionAdd, id, "", mal.AnimeListStatus{}, want); ok {
+			p.Items = append(p.Items, it)
+		}
+	}
+	sortItems(p.Items)
+	return p
+}
+
+// animeUpdate returns the item that changes the anime from cur to want and
+// whether any field changed.
+func animeUpdate(action Action, id int, title string, cur, want mal.AnimeListStatus) (Item, bool) {
+	var (
+		d    differ
+		opts []mal.UpdateMyAnimeListStatusOption
+	)
+	if d.status(string(cur.Status), string(want.Status)) {
+		opts = append(opts, want.Status)
+	}
+	if d.int("score", cur.Score, want.Score) {
+		opts = append(opts, mal.Score(want.Score))
+	}
+	if d.int("num_episodes_watched", cur.NumEpisodesWatched, want.NumEpisodesWatched) {
+		opts = append(opts, mal.NumEpisodesWatched(want.NumEpisodesWatched))
+	}
+	if d.bool("is_rewatching", cur.IsRewatching, want.IsRewatching) {
+		opts = append(opts, mal.IsRewatching(want.IsRewatching))
+	}
+	if d.int("priority", cur.Priority, want.Priority) {
+		opts = append(opts, mal.Priority(want.Priority))
+	}
+	if d.int("num_times_rewatched", cur.NumTimesRewatched, want.NumTimesRewatched) {
+		opts = append(opts, mal.NumTimesRewatched(want.NumTimesRewatched))
+	}
+	if d.int("rewatch_value", cur.RewatchValue, want.RewatchValue) {
+		opts = append(opts, mal.RewatchValue(want.RewatchValue))
+	}
+	if d.tags(cur.Tags, want.Tags) {
+		if len(want.Tags) == 0 {
+			opts = append(opts, mal.ClearTags)
+		} else {
+			opts = append(opts, mal.Tags(want.Tags))
+		}
+	}
+	if d.string("comments", cur.Comments, want.Comments) {
+		opts = append(opts, mal.Comments(want.Comments))
+	}
+	if d.date("start_date", cur.StartDate, want.StartDate) {
+		opts = append(opts, mal.StartDate(want.StartDate))
+	}
+	if d.date("finish_date", cur.FinishDate, want.FinishDate) {
+		opts = append(opts, mal.FinishDate(want.FinishDate))
+	}
+	it := Item{
+		Action:  action,
+		Kind:    "anime",
+		ID:      id,
+		Title:   title,
+		Changes: d.changes,
+		apply: func(ctx context.Context, c *mal.Client) error {
+			_, _, err := c.Anime.UpdateMyListStatus(ctx, id, opts...)
+			return err
+		},
+	}
+	return it, len(opts) > 0
+}
@@ -0,0 +1,242 @@
+// Package listsync makes the anime or manga list of the authenticated user
+// match a desired state, for example one kept in another database.
+//
+// A plan is computed by comparing the desired entries with the current list.
+// It contains the minimal set of updates, with only the fields that changed,
+// and deletions. The plan can be printed as a dry run and then applied:
+//
+//	desired := map[int]mal.AnimeListStatus{
+//		1:  {Status: mal.AnimeStatusCompleted, Score: 9, NumEpisodesWatched: 26},
+//		30: {Status: mal.AnimeStatusWatching, NumEpisodesWatched: 12},
+//	}
+//	plan, err := listsync.PlanAnime(ctx, c, desired, listsync.Options{Concurrency: 4})
+//	// ...
+//	fmt.Print(plan)
+//	report := plan.Apply(ctx, c)
+//	for _, r := range report.Failed() {
+//		fmt.Printf("%s %s %d: %v\n", r.Item.Action, r.Item.Kind, r.Item.ID, r.Err)
+//	}
+package listsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// Options configure a plan.
+type Options struct {
+	// KeepUnlisted keeps the entries of the current list that are not in the
+	// desired entries instead of deleting them.
+	KeepUnlisted bool
+
+	// Concurrency is the maximum number of requests in flight when the plan
+	// is applied. Defaults to 4.
+	Concurrency int
+}
+
+const defaultConcurrency = 4
+
+// Action is what an item of a plan does to an entry of the list.
+type Action string
+
+// The actions of plan items.
+const (
+	ActionAdd    Action = "add"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change is the change of one field of a list entry. The values are
+// formatted for display, e.g. dates as 2022-02-20 and tags joined with commas.
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Item is one request of a plan.
+type Item struct {
+	Action Action
+	Kind   string // "anime" or "manga".
+	ID     int
+	Title  string // Empty for entries that are not in the list yet.
+
+	// Changes are the changed fields of an add or update.
+	Changes []Change
+
+	apply func(ctx context.Context, c *mal.Client) error
+}
+
+// String returns the item as a line of a dry run, e.g.:
+//
+//	update anime 1 "Cowboy Bebop": score 8 -> 9, tags "" -> "space, jazz"
+func (it Item) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %d", it.Action, it.Kind, it.ID)
+	if it.Title != "" {
+		fmt.Fprintf(&b, " %q", it.Title)
+	}
+	for i, c := range it.Changes {
+		if i == 0 {
+			b.WriteString(":")
+		} else {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, " %s %s -> %s", c.Field, c.Old, c.New)
+	}
+	return b.String()
+}
+
+// Plan is the list of requests that make a list match the desired entries.
+type Plan struct {
+	// Items are sorted by ID.
+	Items []Item
+
+	opts Options
+}
+
+// String returns the plan as a dry run, with one line per item.
+func (p *Plan) String() string {
+	var b strings.Builder
+	for _, it := range p.Items {
+		b.WriteString(it.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Result is the result of applying an item of a plan.
+type Result struct {
+	Item Item
+	Err  error
+}
+
+// Report holds the results of applying a plan, in the order of the items.
+type Report struct {
+	Results []Result
+}
+
+// Failed returns the results of the items that failed.
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Err returns an error that wraps the error of the first item that failed or
+// nil if all items succeeded.
+func (r *Report) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	first := failed[0]
+	return fmt.Errorf("listsync: %d of %d items failed, first %s %s %d: %w",
+		len(failed), len(r.Results), first.Item.Action, first.Item.Kind, first.Item.ID, first.Err)
+}
+
+// Apply sends the requests of the plan with at most Options.Concurrency
+// requests in flight. It does not stop when an item fails; the error of each
+// item is reported in its result. Items that had not been applied when ctx was
+// done fail with the error of ctx. Deleting an entry that is not in the list
+// anymore succeeds.
+func (p *Plan) Apply(ctx context.Context, c *mal.Client) *Report {
+	concurrency := p.opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	report := &Report{Results: make([]Result, len(p.Items))}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, it := range p.Items {
+		report.Results[i].Item = it
+		if err := ctx.Err(); err != nil {
+			report.Results[i].Err = err
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			report.Results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(i int, it Item) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := it.apply(ctx, c)
+			if it.Action == ActionDelete && errors.Is(err, mal.ErrNotFound) {
+				err = nil
+			}
+			report.Results[i].Err = err
+		}(i, it)
+	}
+	wg.Wait()
+	return report
+}
+
+func sortItems(items []Item) {
+	sort.SliceStable(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+}
+
+// differ compares the fields of two list statuses and records the changes.
+// Each method reports whether the field changed.
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(field, old, new string) bool {
+	d.changes = append(d.changes, Change{Field: field, Old: old, New: new})
+	return true
+}
+
+func (d *differ) int(field string, old, new int) bool {
+	return old != new && d.add(field, strconv.Itoa(old), strconv.Itoa(new))
+}
+
+func (d *differ) bool(field string, old, new bool) bool {
+	return old != new && d.add(field, strconv.FormatBool(old), strconv.FormatBool(new))
+}
+
+func (d *differ) string(field, old, new string) bool {
+	return old != new && d.add(field, strconv.Quote(old), strconv.Quote(new))
+}
+
+func (d *differ) status(old, new string) bool {
+	// An empty desired status leaves the status as is.
+	return new != "" && old != new && d.add("status", formatStatus(old), new)
+}
+
+func formatStatus(s string) string {
+	if s == "" {
+		return `""`
+	}
+	return s
+}
+
+func (d *differ) tags(old, new []string) bool {
+	o, n := strings.Join(old, ", "), strings.Join(new, ", ")
+	return o != n && d.add("tags", strconv.Quote(o), strconv.Quote(n))
+}
+
+func (d *differ) date(field string, old, new mal.Date) bool {
+	return old != new && d.add(field, formatDate(old), formatDate(new))
+}
+
+func formatDate(d mal.Date) string {
+	if d.IsZero() {
+		return `""`
+	}
+	return d.String()
+}
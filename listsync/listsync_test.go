@@ -0,0 +1,225 @@
+package listsync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/mal"
+	"github.com/nstratos/go-myanimelist/maltest"
+)
+
+func TestNewAnimePlan(t *testing.T) {
+	current := []mal.UserAnime{
+		{
+			Anime: mal.Anime{ID: 1, Title: "Cowboy Bebop"},
+			Status: mal.AnimeListStatus{
+				Status:             mal.AnimeStatusWatching,
+				Score:              8,
+				NumEpisodesWatched: 20,
+				Tags:               []string{"space"},
+				UpdatedAt:          time.Now(),
+			},
+		},
+		{
+			Anime:  mal.Anime{ID: 5, Title: "Unchanged"},
+			Status: mal.AnimeListStatus{Status: mal.AnimeStatusCompleted, Score: 7, Tags: []string{}},
+		},
+		{
+			Anime:  mal.Anime{ID: 9, Title: "Unlisted"},
+			Status: mal.AnimeListStatus{Status: mal.AnimeStatusDropped},
+		},
+	}
+	desired := map[int]mal.AnimeListStatus{
+		1: {
+			Status:             mal.AnimeStatusCompleted,
+			Score:              8,
+			NumEpisodesWatched: 26,
+			FinishDate:         mal.Date{Year: 2022, Month: time.February, Day: 20},
+		},
+		5: {Status: mal.AnimeStatusCompleted, Score: 7},
+		3: {Status: mal.AnimeStatusPlanToWatch, Comments: "later"},
+	}
+
+	plan := NewAnimePlan(current, desired, Options{})
+	want := `update anime 1 "Cowboy Bebop": status watching -> completed, num_episodes_watched 20 -> 26, tags "space" -> "", finish_date "" -> 2022-02-20
+add anime 3: status "" -> plan_to_watch, comments "" -> "later"
+delete anime 9 "Unlisted"
+`
+	if got := plan.String(); got != want {
+		t.Errorf("plan\nhave:\n%s\nwant:\n%s", got, want)
+	}
+
+	plan = NewAnimePlan(current, desired, Options{KeepUnlisted: true})
+	var actions []Action
+	for _, it := range plan.Items {
+		actions = append(actions, it.Action)
+	}
+	if want := []Action{ActionUpdate, ActionAdd}; !reflect.DeepEqual(actions, want) {
+		t.Errorf("plan with KeepUnlisted has actions %v, want %v", actions, want)
+	}
+}
+
+func TestNewMangaPlan(t *testing.T) {
+	current := []mal.UserManga{{
+		Manga:  mal.Manga{ID: 2, Title: "Berserk"},
+		Status: mal.MangaListStatus{Status: mal.MangaStatusReading, NumVolumesRead: 40, NumChaptersRead: 360, Comments: "old"},
+	}}
+	desired := map[int]mal.MangaListStatus{
+		2: {Status: mal.MangaStatusReading, NumVolumesRead: 41, NumChaptersRead: 364, IsRereading: true},
+	}
+	want := `update manga 2 "Berserk": num_volumes_read 40 -> 41, num_chapters_read 360 -> 364, is_rereading false -> true, comments "old" -> ""
+`
+	if got := NewMangaPlan(current, desired, Options{}).String(); got != want {
+		t.Errorf("plan\nhave:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNewPlanZeroAdd(t *testing.T) {
+	anime := NewAnimePlan(nil, map[int]mal.AnimeListStatus{1: {}}, Options{})
+	if len(anime.Items) != 0 {
+		t.Errorf("NewAnimePlan with a zero desired status returned items:\n%s", anime)
+	}
+	manga := NewMangaPlan(nil, map[int]mal.MangaListStatus{2: {}}, Options{})
+	if len(manga.Items) != 0 {
+		t.Errorf("NewMangaPlan with a zero desired status returned items:\n%s", manga)
+	}
+}
+
+func newServer(t *testing.T) *maltest.Server {
+	t.Helper()
+	s := maltest.NewServer()
+	t.Cleanup(s.Close)
+	for id := 1; id <= 5; id++ {
+		s.AddAnime(mal.Anime{ID: id, Title: "Anime", NumEpisodes: 26})
+		s.AddManga(mal.Manga{ID: id, Title: "Manga"})
+	}
+	s.AddUser(mal.User{Name: "alice"})
+	return s
+}
+
+func TestPlanAnimeApply(t *testing.T) {
+	s := newServer(t)
+	s.SetAnimeListStatus("alice", 1, mal.AnimeListStatus{Status: mal.AnimeStatusWatching, Score: 6, Tags: []string{"a"}, Comments: "old"})
+	s.SetAnimeListStatus("alice", 2, mal.AnimeListStatus{Status: mal.AnimeStatusDropped})
+	s.SetAnimeListStatus("alice", 4, mal.AnimeListStatus{Status: mal.AnimeStatusCompleted, Score: 9, NumEpisodesWatched: 26})
+	desired := map[int]mal.AnimeListStatus{
+		1: {Status: mal.AnimeStatusCompleted, Score: 8, NumEpisodesWatched: 26, Priority: 2, StartDate: mal.Date{Year: 2021}},
+		3: {Status: mal.AnimeStatusPlanToWatch, Tags: []string{"next"}},
+		4: {Status: mal.AnimeStatusCompleted, Score: 9, NumEpisodesWatched: 26},
+	}
+
+	ctx := context.Background()
+	c := s.Client("alice")
+	plan, err := PlanAnime(ctx, c, desired, Options{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("PlanAnime returned error: %v", err)
+	}
+	if got, want := len(plan.Items), 3; got != want {
+		t.Fatalf("PlanAnime returned %d items, want %d:\n%s", got, want, plan)
+	}
+	report := plan.Apply(ctx, c)
+	if err := report.Err(); err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+
+	for id, want := range desired {
+		got, ok := s.AnimeListStatus("alice", id)
+		if !ok {
+			t.Errorf("anime %d is not in the list after Apply", id)
+			continue
+		}
+		got.UpdatedAt = time.Time{}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("anime %d after Apply\nhave: %+v\nwant: %+v", id, got, want)
+		}
+	}
+	if _, ok := s.AnimeListStatus("alice", 2); ok {
+		t.Error("anime 2 is still in the list after Apply")
+	}
+
+	plan, err = PlanAnime(ctx, c, desired, Options{})
+	if err != nil {
+		t.Fatalf("PlanAnime returned error: %v", err)
+	}
+	if len(plan.Items) != 0 {
+		t.Errorf("PlanAnime after Apply returned items:\n%s", plan)
+	}
+}
+
+func TestPlanMangaApply(t *testing.T) {
+	s := newServer(t)
+	s.SetMangaListStatus("alice", 1, mal.MangaListStatus{Status: mal.MangaStatusReading, NumChaptersRead: 10})
+	desired := map[int]mal.MangaListStatus{
+		1: {Status: mal.MangaStatusReading, NumChaptersRead: 12, NumVolumesRead: 2},
+		2: {Status: mal.MangaStatusPlanToRead},
+	}
+
+	ctx := context.Background()
+	c := s.Client("alice")
+	plan, err := PlanManga(ctx, c, desired, Options{})
+	if err != nil {
+		t.Fatalf("PlanManga returned error: %v", err)
+	}
+	if err := plan.Apply(ctx, c).Err(); err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+	for id, want := range desired {
+		got, _ := s.MangaListStatus("alice", id)
+		got.UpdatedAt = time.Time{}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("manga %d after Apply\nhave: %+v\nwant: %+v", id, got, want)
+		}
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	s := newServer(t)
+	s.SetAnimeListStatus("alice", 1, mal.AnimeListStatus{Status: mal.AnimeStatusWatching})
+	s.SetAnimeListStatus("alice", 2, mal.AnimeListStatus{Status: mal.AnimeStatusWatching})
+	desired := map[int]mal.AnimeListStatus{
+		3: {Status: mal.AnimeStatusWatching},
+		4: {Status: mal.AnimeStatusWatching},
+	}
+
+	ctx := context.Background()
+	c := s.Client("alice")
+	plan, err := PlanAnime(ctx, c, desired, Options{Concurrency: 1})
+	if err != nil {
+		t.Fatalf("PlanAnime returned error: %v", err)
+	}
+	// Anime 2 was already deleted elsewhere, which is not an error.
+	if _, err := c.Anime.DeleteMyListItem(ctx, 2); err != nil {
+		t.Fatalf("DeleteMyListItem returned error: %v", err)
+	}
+	s.FailNext(1, http.MethodPatch, "anime/3/my_list_status", http.StatusBadRequest, "invalid_parameters")
+
+	report := plan.Apply(ctx, c)
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Item.ID != 3 || failed[0].Item.Action != ActionAdd {
+		t.Fatalf("Apply failed items %+v, want only the add of anime 3", failed)
+	}
+	if err := report.Err(); err == nil {
+		t.Error("Report.Err returned nil, want error")
+	} else if !errors.Is(err, failed[0].Err) {
+		t.Errorf("Report.Err = %v, want it to wrap %v", err, failed[0].Err)
+	}
+	if _, ok := s.AnimeListStatus("alice", 4); !ok {
+		t.Error("anime 4 was not added after anime 3 failed")
+	}
+	if _, ok := s.AnimeListStatus("alice", 1); ok {
+		t.Error("anime 1 was not deleted after anime 3 failed")
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	report = plan.Apply(canceled, c)
+	for _, r := range report.Results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("Apply with canceled context returned error %v for %s %d, want %v", r.Err, r.Item.Action, r.Item.ID, context.Canceled)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package listsync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// mangaFields are the list status fields that are compared.
+var mangaFields = mal.Fields{
+	"list_status{status,score,num_volumes_read,num_chapters_read,is_rereading,start_date,finish_date,priority,num_times_reread,reread_value,tags,comments}",
+}
+
+// PlanManga gets the manga list of the authenticated user and returns the plan
+// that makes it match desired, which maps manga IDs to their desired status.
+func PlanManga(ctx context.Context, c *mal.Client, desired map[int]mal.MangaListStatus, opts Options) (*Plan, error) {
+	var current []mal.UserManga
+	it := c.User.MangaListIterator("@me", mangaFields, mal.Limit(1000))
+	for it.Next(ctx) {
+		current = append(current, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("listsync: getting manga list: %w", err)
+	}
+	return NewMangaPlan(current, desired, opts), nil
+}
+
+// NewMangaPlan returns the plan that makes the current manga list match
+// desired, which maps manga IDs to their desired status. The current list
+// needs to have all the list status fields. UpdatedAt is not compared. Desired
+// entries that are not in the list and only have zero values are not added.
+func NewMangaPlan(current []mal.UserManga, desired map[int]mal.MangaListStatus, opts Options) *Plan {
+	p := &Plan{opts: opts}
+	inList := make(map[int]bool, len(current))
+	for _, m := range current {
+		id := m.Manga.ID
+		inList[id] = true
+		want, ok := desired[id]
+		if !ok {
+			if !opts.KeepUnlisted {
+				p.Items = append(p.Items, Item{
+					Action: ActionDelete,
+					Kind:   "manga",
+					ID:     id,
+					Title:  m.Manga.Title,
+					apply: func(ctx context.Context, c *mal.Client) error {
+						_, err := c.Manga.DeleteMyListItem(ctx, id)
+						return err
+					},
+				})
+			}
+			continue
+		}
+		if it, ok := mangaUpdate(ActionUpdate, id, m.Manga.Title, m.Status, want); ok {
+			p.Items = append(p.Items, it)
+		}
+	}
+	for id, want := range desired {
+		if inList[id] {
+			continue
+		}
+		// A desired status with only zero values has nothing to send.
+		if it, ok := mangaUpdate(ActionAdd, id, "", mal.MangaListStatus{}, want); ok {
+			p.Items = append(p.Items, it)
+		}
+	}
+	sortItems(p.Items)
+	return p
+}
+
+// mangaUpdate returns the item that changes the manga from cur to want and
+// whether any field changed.
+func mangaUpdate(action Action, id int, title string, cur, want mal.MangaListStatus) (Item, bool) {
+	var (
+		d    differ
+		opts []mal.UpdateMyMangaListStatusOption
+	)
+	if d.status(string(cur.Status), string(want.Status)) {
+		opts = append(opts, want.Status)
+	}
+	if d.int("score", cur.Score, want.Score) {
+		opts = append(opts, mal.Score(want.Score))
+	}
+	if d.int("num_volumes_read", cur.NumVolumesRead, want.NumVolumesRead) {
+		opts = append(opts, mal.NumVolumesRead(want.NumVolumesRead))
+	}
+	if d.int("num_chapters_read", cur.NumChaptersRead, want.NumChaptersRead) {
+		opts = append(opts, mal.NumChaptersRead(want.NumChaptersRead))
+	}
+	if d.bool("is_rereading", cur.IsRereading, want.IsRereading) {
+		opts = append(opts, mal.IsRereading(want.IsRereading))
+	}
+	if d.int("priority", cur.Priority, want.Priority) {
+		opts = append(opts, mal.Priority(want.Priority))
+	}
+	if d.int("num_times_reread", cur.NumTimesReread, want.NumTimesReread) {
+		opts = append(opts, mal.NumTimesReread(want.NumTimesReread))
+	}
+	if d.int("reread_value", cur.RereadValue, want.RereadValue) {
+		opts = append(opts, mal.RereadValue(want.RereadValue))
+	}
+	if d.tags(cur.Tags, want.Tags) {
+		if len(want.Tags) == 0 {
+			opts = append(opts, mal.ClearTags)
+		} else {
+			opts = append(opts, mal.Tags(want.Tags))
+		}
+	}
+	if d.string("comments", cur.Comments, want.Comments) {
+		opts = append(opts, mal.Comments(want.Comments))
+	}
+	if d.date("start_date", cur.StartDate, want.StartDate) {
+		opts = append(opts, mal.StartDate(want.StartDate))
+	}
+	if d.date("finish_date", cur.FinishDate, want.FinishDate) {
+		opts = append(opts, mal.FinishDate(want.FinishDate))
+	}
+	it := Item{
+		Action:  action,
+		Kind:    "manga",
+		ID:      id,
+		Title:   title,
+		Changes: d.changes,
+		apply: func(ctx context.Context, c *mal.Client) error {
+			_, _, err := c.Manga.UpdateMyListStatus(ctx, id, opts...)
+			return err
+		},
+	}
+	return it, len(opts) > 0
+}
@@ -24,27 +24,27 @@ type Anime struct {
 	Title                  string             `json:"title"`
 	MainPicture            Picture            `json:"main_picture"`
 	AlternativeTitles      Titles             `json:"alternative_titles"`
-	StartDate              string             `json:"start_date"`
-	EndDate                string             `json:"end_date"`
+	StartDate              Date               `json:"start_date"`
+	EndDate                Date               `json:"end_date"`
 	Synopsis               string             `json:"synopsis"`
 	Mean                   float64            `json:"mean"`
 	Rank                   int                `json:"rank"`
 	Popularity             int                `json:"popularity"`
 	NumListUsers           int                `json:"num_list_users"`
 	NumScoringUsers        int                `json:"num_scoring_users"`
-	NSFW                   string             `json:"nsfw"`
+	NSFW                   NSFWLevel          `json:"nsfw"`
 	CreatedAt              time.Time          `json:"created_at"`
 	UpdatedAt              time.Time          `json:"updated_at"`
-	MediaType              string             `json:"media_type"`
-	Status                 string             `json:"status"`
+	MediaType              AnimeMediaType     `json:"media_type"`
+	Status                 AnimeAiringStatus  `json:"status"`
 	Genres                 []Genre            `json:"genres"`
 	MyListStatus           AnimeListStatus    `json:"my_list_status"`
 	NumEpisodes            int                `json:"num_episodes"`
 	StartSeason            StartSeason        `json:"start_season"`
 	Broadcast              Broadcast          `json:"broadcast"`
-	Source                 string             `json:"source"`
+	Source                 AnimeSource        `json:"source"`
 	AverageEpisodeDuration int                `json:"average_episode_duration"`
-	Rating                 string             `json:"rating"`
+	Rating                 AnimeRating        `json:"rating"`
 	Pictures               []Picture          `json:"pictures"`
 	Background             string             `json:"background"`
 	RelatedAnime           []RelatedAnime     `json:"related_anime"`
@@ -182,6 +182,14 @@ func (n NSFW) apply(v *url.Values)              { v.Set("nsfw", strconv.FormatBo
 // Example:
 //
 //	Fields{"synopsis", "my_list_status{priority,comments}"}
+//
+// To avoid typos, the same option can be built from typed field constants
+// using AnimeFields, MangaFields or UserFields:
+//
+//	AnimeFields(
+//		AnimeFieldSynopsis,
+//		AnimeMyListStatus(AnimeListStatusFieldPriority, AnimeListStatusFieldComments),
+//	)
 type Fields []string
 
 func (f Fields) seasonalAnimeApply(v *url.Values) { f.apply(v) }
@@ -304,7 +312,11 @@ func (s *AnimeService) Seasonal(ctx context.Context, year int, season AnimeSeaso
 	for i := range options {
 		oo[i] = optionFromSeasonalAnimeOption(options[i])
 	}
-	return s.list(ctx, fmt.Sprintf("anime/season/%d/%s", year, season), oo...)
+	return s.list(ctx, seasonalPath(year, season), oo...)
+}
+
+func seasonalPath(year int, season AnimeSeason) string {
+	return fmt.Sprintf("anime/season/%d/%s", year, season)
 }
 
 // Suggested returns suggested anime for the authorized user. If the user is new
@@ -272,6 +272,27 @@ func TestAnimeServiceSeasonal(t *testing.T) {
 	testResponseOffset(t, resp, 4, 0, "Anime.Seasonal")
 }
 
+func TestAnimeServiceSeasonalFor(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/anime/season/2023/fall", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		testURLValues(t, r, urlValues{"sort": "anime_score"})
+		fmt.Fprint(w, `{"data":[{"node":{"id":1}}],"paging":{}}`)
+	})
+
+	ctx := context.Background()
+	got, _, err := client.Anime.SeasonalFor(ctx, Season{Year: 2023, Season: AnimeSeasonFall}, SortSeasonalByAnimeScore)
+	if err != nil {
+		t.Errorf("Anime.SeasonalFor returned error: %v", err)
+	}
+	want := []Anime{{ID: 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Anime.SeasonalFor returned\nhave: %+v\n\nwant: %+v", got, want)
+	}
+}
+
 func TestAnimeServiceSuggested(t *testing.T) {
 	client, mux, teardown := setup()
 	defer teardown()
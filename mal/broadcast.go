@@ -0,0 +1,121 @@
+package mal
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JST is the Japan Standard Time zone in which MyAnimeList expresses broadcast
+// schedules and the start and end dates of anime. Japan does not observe
+// daylight saving time.
+var JST = time.FixedZone("JST", 9*60*60)
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// Weekday returns the day of the week of the broadcast in JST. It returns false
+// if the day is unknown, for example when it is "other".
+func (b Broadcast) Weekday() (time.Weekday, bool) {
+	d, ok := weekdays[strings.ToLower(b.DayOfTheWeek)]
+	return d, ok
+}
+
+// Clock returns the hour and minute of the start time of the broadcast in JST.
+// It returns false if the start time is missing or invalid. Late night
+// broadcasts can be listed with hours past midnight, such as 25:30 for 01:30 of
+// the next day, in which case the hour is 24 or more.
+func (b Broadcast) Clock() (hour, min int, ok bool) {
+	parts := strings.Split(b.StartTime, ":")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	hour, err1 := strconv.Atoi(parts[0])
+	min, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || hour < 0 || hour > 29 || min < 0 || min > 59 {
+		return 0, 0, false
+	}
+	return hour, min, true
+}
+
+// Next returns the first broadcast strictly after the given time, in loc. The
+// day of the week may differ from DayOfTheWeek when loc is behind or ahead of
+// JST, e.g. a Saturday 01:00 JST broadcast is on Friday in Europe and the
+// Americas. If loc is nil, the time is in JST. Next returns the zero time if
+// the broadcast schedule is unknown.
+func (b Broadcast) Next(after time.Time, loc *time.Location) time.Time {
+	day, ok := b.Weekday()
+	if !ok {
+		return time.Time{}
+	}
+	hour, min, ok := b.Clock()
+	if !ok {
+		return time.Time{}
+	}
+	if loc == nil {
+		loc = JST
+	}
+	t := after.In(JST)
+	days := int(day-t.Weekday()+7) % 7
+	next := time.Date(t.Year(), t.Month(), t.Day()+days, hour, min, 0, 0, JST)
+	// A start time past midnight can make the broadcast of the previous week
+	// still upcoming.
+	for next.AddDate(0, 0, -7).After(after) {
+		next = next.AddDate(0, 0, -7)
+	}
+	for !next.After(after) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next.In(loc)
+}
+
+// EpisodeAirTimes returns the expected air times of the episodes of the anime
+// in loc, assuming one episode airs every week on the Broadcast schedule
+// starting from StartDate. The number of episodes is NumEpisodes, or, if it is
+// unknown, as many as air until EndDate. If both are set, episodes that would
+// air after EndDate are left out. If loc is nil, the times are in JST.
+//
+// EpisodeAirTimes returns nil if the broadcast schedule or StartDate, which
+// needs to be a full date, is unknown or if both NumEpisodes and EndDate are
+// unknown. It requires the broadcast, start_date, end_date and num_episodes
+// fields.
+func (a Anime) EpisodeAirTimes(loc *time.Location) []time.Time {
+	hour, _, ok := a.Broadcast.Clock()
+	if !ok || a.StartDate.Day == 0 {
+		return nil
+	}
+	hasEnd := a.EndDate.Day != 0
+	if a.NumEpisodes <= 0 && !hasEnd {
+		return nil
+	}
+	// The broadcast date in JST of an air time, accounting for start times
+	// past midnight that belong to the previous day.
+	overflow := time.Duration(hour/24) * 24 * time.Hour
+	broadcastDate := func(t time.Time) Date { return DateOf(t.Add(-overflow).In(JST)) }
+
+	start := time.Date(a.StartDate.Year, a.StartDate.Month, a.StartDate.Day, 0, 0, 0, 0, JST)
+	next := a.Broadcast.Next(start.Add(overflow-time.Nanosecond), JST)
+	if next.IsZero() {
+		return nil
+	}
+	var times []time.Time
+	for a.NumEpisodes <= 0 || len(times) < a.NumEpisodes {
+		if hasEnd && broadcastDate(next).After(a.EndDate) {
+			break
+		}
+		if loc != nil {
+			times = append(times, next.In(loc))
+		} else {
+			times = append(times, next)
+		}
+		next = next.AddDate(0, 0, 7)
+	}
+	return times
+}
@@ -0,0 +1,159 @@
+package mal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBroadcastNext(t *testing.T) {
+	cet := time.FixedZone("CET", 1*60*60)
+	pst := time.FixedZone("PST", -8*60*60)
+
+	// Wednesday, January 5th, 2022 12:00 JST.
+	after := time.Date(2022, 1, 5, 12, 0, 0, 0, JST)
+	tests := []struct {
+		name      string
+		broadcast Broadcast
+		after     time.Time
+		loc       *time.Location
+		want      time.Time
+	}{
+		{
+			name:      "later this week",
+			broadcast: Broadcast{DayOfTheWeek: "saturday", StartTime: "23:00"},
+			after:     after,
+			loc:       JST,
+			want:      time.Date(2022, 1, 8, 23, 0, 0, 0, JST),
+		},
+		{
+			name:      "same day later",
+			broadcast: Broadcast{DayOfTheWeek: "wednesday", StartTime: "12:30"},
+			after:     after,
+			want:      time.Date(2022, 1, 5, 12, 30, 0, 0, JST),
+		},
+		{
+			name:      "same time is next week",
+			broadcast: Broadcast{DayOfTheWeek: "wednesday", StartTime: "12:00"},
+			after:     after,
+			want:      time.Date(2022, 1, 12, 12, 0, 0, 0, JST),
+		},
+		{
+			name:      "previous day in Europe",
+			broadcast: Broadcast{DayOfTheWeek: "saturday", StartTime: "01:30"},
+			after:     after,
+			loc:       cet,
+			want:      time.Date(2022, 1, 7, 17, 30, 0, 0, cet),
+		},
+		{
+			name:      "previous day in the US",
+			broadcast: Broadcast{DayOfTheWeek: "Monday", StartTime: "10:00"},
+			after:     after,
+			loc:       pst,
+			want:      time.Date(2022, 1, 9, 17, 0, 0, 0, pst),
+		},
+		{
+			name:      "start time past midnight",
+			broadcast: Broadcast{DayOfTheWeek: "tuesday", StartTime: "25:30"},
+			after:     after.Add(-12 * time.Hour), // Wednesday 00:00 JST.
+			want:      time.Date(2022, 1, 5, 1, 30, 0, 0, JST),
+		},
+		{
+			name:      "unknown day",
+			broadcast: Broadcast{DayOfTheWeek: "other", StartTime: "10:00"},
+			after:     after,
+		},
+		{
+			name:      "missing time",
+			broadcast: Broadcast{DayOfTheWeek: "monday"},
+			after:     after,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.broadcast.Next(tt.after, tt.loc)
+			if !got.Equal(tt.want) {
+				t.Errorf("Next = %v, want %v", got, tt.want)
+			}
+			if !tt.want.IsZero() && got.Location() != tt.want.Location() {
+				t.Errorf("Next returned location %v, want %v", got.Location(), tt.want.Location())
+			}
+		})
+	}
+}
+
+func TestBroadcastClock(t *testing.T) {
+	tests := []struct {
+		in        string
+		hour, min int
+		wantOK    bool
+	}{
+		{"23:30", 23, 30, true},
+		{"00:05", 0, 5, true},
+		{"25:00", 25, 0, true},
+		{"", 0, 0, false},
+		{"9", 0, 0, false},
+		{"12:60", 0, 0, false},
+		{"ab:cd", 0, 0, false},
+	}
+	for _, tt := range tests {
+		hour, min, ok := Broadcast{StartTime: tt.in}.Clock()
+		if hour != tt.hour || min != tt.min || ok != tt.wantOK {
+			t.Errorf("Clock(%q) = %d, %d, %v, want %d, %d, %v", tt.in, hour, min, ok, tt.hour, tt.min, tt.wantOK)
+		}
+	}
+}
+
+func TestAnimeEpisodeAirTimes(t *testing.T) {
+	utc := time.UTC
+	a := Anime{
+		StartDate:   Date{Year: 2022, Month: time.January, Day: 8},
+		EndDate:     Date{Year: 2022, Month: time.January, Day: 22},
+		NumEpisodes: 3,
+		Broadcast:   Broadcast{DayOfTheWeek: "saturday", StartTime: "01:30"},
+	}
+	want := []time.Time{
+		time.Date(2022, 1, 7, 16, 30, 0, 0, utc),
+		time.Date(2022, 1, 14, 16, 30, 0, 0, utc),
+		time.Date(2022, 1, 21, 16, 30, 0, 0, utc),
+	}
+	if got := a.EpisodeAirTimes(utc); !reflect.DeepEqual(got, want) {
+		t.Errorf("EpisodeAirTimes = %v, want %v", got, want)
+	}
+
+	// Without NumEpisodes the episodes are enumerated until EndDate.
+	a.NumEpisodes = 0
+	if got := a.EpisodeAirTimes(utc); !reflect.DeepEqual(got, want) {
+		t.Errorf("EpisodeAirTimes without NumEpisodes = %v, want %v", got, want)
+	}
+
+	// Episodes past EndDate are left out.
+	a.NumEpisodes = 12
+	if got := a.EpisodeAirTimes(utc); len(got) != 3 {
+		t.Errorf("EpisodeAirTimes returned %d episodes, want 3 until EndDate", len(got))
+	}
+
+	// Start times past midnight belong to the broadcast day.
+	late := Anime{
+		StartDate: Date{Year: 2022, Month: time.January, Day: 8},
+		EndDate:   Date{Year: 2022, Month: time.January, Day: 15},
+		Broadcast: Broadcast{DayOfTheWeek: "saturday", StartTime: "25:00"},
+	}
+	wantLate := []time.Time{
+		time.Date(2022, 1, 9, 1, 0, 0, 0, JST),
+		time.Date(2022, 1, 16, 1, 0, 0, 0, JST),
+	}
+	if got := late.EpisodeAirTimes(nil); !reflect.DeepEqual(got, wantLate) {
+		t.Errorf("EpisodeAirTimes with late start time = %v, want %v", got, wantLate)
+	}
+
+	for _, unknown := range []Anime{
+		{StartDate: Date{Year: 2022, Month: time.January}, NumEpisodes: 12, Broadcast: a.Broadcast},
+		{StartDate: a.StartDate, Broadcast: a.Broadcast},
+		{StartDate: a.StartDate, NumEpisodes: 12, Broadcast: Broadcast{DayOfTheWeek: "other"}},
+	} {
+		if got := unknown.EpisodeAirTimes(utc); got != nil {
+			t.Errorf("EpisodeAirTimes(%+v) = %v, want nil", unknown, got)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package mal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	defaultCacheDetailsTTL = 24 * time.Hour
+	defaultCacheListTTL    = 5 * time.Minute
+	defaultCacheMyInfoTTL  = 1 * time.Minute
+)
+
+// Cache is a storage for API responses. Implementations must be safe for
+// concurrent use. See MemoryCache and DiskCache.
+type Cache interface {
+	// Get returns the value stored for key and true or false if there is no
+	// value or it has expired.
+	Get(key string) ([]byte, bool)
+	// Set stores the value for key. The value should expire after ttl.
+	Set(key string, value []byte, ttl time.Duration)
+	// Delete removes the value stored for key, if any.
+	Delete(key string)
+}
+
+// CacheConfig configures the response cache of a Client. See WithCache.
+type CacheConfig struct {
+	// Cache stores the responses. It can be shared between multiple Clients.
+	Cache Cache
+
+	// Identity identifies the user on whose behalf the Client sends requests,
+	// for example their user ID. It is part of the cache keys so that the
+	// responses of one user are never served to another when Cache is
+	// shared.
+	//
+	// If Identity is empty, it is derived from the credentials of each
+	// request: a hash of its Authorization header or, for HTTP clients
+	// created by the golang.org/x/oauth2 package, of the access token that
+	// the transport adds. HTTP clients with the default transport are
+	// assumed to only access public data. Requests of HTTP clients with any
+	// other transport are not cached, since they may be authenticated in a
+	// way that cannot be told apart.
+	Identity string
+
+	// DetailsTTL is how long the responses of the anime and manga details
+	// and forum methods are cached. Defaults to 24 hours.
+	DetailsTTL time.Duration
+
+	// ListTTL is how long the responses of the list, ranking, seasonal,
+	// suggested and user list methods are cached. Defaults to 5 minutes.
+	ListTTL time.Duration
+
+	// MyInfoTTL is how long the response of UserService.MyInfo is cached.
+	// Defaults to 1 minute.
+	MyInfoTTL time.Duration
+}
+
+// WithCache is a client option which enables caching the responses of GET
+// requests. Cached responses are returned without contacting the API and have
+// Response.Cached set.
+//
+// When an entry of the user's list is updated or deleted, the cached user list
+// pages and the cached details of that anime or manga are invalidated. The
+// invalidation is recorded in the Cache itself, so it applies to every Client
+// that shares the Cache with the same Identity, including Clients created after
+// a restart with a DiskCache.
+//
+// Responses of different users are kept apart by CacheConfig.Identity, which
+// is derived from the access token if it is not set. Setting it, for example to
+// the user ID, lets the Clients of a user share their responses and
+// invalidations even if their access tokens differ.
+func WithCache(conf CacheConfig) ClientOption {
+	return func(c *Client) {
+		if conf.Cache == nil {
+			c.cache = nil
+			return
+		}
+		if conf.DetailsTTL <= 0 {
+			conf.DetailsTTL = defaultCacheDetailsTTL
+		}
+		if conf.ListTTL <= 0 {
+			conf.ListTTL = defaultCacheListTTL
+		}
+		if conf.MyInfoTTL <= 0 {
+			conf.MyInfoTTL = defaultCacheMyInfoTTL
+		}
+		c.cache = &responseCache{CacheConfig: conf}
+	}
+}
+
+// responseCache stores the responses of a Client in the configured Cache.
+//
+// Responses are invalidated with generations: every cache key includes the
+// generations of the scopes that the response depends on, such as "anime" for
+// the user's anime list or "anime/1" for the details of anime 1. Changing a
+// generation makes the keys that include it unreachable, and the entries
+// expire on their own. Generations are stored as entries of the Cache too.
+type responseCache struct {
+	CacheConfig
+}
+
+type endpointKind int
+
+const (
+	endpointList endpointKind = iota
+	endpointDetails
+	endpointMyInfo
+)
+
+var (
+	detailsPath      = regexp.MustCompile(`^(anime|manga)/\d+$|^forum/boards$|^forum/topic/\d+$`)
+	myListStatusPath = regexp.MustCompile(`^(anime|manga)/(\d+)/my_list_status$`)
+	userListPath     = regexp.MustCompile(`^users/[^/]+/(anime|manga)list$`)
+)
+
+// classifyPath returns the kind of endpoint of a path relative to the base URL.
+func classifyPath(path string) endpointKind {
+	switch {
+	case path == "users/@me":
+		return endpointMyInfo
+	case detailsPath.MatchString(path):
+		return endpointDetails
+	}
+	return endpointList
+}
+
+func (rc *responseCache) ttl(path string) time.Duration {
+	switch classifyPath(path) {
+	case endpointDetails:
+		return rc.DetailsTTL
+	case endpointMyInfo:
+		return rc.MyInfoTTL
+	}
+	return rc.ListTTL
+}
+
+// scopes returns the scopes of the responses of path that change when an
+// entry of the user's list is updated or deleted.
+func scopes(path string) []string {
+	switch {
+	case path == "users/@me":
+		return []string{"anime", "manga"}
+	case strings.HasPrefix(path, "forum"):
+		return nil
+	case detailsPath.MatchString(path):
+		return []string{path}
+	}
+	if m := userListPath.FindStringSubmatch(path); m != nil {
+		return []string{m[1]}
+	}
+	for _, media := range []string{"anime", "manga"} {
+		if path == media || strings.HasPrefix(path, media+"/") {
+			return []string{media}
+		}
+	}
+	return nil
+}
+
+// cacheIdentity returns the identity of the user on whose behalf req is sent.
+// It returns false if the identity cannot be determined, in which case the
+// request must not use the cache. See CacheConfig.Identity.
+func (c *Client) cacheIdentity(req *http.Request) (string, bool) {
+	if c.cache.Identity != "" {
+		return c.cache.Identity, true
+	}
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		return hashCredentials(auth), true
+	}
+	switch t := c.client.Transport.(type) {
+	case nil:
+		return "", true
+	case *oauth2.Transport:
+		if t.Source == nil {
+			return "", false
+		}
+		// The token that the transport is about to add to the request. A
+		// refreshed token results in a new identity with an empty cache.
+		tok, err := t.Source.Token()
+		if err != nil {
+			return "", false
+		}
+		return hashCredentials(tok.Type() + " " + tok.AccessToken), true
+	}
+	if c.client.Transport == http.DefaultTransport {
+		return "", true
+	}
+	return "", false
+}
+
+// hashCredentials returns an identity derived from credentials, so that they
+// are not stored in the cache keys.
+func hashCredentials(credentials string) string {
+	sum := sha256.Sum256([]byte(credentials))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// generationKey returns the key under which the generation of scope is
+// stored in the Cache for identity.
+func (rc *responseCache) generationKey(scope, identity string) string {
+	return "generation " + scope + " " + identity
+}
+
+// generation returns the current generation of scope, starting a new one if
+// there is none. A generation that was evicted or expired is never reused, so
+// entries keyed with it cannot become reachable again.
+func (rc *responseCache) generation(scope, identity string) string {
+	if g, ok := rc.Cache.Get(rc.generationKey(scope, identity)); ok {
+		return string(g)
+	}
+	return rc.newGeneration(scope, identity)
+}
+
+func (rc *responseCache) newGeneration(scope, identity string) string {
+	g := strconv.FormatInt(time.Now().UnixNano(), 36)
+	rc.Cache.Set(rc.generationKey(scope, identity), []byte(g), rc.generationTTL())
+	return g
+}
+
+// generationTTL returns the longest of the TTLs, so that generations usually
+// outlive the entries keyed with them.
+func (rc *responseCache) generationTTL() time.Duration {
+	ttl := rc.DetailsTTL
+	for _, t := range []time.Duration{rc.ListTTL, rc.MyInfoTTL} {
+		if t > ttl {
+			ttl = t
+		}
+	}
+	return ttl
+}
+
+// key returns the cache key of a request to path which consists of the
+// method, the URL, the identity of the user and the generations of the scopes
+// of path.
+func (rc *responseCache) key(path, identity string, req *http.Request) string {
+	key := req.Method + " " + req.URL.String() + " " + identity
+	for _, scope := range scopes(path) {
+		key += " " + scope + "@" + rc.generation(scope, identity)
+	}
+	return key
+}
+
+// get returns a response built from the body cached under key, if any.
+func (rc *responseCache) get(key string, req *http.Request) (*http.Response, bool) {
+	body, ok := rc.Cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	resp := &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+	return resp, true
+}
+
+// set stores the body of a successful response to a GET request to path under
+// key. The key is computed before the request is sent, so a response that
+// races with an invalidation is stored under the old generation. The body of
+// resp is replaced so that it can still be read by the caller.
+func (rc *responseCache) set(key, path string, resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	rc.Cache.Set(key, body, rc.ttl(path))
+	return nil
+}
+
+// invalidate starts new generations of the scopes that became stale after a
+// successful request that modified the user's list: the lists of that media
+// and the details of that anime or manga.
+func (rc *responseCache) invalidate(path, identity string) {
+	m := myListStatusPath.FindStringSubmatch(path)
+	if m == nil {
+		return
+	}
+	rc.newGeneration(m[1], identity)
+	rc.newGeneration(m[1]+"/"+m[2], identity)
+}
+
+// relativePath returns the path of the request URL relative to the base URL of the client.
+func (c *Client) relativePath(req *http.Request) string {
+	return strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, c.BaseURL.Path), "/")
+}
@@ -0,0 +1,378 @@
+package mal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// recordingCache is a MemoryCache which records the TTLs it was given.
+type recordingCache struct {
+	*MemoryCache
+	ttls map[string]time.Duration
+}
+
+func newRecordingCache() *recordingCache {
+	return &recordingCache{MemoryCache: NewMemoryCache(0), ttls: make(map[string]time.Duration)}
+}
+
+func (c *recordingCache) Set(key string, value []byte, ttl time.Duration) {
+	c.ttls[key] = ttl
+	c.MemoryCache.Set(key, value, ttl)
+}
+
+// setupCache returns a client with a cache and a counter of the requests that
+// reached the server for each path.
+func setupCache(conf CacheConfig) (*Client, *http.ServeMux, map[string]int, func()) {
+	client, mux, teardown := setup()
+	WithCache(conf)(client)
+	hits := make(map[string]int)
+	handle := func(pattern, body string) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			hits[r.Method+" "+r.URL.Path]++
+			fmt.Fprint(w, body)
+		})
+	}
+	handle("/anime/1", `{"id":1}`)
+	handle("/anime/2", `{"id":2}`)
+	handle("/anime", `{"data":[{"node":{"id":1}}]}`)
+	handle("/manga/1", `{"id":1}`)
+	handle("/users/@me", `{"id":1}`)
+	handle("/users/@me/animelist", `{"data":[{"node":{"id":1}}]}`)
+	handle("/users/@me/mangalist", `{"data":[{"node":{"id":1}}]}`)
+	handle("/anime/1/my_list_status", `{"status":"watching"}`)
+	return client, mux, hits, teardown
+}
+
+func TestDoCache(t *testing.T) {
+	client, _, hits, teardown := setupCache(CacheConfig{Cache: NewMemoryCache(10)})
+	defer teardown()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		a, resp, err := client.Anime.Details(ctx, 1, Fields{"title"})
+		if err != nil {
+			t.Fatalf("Anime.Details returned error: %v", err)
+		}
+		if got, want := a, (&Anime{ID: 1}); !reflect.DeepEqual(got, want) {
+			t.Errorf("Anime.Details returned\nhave: %+v\nwant: %+v", got, want)
+		}
+		if got, want := resp.Cached, i > 0; got != want {
+			t.Errorf("Anime.Details call #%d Response.Cached = %v, want %v", i+1, got, want)
+		}
+		// Responses served from the cache were not sent.
+		wantAttempts := 1
+		if i > 0 {
+			wantAttempts = 0
+		}
+		if got := resp.Attempts; got != wantAttempts {
+			t.Errorf("Anime.Details call #%d Response.Attempts = %d, want %d", i+1, got, wantAttempts)
+		}
+	}
+	if got, want := hits["GET /anime/1"], 1; got != want {
+		t.Errorf("server received %d requests, want %d", got, want)
+	}
+
+	// Different query parameters are different keys.
+	if _, _, err := client.Anime.Details(ctx, 1, Fields{"synopsis"}); err != nil {
+		t.Fatalf("Anime.Details returned error: %v", err)
+	}
+	if got, want := hits["GET /anime/1"], 2; got != want {
+		t.Errorf("server received %d requests, want %d", got, want)
+	}
+}
+
+func TestDoCacheTTL(t *testing.T) {
+	cache := newRecordingCache()
+	client, _, _, teardown := setupCache(CacheConfig{
+		Cache:      cache,
+		DetailsTTL: time.Hour,
+		MyInfoTTL:  time.Second,
+	})
+	defer teardown()
+
+	ctx := context.Background()
+	_, _, _ = client.Anime.Details(ctx, 1)
+	_, _, _ = client.User.MyInfo(ctx)
+	_, _, _ = client.User.AnimeList(ctx, "@me")
+
+	var got []time.Duration
+	for _, path := range []string{"anime/1", "users/@me", "users/@me/animelist"} {
+		req, _ := client.NewRequest(http.MethodGet, path)
+		got = append(got, cache.ttls[client.cache.key(path, "", req)])
+	}
+	want := []time.Duration{time.Hour, time.Second, defaultCacheListTTL}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cache TTLs = %v, want %v", got, want)
+	}
+}
+
+func TestDoCacheIdentity(t *testing.T) {
+	cache := NewMemoryCache(10)
+	client, _, hits, teardown := setupCache(CacheConfig{Cache: cache, Identity: "alice"})
+	defer teardown()
+	other := NewClient(nil, WithCache(CacheConfig{Cache: cache, Identity: "bob"}))
+	other.BaseURL = client.BaseURL
+
+	ctx := context.Background()
+	_, _, _ = client.User.MyInfo(ctx)
+	_, resp, err := other.User.MyInfo(ctx)
+	if err != nil {
+		t.Fatalf("User.MyInfo returned error: %v", err)
+	}
+	if resp.Cached {
+		t.Errorf("User.MyInfo of another identity was served from the cache")
+	}
+	if got, want := hits["GET /users/@me"], 2; got != want {
+		t.Errorf("server received %d requests, want %d", got, want)
+	}
+}
+
+func TestDoCacheTokens(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	hits := 0
+	mux.HandleFunc("/users/@me", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		fmt.Fprintf(w, `{"name":%q}`, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	})
+
+	// Clients of different users share a Cache without setting an Identity.
+	ctx := context.Background()
+	cache := NewMemoryCache(0)
+	newClient := func(token string) *Client {
+		httpClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+		c := NewClient(httpClient, WithCache(CacheConfig{Cache: cache}))
+		c.BaseURL = client.BaseURL
+		return c
+	}
+	alice, bob := newClient("alice"), newClient("bob")
+	for i := 0; i < 2; i++ {
+		for _, c := range []struct {
+			client *Client
+			name   string
+		}{{alice, "alice"}, {bob, "bob"}} {
+			u, resp, err := c.client.User.MyInfo(ctx)
+			if err != nil {
+				t.Fatalf("User.MyInfo returned error: %v", err)
+			}
+			if u.Name != c.name {
+				t.Errorf("User.MyInfo with the token of %s returned the user %q", c.name, u.Name)
+			}
+			if got, want := resp.Cached, i > 0; got != want {
+				t.Errorf("User.MyInfo call #%d Response.Cached = %v, want %v", i+1, got, want)
+			}
+		}
+	}
+	if got, want := hits, 2; got != want {
+		t.Errorf("server received %d requests, want %d", got, want)
+	}
+}
+
+type transportFunc func(*http.Request) (*http.Response, error)
+
+func (f transportFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestDoCacheUnknownTransport(t *testing.T) {
+	client, _, hits, teardown := setupCache(CacheConfig{Cache: NewMemoryCache(0)})
+	defer teardown()
+	// A transport that may authenticate the requests in its own way.
+	client.client.Transport = transportFunc(http.DefaultTransport.RoundTrip)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, resp, err := client.User.MyInfo(ctx)
+		if err != nil {
+			t.Fatalf("User.MyInfo returned error: %v", err)
+		}
+		if resp.Cached {
+			t.Errorf("User.MyInfo call #%d was served from the cache", i+1)
+		}
+	}
+	if got, want := hits["GET /users/@me"], 2; got != want {
+		t.Errorf("server received %d requests, want %d", got, want)
+	}
+
+	// An explicit Identity enables the cache.
+	WithCache(CacheConfig{Cache: NewMemoryCache(0), Identity: "alice"})(client)
+	_, _, _ = client.User.MyInfo(ctx)
+	_, resp, _ := client.User.MyInfo(ctx)
+	if !resp.Cached {
+		t.Errorf("User.MyInfo with an Identity was not served from the cache")
+	}
+}
+
+func TestDoCacheErrorsNotCached(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	WithCache(CacheConfig{Cache: NewMemoryCache(10)})(client)
+
+	var hits int
+	mux.HandleFunc("/anime/1", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		http.Error(w, `{"message":"","error":"not_found"}`, http.StatusNotFound)
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.Anime.Details(ctx, 1); err == nil {
+			t.Fatalf("Anime.Details expected error, got no error.")
+		}
+	}
+	if got, want := hits, 2; got != want {
+		t.Errorf("server received %d requests, want %d", got, want)
+	}
+}
+
+func TestDoCacheInvalidation(t *testing.T) {
+	client, _, hits, teardown := setupCache(CacheConfig{Cache: NewMemoryCache(0)})
+	defer teardown()
+
+	ctx := context.Background()
+	fetch := func() {
+		_, _, _ = client.Anime.Details(ctx, 1)
+		_, _, _ = client.Anime.Details(ctx, 2)
+		_, _, _ = client.Anime.List(ctx, "foo")
+		_, _, _ = client.Manga.Details(ctx, 1)
+		_, _, _ = client.User.MyInfo(ctx)
+		_, _, _ = client.User.AnimeList(ctx, "@me")
+		_, _, _ = client.User.MangaList(ctx, "@me")
+	}
+	fetch()
+	if _, _, err := client.Anime.UpdateMyListStatus(ctx, 1, AnimeStatusWatching); err != nil {
+		t.Fatalf("Anime.UpdateMyListStatus returned error: %v", err)
+	}
+	fetch()
+
+	want := map[string]int{
+		"GET /anime/1":                  2,
+		"GET /anime/2":                  1,
+		"GET /anime":                    2,
+		"GET /manga/1":                  1,
+		"GET /users/@me":                2,
+		"GET /users/@me/animelist":      2,
+		"GET /users/@me/mangalist":      1,
+		"PATCH /anime/1/my_list_status": 1,
+	}
+	if got := hits; !reflect.DeepEqual(got, want) {
+		t.Errorf("server received requests\nhave: %v\nwant: %v", got, want)
+	}
+
+	if _, err := client.Anime.DeleteMyListItem(ctx, 1); err != nil {
+		t.Fatalf("Anime.DeleteMyListItem returned error: %v", err)
+	}
+	fetch()
+	if got, want := hits["GET /users/@me/animelist"], 3; got != want {
+		t.Errorf("server received %d user anime list requests, want %d", got, want)
+	}
+}
+
+func TestDoCacheInvalidationShared(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	newDiskCache := func() Cache {
+		cache, err := NewDiskCache(dir)
+		if err != nil {
+			t.Fatalf("NewDiskCache returned error: %v", err)
+		}
+		return cache
+	}
+	memory := NewMemoryCache(0)
+
+	tests := []struct {
+		name  string
+		cache func() Cache
+	}{
+		// Clients that share a cache in the same process.
+		{"shared", func() Cache { return memory }},
+		// Clients of different processes, each with its own DiskCache.
+		{"restart", newDiskCache},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _, hits, teardown := setupCache(CacheConfig{Cache: tt.cache(), Identity: "alice"})
+			defer teardown()
+			newClient := func(identity string) *Client {
+				c := NewClient(nil, WithCache(CacheConfig{Cache: tt.cache(), Identity: identity}))
+				c.BaseURL = client.BaseURL
+				return c
+			}
+
+			ctx := context.Background()
+			_, _, _ = client.User.AnimeList(ctx, "@me")
+			_, _, _ = client.Anime.Details(ctx, 1)
+			// An update of another identity does not invalidate anything.
+			if _, _, err := newClient("bob").Anime.UpdateMyListStatus(ctx, 1, AnimeStatusWatching); err != nil {
+				t.Fatalf("Anime.UpdateMyListStatus returned error: %v", err)
+			}
+			if _, resp, _ := newClient("alice").User.AnimeList(ctx, "@me"); !resp.Cached {
+				t.Errorf("User.AnimeList after an update of another identity was not served from the cache")
+			}
+
+			if _, _, err := newClient("alice").Anime.UpdateMyListStatus(ctx, 1, AnimeStatusWatching); err != nil {
+				t.Fatalf("Anime.UpdateMyListStatus returned error: %v", err)
+			}
+			reader := newClient("alice")
+			_, _, _ = reader.User.AnimeList(ctx, "@me")
+			_, _, _ = reader.Anime.Details(ctx, 1)
+			want := map[string]int{
+				"GET /users/@me/animelist":      2,
+				"GET /anime/1":                  2,
+				"PATCH /anime/1/my_list_status": 2,
+			}
+			if !reflect.DeepEqual(hits, want) {
+				t.Errorf("server received requests\nhave: %v\nwant: %v", hits, want)
+			}
+		})
+	}
+}
+
+func TestDoCacheGenerationEvicted(t *testing.T) {
+	cache := NewMemoryCache(0)
+	client, _, hits, teardown := setupCache(CacheConfig{Cache: cache})
+	defer teardown()
+
+	ctx := context.Background()
+	_, _, _ = client.User.AnimeList(ctx, "@me")
+	if _, _, err := client.Anime.UpdateMyListStatus(ctx, 1, AnimeStatusWatching); err != nil {
+		t.Fatalf("Anime.UpdateMyListStatus returned error: %v", err)
+	}
+	// A generation that is lost is replaced by a new one instead of falling
+	// back to a value that was used before.
+	cache.Delete(client.cache.generationKey("anime", ""))
+	_, resp, _ := client.User.AnimeList(ctx, "@me")
+	if resp.Cached {
+		t.Errorf("User.AnimeList was served from the cache after an update")
+	}
+	if got, want := hits["GET /users/@me/animelist"], 2; got != want {
+		t.Errorf("server received %d requests, want %d", got, want)
+	}
+}
+
+func TestClassifyPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want endpointKind
+	}{
+		{"anime/1", endpointDetails},
+		{"manga/2", endpointDetails},
+		{"forum/boards", endpointDetails},
+		{"forum/topic/3", endpointDetails},
+		{"users/@me", endpointMyInfo},
+		{"users/@me/animelist", endpointList},
+		{"anime/ranking", endpointList},
+		{"anime/season/2022/winter", endpointList},
+		{"forum/topics", endpointList},
+	}
+	for _, tt := range tests {
+		if got := classifyPath(tt.path); got != tt.want {
+			t.Errorf("classifyPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package mal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Date is a calendar date as used by MyAnimeList, which can be partial: a date
+// can have only a year, like 2017, a year and a month, like 2017-10, or be
+// full, like 2017-10-04. Missing parts are zero. The zero Date means that the
+// date is not set.
+//
+// A Date is encoded in JSON as a string in the same format, with the zero Date
+// encoded as an empty string. Decoding is lenient; see UnmarshalText.
+type Date struct {
+	Year  int
+	Month time.Month // Zero if the date only has a year.
+	Day   int        // Zero if the date does not have a day.
+}
+
+// DateOf returns the full date of t in its location. The zero time returns the
+// zero Date.
+func DateOf(t time.Time) Date {
+	if t.IsZero() {
+		return Date{}
+	}
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// ParseDate parses a date in one of the formats 2017, 2017-10 or 2017-10-04.
+// The empty string returns the zero Date.
+func ParseDate(s string) (Date, error) {
+	if s == "" {
+		return Date{}, nil
+	}
+	parts := strings.Split(s, "-")
+	if len(parts) > 3 || len(parts[0]) != 4 {
+		return Date{}, fmt.Errorf("mal: invalid date %q", s)
+	}
+	var nums [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || (i > 0 && len(p) != 2) {
+			return Date{}, fmt.Errorf("mal: invalid date %q", s)
+		}
+		nums[i] = n
+	}
+	d := Date{Year: nums[0], Month: time.Month(nums[1]), Day: nums[2]}
+	if !d.valid() {
+		return Date{}, fmt.Errorf("mal: invalid date %q", s)
+	}
+	return d, nil
+}
+
+// valid reports whether the parts of d are in range and a day is only set
+// together with a month.
+func (d Date) valid() bool {
+	switch {
+	case d.Month < 0 || d.Month > time.December:
+		return false
+	case d.Day == 0:
+		return true
+	case d.Month == 0:
+		return false
+	}
+	return d.Day > 0 && d.Day <= daysIn(d.Month, d.Year)
+}
+
+func daysIn(m time.Month, year int) int {
+	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// IsZero reports whether d is the zero Date, which means the date is not set.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// String returns the date in the format used by MyAnimeList: 2017, 2017-10 or
+// 2017-10-04. The zero Date returns the empty string.
+func (d Date) String() string {
+	switch {
+	case d.IsZero():
+		return ""
+	case d.Month == 0:
+		return fmt.Sprintf("%04d", d.Year)
+	case d.Day == 0:
+		return fmt.Sprintf("%04d-%02d", d.Year, d.Month)
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// Time returns the time at the start of the date in UTC. A missing month or
+// day is taken as the first one, so 2017 returns January 1st, 2017. The zero
+// Date returns the zero time.
+func (d Date) Time() time.Time {
+	if d.IsZero() {
+		return time.Time{}
+	}
+	m, day := d.Month, d.Day
+	if m == 0 {
+		m = time.January
+	}
+	if day == 0 {
+		day = 1
+	}
+	return time.Date(d.Year, m, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Compare returns -1 if d is before d2, 1 if it is after and 0 if they are
+// equal. A partial date is before the full dates it contains, so 2017 is
+// before 2017-01 which is before 2017-01-01.
+func (d Date) Compare(d2 Date) int {
+	switch {
+	case d.Year != d2.Year:
+		return cmpInt(d.Year, d2.Year)
+	case d.Month != d2.Month:
+		return cmpInt(int(d.Month), int(d2.Month))
+	}
+	return cmpInt(d.Day, d2.Day)
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Before reports whether d is before d2. See Compare.
+func (d Date) Before(d2 Date) bool { return d.Compare(d2) < 0 }
+
+// After reports whether d is after d2. See Compare.
+func (d Date) After(d2 Date) bool { return d.Compare(d2) > 0 }
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Date) MarshalText() ([]byte, error) {
+	if !d.valid() {
+		return nil, fmt.Errorf("mal: invalid date %d-%d-%d", d.Year, d.Month, d.Day)
+	}
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Unlike ParseDate it never
+// fails, so that an unexpected date sent by the API does not fail the decoding
+// of the whole response: it keeps the year, month and day as far as they parse
+// and are in range, e.g. 2017-10-4 is 2017-10-04 and 2017-13-01 is 2017, and
+// returns the zero Date if the year does not parse.
+func (d *Date) UnmarshalText(text []byte) error {
+	*d = lenientDate(string(text))
+	return nil
+}
+
+func lenientDate(s string) Date {
+	parts := strings.SplitN(strings.TrimSpace(s), "-", 3)
+	year, err := strconv.Atoi(parts[0])
+	if err != nil || year <= 0 {
+		return Date{}
+	}
+	d := Date{Year: year}
+	if len(parts) < 2 {
+		return d
+	}
+	month, err := strconv.Atoi(parts[1])
+	if err != nil || month < 1 || month > 12 {
+		return d
+	}
+	d.Month = time.Month(month)
+	if len(parts) < 3 {
+		return d
+	}
+	if day, err := strconv.Atoi(parts[2]); err == nil && day >= 1 && day <= daysIn(d.Month, year) {
+		d.Day = day
+	}
+	return d
+}
@@ -0,0 +1,158 @@
+package mal
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Date
+		wantErr bool
+	}{
+		{in: "", want: Date{}},
+		{in: "2017", want: Date{Year: 2017}},
+		{in: "2017-10", want: Date{Year: 2017, Month: time.October}},
+		{in: "2017-10-04", want: Date{Year: 2017, Month: time.October, Day: 4}},
+		{in: "2016-02-29", want: Date{Year: 2016, Month: time.February, Day: 29}},
+		{in: "2017-02-29", wantErr: true},
+		{in: "2017-13", wantErr: true},
+		{in: "2017-1-4", wantErr: true},
+		{in: "17-10-04", wantErr: true},
+		{in: "2017-10-04-01", wantErr: true},
+		{in: "2017-10-04T00:00:00Z", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDate(%q) returned err = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDate(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+		if !tt.wantErr && got.String() != tt.in {
+			t.Errorf("ParseDate(%q).String() = %q", tt.in, got.String())
+		}
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	type dates struct {
+		Start  Date `json:"start_date"`
+		Finish Date `json:"finish_date"`
+		End    Date `json:"end_date"`
+	}
+	const in = `{"start_date":"2017-10","finish_date":"","end_date":null}`
+	var d dates
+	if err := json.Unmarshal([]byte(in), &d); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+	if want := (dates{Start: Date{Year: 2017, Month: time.October}}); d != want {
+		t.Errorf("json.Unmarshal = %#v, want %#v", d, want)
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+	if want := `{"start_date":"2017-10","finish_date":"","end_date":""}`; string(b) != want {
+		t.Errorf("json.Marshal = %s, want %s", b, want)
+	}
+}
+
+func TestDateUnmarshalTextLenient(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Date
+	}{
+		{"2017-10-4", Date{2017, time.October, 4}},
+		{"2017-10-04T00:00:00+09:00", Date{2017, time.October, 0}},
+		{"2017-13-01", Date{Year: 2017}},
+		{"2017-02-30", Date{2017, time.February, 0}},
+		{" 2017 ", Date{Year: 2017}},
+		{"October 2017", Date{}},
+		{"0000-00-00", Date{}},
+	}
+	for _, tt := range tests {
+		var d Date
+		if err := d.UnmarshalText([]byte(tt.in)); err != nil {
+			t.Errorf("UnmarshalText(%q) returned error: %v", tt.in, err)
+		}
+		if d != tt.want {
+			t.Errorf("UnmarshalText(%q) = %#v, want %#v", tt.in, d, tt.want)
+		}
+	}
+
+	// A malformed date does not fail the decoding of the anime.
+	var a Anime
+	if err := json.Unmarshal([]byte(`{"id":1,"title":"Cowboy Bebop","start_date":"2017-10-4","end_date":"soon"}`), &a); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+	want := Anime{ID: 1, Title: "Cowboy Bebop", StartDate: Date{2017, time.October, 4}}
+	if !reflect.DeepEqual(a, want) {
+		t.Errorf("json.Unmarshal = %+v, want %+v", a, want)
+	}
+}
+
+func TestDateTime(t *testing.T) {
+	tests := []struct {
+		in   Date
+		want time.Time
+	}{
+		{Date{}, time.Time{}},
+		{Date{Year: 2017}, time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{Date{Year: 2017, Month: time.October}, time.Date(2017, time.October, 1, 0, 0, 0, 0, time.UTC)},
+		{Date{Year: 2017, Month: time.October, Day: 4}, time.Date(2017, time.October, 4, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := tt.in.Time(); !got.Equal(tt.want) {
+			t.Errorf("%v.Time() = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	jst := time.FixedZone("JST", 9*60*60)
+	if got, want := DateOf(time.Date(2017, 10, 4, 23, 0, 0, 0, jst)), (Date{2017, time.October, 4}); got != want {
+		t.Errorf("DateOf = %v, want %v", got, want)
+	}
+	if got := DateOf(time.Time{}); !got.IsZero() {
+		t.Errorf("DateOf(zero time) = %v, want zero Date", got)
+	}
+}
+
+func TestDateCompare(t *testing.T) {
+	ordered := []Date{
+		{},
+		{Year: 2016, Month: time.December, Day: 31},
+		{Year: 2017},
+		{Year: 2017, Month: time.January},
+		{Year: 2017, Month: time.January, Day: 1},
+		{Year: 2017, Month: time.January, Day: 2},
+		{Year: 2017, Month: time.October},
+	}
+	for i := range ordered {
+		for j := range ordered {
+			want := cmpInt(i, j)
+			if got := ordered[i].Compare(ordered[j]); got != want {
+				t.Errorf("%v.Compare(%v) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+			if got := ordered[i].Before(ordered[j]); got != (want < 0) {
+				t.Errorf("%v.Before(%v) = %v", ordered[i], ordered[j], got)
+			}
+			if got := ordered[i].After(ordered[j]); got != (want > 0) {
+				t.Errorf("%v.After(%v) = %v", ordered[i], ordered[j], got)
+			}
+		}
+	}
+}
+
+func TestDateOptions(t *testing.T) {
+	v := make(url.Values)
+	StartDate{Year: 2017, Month: time.October}.updateMyAnimeListStatusApply(&v)
+	FinishDate{}.updateMyMangaListStatusApply(&v)
+	if got, want := v.Encode(), "finish_date=&start_date=2017-10"; got != want {
+		t.Errorf("date options encoded %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,89 @@
+package mal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// DiskCache is a Cache which stores each entry in a file of a directory so
+// that cached responses survive restarts. Errors while reading or writing the
+// files are treated as cache misses.
+type DiskCache struct {
+	dir string
+
+	// now returns the current time. It can be replaced in tests.
+	now func() time.Time
+}
+
+// NewDiskCache returns a DiskCache which stores its entries in dir. The
+// directory is created if it does not exist.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir, now: time.Now}, nil
+}
+
+// path returns the path of the file of key. Keys are hashed since they contain
+// URLs which are not valid file names.
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get returns the value stored for key if it has not expired. Expired entries
+// are removed.
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	// The first line of the file holds the expiration time in Unix
+	// nanoseconds.
+	i := bytes.IndexByte(data, '\n')
+	if i == -1 {
+		_ = os.Remove(path)
+		return nil, false
+	}
+	expires, err := strconv.ParseInt(string(data[:i]), 10, 64)
+	if err != nil || c.now().UnixNano() >= expires {
+		_ = os.Remove(path)
+		return nil, false
+	}
+	return data[i+1:], true
+}
+
+// Set stores the value for key until ttl passes. The file is written to a
+// temporary file first and then renamed so that concurrent readers never see a
+// partially written entry.
+func (c *DiskCache) Set(key string, value []byte, ttl time.Duration) {
+	f, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	expires := c.now().Add(ttl).UnixNano()
+	_, err = f.WriteString(strconv.FormatInt(expires, 10) + "\n")
+	if err == nil {
+		_, err = f.Write(value)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.path(key))
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+}
+
+// Delete removes the value stored for key.
+func (c *DiskCache) Delete(key string) {
+	_ = os.Remove(c.path(key))
+}
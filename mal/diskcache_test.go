@@ -0,0 +1,70 @@
+package mal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	c, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("NewDiskCache returned error: %v", err)
+	}
+	now := time.Date(2022, 2, 20, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	key := "GET https://api.myanimelist.net/v2/anime/1?fields=title"
+	if _, ok := c.Get(key); ok {
+		t.Errorf("Get on empty cache returned ok")
+	}
+	c.Set(key, []byte(`{"id":1}`), time.Minute)
+	if got, ok := c.Get(key); !ok || string(got) != `{"id":1}` {
+		t.Errorf("Get = %q, %v, want %q, true", got, ok, `{"id":1}`)
+	}
+
+	// A new DiskCache on the same directory sees the stored entries.
+	c2, _ := NewDiskCache(dir)
+	c2.now = c.now
+	if _, ok := c2.Get(key); !ok {
+		t.Errorf("Get on reopened cache returned not ok")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get(key); ok {
+		t.Errorf("Get after expiration returned ok")
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 0 {
+		t.Errorf("expired entry was not removed, directory has %d files", len(files))
+	}
+}
+
+func TestDiskCacheDelete(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskCache returned error: %v", err)
+	}
+	c.Set("foo", []byte("bar"), time.Minute)
+	c.Delete("foo")
+	if _, ok := c.Get("foo"); ok {
+		t.Errorf("Get after Delete returned ok")
+	}
+	// Deleting a missing key is a no-op.
+	c.Delete("foo")
+}
+
+func TestDiskCacheCorruptEntry(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskCache returned error: %v", err)
+	}
+	if err := os.WriteFile(c.path("foo"), []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("foo"); ok {
+		t.Errorf("Get on corrupt entry returned ok")
+	}
+}
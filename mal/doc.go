@@ -28,32 +28,16 @@ for you.
 
 # Accessing publicly available information
 
-To access public information, you need to add the ` X-MAL-CLIENT-ID` header in
-your requests. You can achieve this by creating an `http.Client` with a custom
-transport and use it as shown below:
+To access public information, you need to add the X-MAL-CLIENT-ID header in
+your requests. You can achieve this by using the WithClientID option:
 
-	type clientIDTransport struct {
-		Transport http.RoundTripper
-		ClientID  string
-	}
-
-	func (c *clientIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-		if c.Transport == nil {
-			c.Transport = http.DefaultTransport
-		}
-		req.Header.Add("X-MAL-CLIENT-ID", c.ClientID)
-		return c.Transport.RoundTrip(req)
-	}
+	// Create client ID from https://myanimelist.net/apiconfig.
+	c := mal.NewClient(nil, mal.WithClientID("<Your application client ID>"))
 
-	func main() {
-		publicInfoClient := &http.Client{
-			// Create client ID from https://myanimelist.net/apiconfig.
-			Transport: &clientIDTransport{ClientID: "<Your application client ID>"},
-		}
+The option can also be combined with an OAuth2 http.Client so that the same
+Client can be used for both public and authenticated calls:
 
-		c := mal.NewClient(publicInfoClient)
-		// ...
-	}
+	c := mal.NewClient(oauth2Client, mal.WithClientID("<Your application client ID>"))
 
 # Authenticating using OAuth2
 
@@ -77,15 +61,11 @@ like this:
 	// Create client ID and secret from https://myanimelist.net/apiconfig.
 	//
 	// Secret is currently optional if you choose App Type 'other'.
-	oauth2Conf := &oauth2.Config{
-		ClientID:     "<Enter your registered MyAnimeList.net application client ID>",
-		ClientSecret: "<Enter your registered MyAnimeList.net application client secret>",
-		Endpoint: oauth2.Endpoint{
-		    AuthURL:   "https://myanimelist.net/v1/oauth2/authorize",
-		    TokenURL:  "https://myanimelist.net/v1/oauth2/token",
-		    AuthStyle: oauth2.AuthStyleInParams,
-		},
-	}
+	oauth2Conf := malauth.NewConfig(
+		"<Enter your registered MyAnimeList.net application client ID>",
+		"<Enter your registered MyAnimeList.net application client secret>",
+		"<Enter your registered MyAnimeList.net application redirect URL>",
+	)
 
 	oauth2Client := oauth2Conf.Client(ctx, oauth2Token)
 
@@ -98,6 +78,46 @@ clients should almost never be shared between different users.
 
 Performing the OAuth2 flow involves registering a MAL API application and then
 asking for the user's consent to allow the application to access their data.
+The malauth package (github.com/nstratos/go-myanimelist/malauth) implements the
+flow. For a command line application, malauth.Authenticate opens the
+authorization URL in the browser and captures the authorization code with a
+local server listening on the redirect URL, which must be a loopback URL such
+as http://localhost:8080/callback:
+
+	token, err := malauth.Authenticate(ctx, oauth2Conf, malauth.OpenBrowser)
+	if err != nil {
+		return err
+	}
+	c := mal.NewClient(oauth2Conf.Client(ctx, token))
+
+Web applications that handle the redirect themselves can use
+malauth.NewCodeVerifier, malauth.NewState, malauth.AuthCodeURL and
+malauth.Exchange instead.
+
+Tokens can be persisted with a malauth.TokenStore. The client returned by
+malauth.NewClient saves every refreshed token back to the store the moment it
+is refreshed, so long-running programs never lose a rotated refresh token. The
+file store writes one file per key, only readable by the current user:
+
+	store, err := malauth.NewFileTokenStore("tokens")
+	if err != nil {
+		return err
+	}
+	if err := store.Save(userID, token); err != nil {
+		return err
+	}
+
+	// Later, possibly after a restart.
+	oauth2Client, err := malauth.NewClient(ctx, oauth2Conf, store, userID)
+	if errors.Is(err, malauth.ErrTokenNotFound) {
+		// The user needs to authenticate.
+	}
+	c := mal.NewClient(oauth2Client)
+
+malauth.NewMemoryTokenStore returns an in-memory store and
+malauth.NewEncryptedFileTokenStore a file store which encrypts the tokens
+with AES-GCM. malauth.StoreTokenSource adds the same write-back to any
+oauth2.TokenSource.
 
 There is a detailed example of how to perform the Oauth2 flow and get an oauth2
 token through the terminal under example/malauth. The only thing you need to run
@@ -107,7 +127,8 @@ registering your MAL API application. Here's how:
  1. Navigate to https://myanimelist.net/apiconfig or go to your MyAnimeList
     profile, click Edit Profile and select the API tab on the far right.
 
- 2. Click Create ID and submit the form with your application details.
+ 2. Click Create ID and submit the form with your application details. Use
+    http://localhost:8080/callback as the App Redirect URL.
 
 After registering your application, you can run the example and pass the client
 ID and client secret through flags:
@@ -121,7 +142,7 @@ ID and client secret through flags:
 	malauth --client-id=... --client-secret=...
 
 After you perform a successful authentication once, the oauth2 token will be
-cached in a file under the same directory which makes it easier to run the
+stored in the auth-example-tokens directory which makes it easier to run the
 example multiple times.
 
 Official MAL API OAuth2 docs:
@@ -233,6 +254,25 @@ To get details for a certain anime or manga:
 By default most fields are not populated so use the Fields option to request the
 fields you need.
 
+The fields can also be selected using typed constants which are checked by the
+compiler:
+
+	a, _, err := c.Anime.Details(ctx, 967,
+		mal.AnimeFields(
+			mal.AnimeFieldAlternativeTitles,
+			mal.AnimeFieldNumEpisodes,
+			mal.AnimeMyListStatus(mal.AnimeListStatusFieldComments, mal.AnimeListStatusFieldTags),
+		),
+	)
+	// ...
+
+To get all the fields, use one of the predefined options AnimeAllFields,
+MangaAllFields, UserAllFields, AnimeListStatusAllFields or
+MangaListStatusAllFields:
+
+	a, _, err := c.Anime.Details(ctx, 967, mal.AnimeAllFields)
+	// ...
+
 Official docs:
 
 - https://myanimelist.net/apiconfig/references/api/v2#operation/anime_anime_id_get
@@ -263,6 +303,56 @@ Official docs:
 
 - https://myanimelist.net/apiconfig/references/api/v2#operation/manga_ranking_get
 
+# Pagination
+
+The list methods return a single page of results. Use the Limit and Offset
+options along with Response.NextOffset to request more pages or use the
+corresponding iterator which requests the next pages as needed:
+
+	it := c.User.AnimeListIterator("@me",
+		mal.Fields{"list_status"},
+		mal.Limit(100), // Page size.
+	)
+	it.MaxItems = 1000 // Optional cap on the total number of entries.
+	for it.Next(ctx) {
+		a := it.Value()
+		// ...
+	}
+	if err := it.Err(); err != nil {
+		// ...
+	}
+
+Iterators are available for all the list methods: AnimeService.ListIterator,
+RankingIterator, SeasonalIterator, SuggestedIterator, MangaService.ListIterator,
+RankingIterator, UserService.AnimeListIterator, MangaListIterator and
+ForumService.TopicsIterator. AnimeService.SeasonalRangeIterator iterates over
+the seasonal anime of several seasons.
+
+# Seasons
+
+A Season is a year together with an AnimeSeason. It can be computed from a
+time, parsed from a string like "fall 2023" and moved forwards or backwards:
+
+	current := mal.SeasonOf(time.Now().In(mal.JST))
+	anime, _, err := c.Anime.SeasonalFor(ctx, current.Next())
+	// ...
+	s, err := mal.ParseSeason("fall 2023")
+	// ...
+
+To get the anime of several seasons, SeasonalRangeIterator requests every page
+of each season in the range, one season after the other:
+
+	it := c.Anime.SeasonalRangeIterator(current.Prev(), current.Next(), mal.Limit(100))
+	for it.Next(ctx) {
+		a := it.Value()
+		// ...
+	}
+	if err := it.Err(); err != nil {
+		// ...
+	}
+
+Anime that air for more than one season are returned once for each of them.
+
 # Add or Update List
 
 To add or update an entry in an authenticated user's list, provide the anime or
@@ -273,8 +363,8 @@ manga ID and then options to specify the status, score, comments, tags etc.
 		mal.NumEpisodesWatched(73),
 		mal.Score(8),
 		mal.Comments("You wa shock!"),
-		mal.StartDate(time.Date(2022, 02, 20, 0, 0, 0, 0, time.UTC)),
-		mal.FinishDate(time.Time{}), // Remove an existing date.
+		mal.StartDate{Year: 2022, Month: time.February, Day: 20},
+		mal.FinishDate{}, // Remove an existing date.
 	)
 	// ...
 
@@ -283,11 +373,28 @@ manga ID and then options to specify the status, score, comments, tags etc.
 		mal.NumVolumesRead(1),
 		mal.NumChaptersRead(5),
 		mal.Comments("Migi"),
-		mal.StartDate(time.Date(2022, 02, 20, 0, 0, 0, 0, time.UTC)),
-		mal.FinishDate(time.Time{}), // Remove an existing date.
+		mal.StartDate{Year: 2022, Month: time.February, Day: 20},
+		mal.FinishDate{}, // Remove an existing date.
 	)
 	// ...
 
+To remove the score, tags, comments or dates of an existing entry, use the
+mal.Clear options:
+
+	_, _, err := c.Anime.UpdateMyListStatus(ctx, 967,
+		mal.ClearScore,
+		mal.ClearTags,
+		mal.ClearComments,
+		mal.ClearStartDate,
+		mal.ClearFinishDate,
+	)
+	// ...
+
+Dates are represented by mal.Date which can be partial, like 2017 or 2017-10, as
+MyAnimeList allows. The start and finish dates of list entries and the start
+and end dates of anime and manga use the same type. Use mal.DateOf to convert
+a time.Time and Date.Time to convert back.
+
 Official docs:
 
 - https://myanimelist.net/apiconfig/references/api/v2#operation/anime_anime_id_my_list_status_put
@@ -310,6 +417,133 @@ Official docs:
 
 - https://myanimelist.net/apiconfig/references/api/v2#operation/manga_manga_id_my_list_status_delete
 
+# Broadcast Times
+
+MyAnimeList gives the broadcast schedule of anime in Japan Standard Time. To
+get the next broadcast or the expected air times of all episodes in the time
+zone of the user, request the broadcast, start_date, end_date and num_episodes
+fields:
+
+	a, _, err := c.Anime.Details(ctx, 40028, mal.Fields{"broadcast", "start_date", "end_date", "num_episodes"})
+	// ...
+	loc, _ := time.LoadLocation("Europe/Athens")
+	next := a.Broadcast.Next(time.Now(), loc)
+	episodes := a.EpisodeAirTimes(loc)
+
+The day of the week in the user's time zone can differ from
+Broadcast.DayOfTheWeek, which is the day in Japan.
+
+# Errors
+
+When the API responds with an error, the methods return an *ErrorResponse
+which contains the response along with the message and error sent by the API.
+The error can be matched against sentinel errors such as ErrNotFound,
+ErrUnauthorized, ErrForbidden, ErrRateLimited, ErrInvalidParameters and
+ErrServerError using errors.Is:
+
+	_, err := c.Anime.DeleteMyListItem(ctx, 967)
+	if errors.Is(err, mal.ErrNotFound) {
+		// The anime was not in the list.
+	}
+	if mal.IsTokenExpired(err) {
+		// The oauth2 token needs to be refreshed.
+	}
+
+# Retries
+
+By default, a request that fails is not retried. Use the WithRetryPolicy
+option to retry requests that fail due to transient errors such as connection
+resets, 5xx server errors or 429 Too Many Requests:
+
+	c := mal.NewClient(oauth2Client, mal.WithRetryPolicy(mal.DefaultRetryPolicy))
+
+The client waits between attempts using exponential backoff with jitter and
+honors the Retry-After header sent by the API. Only GET requests are retried
+by default. Set RetryPolicy.RetryPatch to also retry the PATCH requests of
+UpdateMyListStatus. The number of attempts is available in Response.Attempts.
+
+# Rate Limiting
+
+MyAnimeList throttles clients that send too many requests. Use the
+WithRateLimiter option to make every request wait on a rate limiter before it is
+sent. The limiter can be shared between multiple clients:
+
+	// Allow one request every 500ms with bursts of up to 5 requests.
+	limiter := mal.NewTokenBucketLimiter(500*time.Millisecond, 5)
+
+	c1 := mal.NewClient(oauth2Client1, mal.WithRateLimiter(limiter))
+	c2 := mal.NewClient(oauth2Client2, mal.WithRateLimiter(limiter))
+
+Passing zero values to NewTokenBucketLimiter uses the defaults of one request
+per second with bursts of up to 3 requests. Custom limiters can be used by
+implementing the RateLimiter interface.
+
+# Caching
+
+Use the WithCache option to cache the responses of GET requests. Cached
+responses are returned without contacting the API and have Response.Cached set.
+The package provides an in-memory LRU cache and a cache that stores its entries
+on disk:
+
+	cache := mal.NewMemoryCache(1000)
+	// or: cache, err := mal.NewDiskCache("/path/to/cache")
+
+	c := mal.NewClient(oauth2Client, mal.WithCache(mal.CacheConfig{
+		Cache:    cache,
+		Identity: "user1",
+	}))
+
+Details are cached for 24 hours, lists for 5 minutes and MyInfo for 1 minute.
+These can be changed using the TTL fields of CacheConfig. The cache keys
+include the Identity of the user, so that when a Cache is shared between
+clients of different users, a user is never served the responses of another.
+If Identity is not set, it is derived from the access token of the client. HTTP
+clients with a custom transport that are not created by the oauth2 package are
+not cached unless Identity is set. Updating or deleting a list entry
+invalidates the cached user lists and the details of that anime or manga for
+every client with the same Identity, since the invalidation is stored in the
+Cache itself. Responses served from the cache have Response.Cached set and
+Response.Attempts 0.
+
+# Multiple Users
+
+Services that act on behalf of many users can use a malauth.ClientPool instead
+of creating a client for every request. The pool creates the client of a user
+the first time it is requested, using the token stored for the user, and
+evicts clients that have been idle for a while. All clients share the same
+transport, rate limiter and cache, with the cache Identity set to the user ID:
+
+	pool := malauth.NewClientPool(malauth.PoolConfig{
+		Config:      oauth2Conf,
+		Store:       store,
+		RateLimiter: limiter,
+		Cache:       mal.CacheConfig{Cache: mal.NewMemoryCache(1000)},
+	})
+
+	c, err := pool.Client(userID)
+	if errors.Is(err, malauth.ErrTokenNotFound) {
+		// The user needs to authenticate.
+	}
+
+# Testing Your Code
+
+The maltest package provides a fake MyAnimeList API server for testing code
+that uses this package, without reimplementing the JSON responses of the API.
+The server is seeded with data and keeps the state of the user lists:
+
+	srv := maltest.NewServer()
+	defer srv.Close()
+
+	srv.AddAnime(mal.Anime{ID: 1, Title: "Cowboy Bebop", NumEpisodes: 26})
+	srv.AddUser(mal.User{Name: "alice"})
+
+	c := srv.Client("alice")
+	_, _, err := c.Anime.UpdateMyListStatus(ctx, 1, mal.AnimeStatusWatching)
+	// ...
+	status, ok := srv.AnimeListStatus("alice", 1)
+
+Use FailNext to make the server return errors such as 429 Too Many Requests.
+
 # More Examples
 
 See package examples:
@@ -341,6 +575,19 @@ provided. To run all tests including the integration tests:
 
 	go test --client-id='<your app client ID>' --oauth2-token='<your oauth2 token>'
 
+When an oauth2 token is not provided, the integration tests replay the requests
+in testdata/cassettes/integration.json instead, so they also run offline.
+That cassette is synthetic: its responses were written by hand after the API
+documentation, not recorded from the live API, so the offline run checks the
+requests that the package sends but not the shape of the real responses. To
+replace it with a recording of the live API, with the Authorization and
+X-MAL-CLIENT-ID headers redacted:
+
+	go test -run TestIntegration --record --client-id='<your app client ID>' --oauth2-token='<your oauth2 token>'
+
+The recording and replaying is done by maltest.Recorder which can also be used
+to record the requests of your own tests.
+
 # License
 
 MIT
@@ -0,0 +1,269 @@
+package mal
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// The types below hold the enumerated values of the anime and manga fields.
+// They are strings so that values that MyAnimeList adds in the future are
+// preserved when decoding instead of causing an error. Their String method
+// returns a human-readable label for known values and the value itself
+// otherwise.
+
+// unmarshalEnum decodes an enumerated value leniently: null becomes the empty
+// string and a value which is not a JSON string is kept as is.
+func unmarshalEnum(data []byte) string {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return string(data)
+	}
+	return s
+}
+
+// label returns the label of value or value itself if it is unknown.
+func label(labels map[string]string, value string) string {
+	if l, ok := labels[value]; ok {
+		return l
+	}
+	return value
+}
+
+// AnimeMediaType is the media type of an anime.
+type AnimeMediaType string
+
+// The media types of an anime.
+const (
+	AnimeMediaTypeUnknown   AnimeMediaType = "unknown"
+	AnimeMediaTypeTV        AnimeMediaType = "tv"
+	AnimeMediaTypeOVA       AnimeMediaType = "ova"
+	AnimeMediaTypeMovie     AnimeMediaType = "movie"
+	AnimeMediaTypeSpecial   AnimeMediaType = "special"
+	AnimeMediaTypeONA       AnimeMediaType = "ona"
+	AnimeMediaTypeMusic     AnimeMediaType = "music"
+	AnimeMediaTypeTVSpecial AnimeMediaType = "tv_special"
+	AnimeMediaTypeCM        AnimeMediaType = "cm"
+	AnimeMediaTypePV        AnimeMediaType = "pv"
+)
+
+var animeMediaTypeLabels = map[string]string{
+	"unknown":    "Unknown",
+	"tv":         "TV",
+	"ova":        "OVA",
+	"movie":      "Movie",
+	"special":    "Special",
+	"ona":        "ONA",
+	"music":      "Music",
+	"tv_special": "TV Special",
+	"cm":         "CM",
+	"pv":         "PV",
+}
+
+func (t AnimeMediaType) String() string { return label(animeMediaTypeLabels, string(t)) }
+
+// UnmarshalJSON implements json.Unmarshaler, keeping unknown values.
+func (t *AnimeMediaType) UnmarshalJSON(data []byte) error {
+	*t = AnimeMediaType(unmarshalEnum(data))
+	return nil
+}
+
+// AnimeAiringStatus is the airing status of an anime.
+type AnimeAiringStatus string
+
+// The airing statuses of an anime.
+const (
+	AnimeAiringStatusFinished        AnimeAiringStatus = "finished_airing"
+	AnimeAiringStatusCurrentlyAiring AnimeAiringStatus = "currently_airing"
+	AnimeAiringStatusNotYetAired     AnimeAiringStatus = "not_yet_aired"
+)
+
+var animeAiringStatusLabels = map[string]string{
+	"finished_airing":  "Finished Airing",
+	"currently_airing": "Currently Airing",
+	"not_yet_aired":    "Not Yet Aired",
+}
+
+func (s AnimeAiringStatus) String() string { return label(animeAiringStatusLabels, string(s)) }
+
+// UnmarshalJSON implements json.Unmarshaler, keeping unknown values.
+func (s *AnimeAiringStatus) UnmarshalJSON(data []byte) error {
+	*s = AnimeAiringStatus(unmarshalEnum(data))
+	return nil
+}
+
+// AnimeRating is the age rating of an anime.
+type AnimeRating string
+
+// The age ratings of an anime.
+const (
+	AnimeRatingG     AnimeRating = "g"
+	AnimeRatingPG    AnimeRating = "pg"
+	AnimeRatingPG13  AnimeRating = "pg_13"
+	AnimeRatingR     AnimeRating = "r"
+	AnimeRatingRPlus AnimeRating = "r+"
+	AnimeRatingRx    AnimeRating = "rx"
+)
+
+var animeRatingLabels = map[string]string{
+	"g":     "G - All Ages",
+	"pg":    "PG - Children",
+	"pg_13": "PG-13 - Teens 13 and Older",
+	"r":     "R - 17+ (violence & profanity)",
+	"r+":    "R+ - Profanity & Mild Nudity",
+	"rx":    "Rx - Hentai",
+}
+
+func (r AnimeRating) String() string { return label(animeRatingLabels, string(r)) }
+
+// UnmarshalJSON implements json.Unmarshaler, keeping unknown values.
+func (r *AnimeRating) UnmarshalJSON(data []byte) error {
+	*r = AnimeRating(unmarshalEnum(data))
+	return nil
+}
+
+// AnimeSource is the original work that an anime is based on.
+type AnimeSource string
+
+// The sources of an anime.
+const (
+	AnimeSourceOther        AnimeSource = "other"
+	AnimeSourceOriginal     AnimeSource = "original"
+	AnimeSourceManga        AnimeSource = "manga"
+	AnimeSource4KomaManga   AnimeSource = "4_koma_manga"
+	AnimeSourceWebManga     AnimeSource = "web_manga"
+	AnimeSourceDigitalManga AnimeSource = "digital_manga"
+	AnimeSourceNovel        AnimeSource = "novel"
+	AnimeSourceLightNovel   AnimeSource = "light_novel"
+	AnimeSourceVisualNovel  AnimeSource = "visual_novel"
+	AnimeSourceGame         AnimeSource = "game"
+	AnimeSourceCardGame     AnimeSource = "card_game"
+	AnimeSourceBook         AnimeSource = "book"
+	AnimeSourcePictureBook  AnimeSource = "picture_book"
+	AnimeSourceRadio        AnimeSource = "radio"
+	AnimeSourceMusic        AnimeSource = "music"
+	AnimeSourceMixedMedia   AnimeSource = "mixed_media"
+	AnimeSourceWebNovel     AnimeSource = "web_novel"
+)
+
+var animeSourceLabels = map[string]string{
+	"other":         "Other",
+	"original":      "Original",
+	"manga":         "Manga",
+	"4_koma_manga":  "4-koma Manga",
+	"web_manga":     "Web Manga",
+	"digital_manga": "Digital Manga",
+	"novel":         "Novel",
+	"light_novel":   "Light Novel",
+	"visual_novel":  "Visual Novel",
+	"game":          "Game",
+	"card_game":     "Card Game",
+	"book":          "Book",
+	"picture_book":  "Picture Book",
+	"radio":         "Radio",
+	"music":         "Music",
+	"mixed_media":   "Mixed Media",
+	"web_novel":     "Web Novel",
+}
+
+func (s AnimeSource) String() string { return label(animeSourceLabels, string(s)) }
+
+// UnmarshalJSON implements json.Unmarshaler, keeping unknown values.
+func (s *AnimeSource) UnmarshalJSON(data []byte) error {
+	*s = AnimeSource(unmarshalEnum(data))
+	return nil
+}
+
+// NSFWLevel is how suitable an anime or manga is for work.
+type NSFWLevel string
+
+// The NSFW levels of an anime or manga.
+const (
+	// NSFWLevelWhite means the work is safe for work.
+	NSFWLevelWhite NSFWLevel = "white"
+	// NSFWLevelGray means the work may be not safe for work.
+	NSFWLevelGray NSFWLevel = "gray"
+	// NSFWLevelBlack means the work is not safe for work.
+	NSFWLevelBlack NSFWLevel = "black"
+)
+
+var nsfwLevelLabels = map[string]string{
+	"white": "Safe for Work",
+	"gray":  "May Be Not Safe for Work",
+	"black": "Not Safe for Work",
+}
+
+func (n NSFWLevel) String() string { return label(nsfwLevelLabels, string(n)) }
+
+// UnmarshalJSON implements json.Unmarshaler, keeping unknown values.
+func (n *NSFWLevel) UnmarshalJSON(data []byte) error {
+	*n = NSFWLevel(unmarshalEnum(data))
+	return nil
+}
+
+// MangaMediaType is the media type of a manga.
+type MangaMediaType string
+
+// The media types of a manga.
+const (
+	MangaMediaTypeUnknown    MangaMediaType = "unknown"
+	MangaMediaTypeManga      MangaMediaType = "manga"
+	MangaMediaTypeNovel      MangaMediaType = "novel"
+	MangaMediaTypeOneShot    MangaMediaType = "one_shot"
+	MangaMediaTypeDoujinshi  MangaMediaType = "doujinshi"
+	MangaMediaTypeManhwa     MangaMediaType = "manhwa"
+	MangaMediaTypeManhua     MangaMediaType = "manhua"
+	MangaMediaTypeOEL        MangaMediaType = "oel"
+	MangaMediaTypeLightNovel MangaMediaType = "light_novel"
+)
+
+var mangaMediaTypeLabels = map[string]string{
+	"unknown":     "Unknown",
+	"manga":       "Manga",
+	"novel":       "Novel",
+	"one_shot":    "One-shot",
+	"doujinshi":   "Doujinshi",
+	"manhwa":      "Manhwa",
+	"manhua":      "Manhua",
+	"oel":         "OEL",
+	"light_novel": "Light Novel",
+}
+
+func (t MangaMediaType) String() string { return label(mangaMediaTypeLabels, string(t)) }
+
+// UnmarshalJSON implements json.Unmarshaler, keeping unknown values.
+func (t *MangaMediaType) UnmarshalJSON(data []byte) error {
+	*t = MangaMediaType(unmarshalEnum(data))
+	return nil
+}
+
+// MangaPublishingStatus is the publishing status of a manga.
+type MangaPublishingStatus string
+
+// The publishing statuses of a manga.
+const (
+	MangaPublishingStatusFinished            MangaPublishingStatus = "finished"
+	MangaPublishingStatusCurrentlyPublishing MangaPublishingStatus = "currently_publishing"
+	MangaPublishingStatusNotYetPublished     MangaPublishingStatus = "not_yet_published"
+	MangaPublishingStatusOnHiatus            MangaPublishingStatus = "on_hiatus"
+	MangaPublishingStatusDiscontinued        MangaPublishingStatus = "discontinued"
+)
+
+var mangaPublishingStatusLabels = map[string]string{
+	"finished":             "Finished",
+	"currently_publishing": "Publishing",
+	"not_yet_published":    "Not Yet Published",
+	"on_hiatus":            "On Hiatus",
+	"discontinued":         "Discontinued",
+}
+
+func (s MangaPublishingStatus) String() string { return label(mangaPublishingStatusLabels, string(s)) }
+
+// UnmarshalJSON implements json.Unmarshaler, keeping unknown values.
+func (s *MangaPublishingStatus) UnmarshalJSON(data []byte) error {
+	*s = MangaPublishingStatus(unmarshalEnum(data))
+	return nil
+}
@@ -0,0 +1,83 @@
+package mal
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestEnumUnmarshalJSON(t *testing.T) {
+	const in = `{
+		"media_type": "tv",
+		"status": "some_new_status",
+		"rating": null,
+		"source": 4,
+		"nsfw": "gray"
+	}`
+	var a Anime
+	if err := json.Unmarshal([]byte(in), &a); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+	if a.MediaType != AnimeMediaTypeTV {
+		t.Errorf("MediaType = %q, want %q", a.MediaType, AnimeMediaTypeTV)
+	}
+	if a.Status != "some_new_status" {
+		t.Errorf("unknown Status = %q, want it preserved", a.Status)
+	}
+	if a.Rating != "" {
+		t.Errorf("null Rating = %q, want empty", a.Rating)
+	}
+	if a.Source != "4" {
+		t.Errorf("non-string Source = %q, want %q", a.Source, "4")
+	}
+	if a.NSFW != NSFWLevelGray {
+		t.Errorf("NSFW = %q, want %q", a.NSFW, NSFWLevelGray)
+	}
+
+	var m Manga
+	if err := json.Unmarshal([]byte(`{"media_type":"light_novel","status":"on_hiatus","nsfw":"white"}`), &m); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+	if m.MediaType != MangaMediaTypeLightNovel || m.Status != MangaPublishingStatusOnHiatus || m.Nsfw != NSFWLevelWhite {
+		t.Errorf("Manga decoded as %q, %q, %q", m.MediaType, m.Status, m.Nsfw)
+	}
+}
+
+func TestEnumMarshalJSON(t *testing.T) {
+	b, err := json.Marshal(Anime{MediaType: AnimeMediaTypeOVA, Rating: AnimeRatingPG13})
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+	var a Anime
+	if err := json.Unmarshal(b, &a); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+	if a.MediaType != AnimeMediaTypeOVA || a.Rating != AnimeRatingPG13 {
+		t.Errorf("round trip returned %q, %q", a.MediaType, a.Rating)
+	}
+}
+
+func TestEnumString(t *testing.T) {
+	tests := []struct {
+		in   fmt.Stringer
+		want string
+	}{
+		{AnimeMediaTypeTV, "TV"},
+		{AnimeMediaTypeTVSpecial, "TV Special"},
+		{AnimeMediaType("new_type"), "new_type"},
+		{AnimeAiringStatusCurrentlyAiring, "Currently Airing"},
+		{AnimeRatingPG13, "PG-13 - Teens 13 and Older"},
+		{AnimeRatingRPlus, "R+ - Profanity & Mild Nudity"},
+		{AnimeSource4KomaManga, "4-koma Manga"},
+		{AnimeSourceLightNovel, "Light Novel"},
+		{NSFWLevelBlack, "Not Safe for Work"},
+		{MangaMediaTypeOneShot, "One-shot"},
+		{MangaPublishingStatusCurrentlyPublishing, "Publishing"},
+		{MangaPublishingStatus(""), ""},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package mal
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors that classify the errors returned by the MyAnimeList API.
+// An *ErrorResponse matches one of them, based on the HTTP status code and the
+// error field of the response, so they can be checked using errors.Is:
+//
+//	_, _, err := c.Anime.Details(ctx, 1)
+//	if errors.Is(err, mal.ErrNotFound) {
+//		// ...
+//	}
+var (
+	// ErrInvalidParameters is returned when the request has invalid
+	// parameters (400 Bad Request).
+	ErrInvalidParameters = errors.New("invalid parameters")
+	// ErrUnauthorized is returned when the access token is missing, invalid
+	// or expired (401 Unauthorized).
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when access to the resource is not allowed
+	// (403 Forbidden).
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound is returned when the resource does not exist, for example
+	// when deleting an anime that is not in the user's list (404 Not Found).
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimited is returned when too many requests have been sent (429
+	// Too Many Requests).
+	ErrRateLimited = errors.New("rate limited")
+	// ErrServerError is returned when the API fails to handle the request
+	// (5xx).
+	ErrServerError = errors.New("server error")
+)
+
+// classifyError returns the sentinel error that corresponds to the status code
+// and error field of an error response or nil if there is none.
+func classifyError(statusCode int, errField string) error {
+	switch errField {
+	case "invalid_parameters", "bad_request":
+		return ErrInvalidParameters
+	case "invalid_token", "unauthorized":
+		return ErrUnauthorized
+	case "forbidden":
+		return ErrForbidden
+	case "not_found":
+		return ErrNotFound
+	}
+	switch {
+	case statusCode == http.StatusBadRequest:
+		return ErrInvalidParameters
+	case statusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case statusCode == http.StatusForbidden:
+		return ErrForbidden
+	case statusCode == http.StatusNotFound:
+		return ErrNotFound
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case statusCode >= 500:
+		return ErrServerError
+	}
+	return nil
+}
+
+// IsTokenExpired reports whether err was caused by an expired access token. In
+// that case the token needs to be refreshed or the user needs to go through the
+// OAuth2 flow again.
+func IsTokenExpired(err error) bool {
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return false
+	}
+	if errResp.Response.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	if strings.Contains(strings.ToLower(errResp.Message), "expired") {
+		return true
+	}
+	// The reason is usually described in the WWW-Authenticate header, e.g.
+	// Bearer error="invalid_token",error_description="The access token expired"
+	auth := strings.ToLower(errResp.Response.Header.Get("WWW-Authenticate"))
+	return strings.Contains(auth, "invalid_token") && strings.Contains(auth, "expired")
+}
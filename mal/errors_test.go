@@ -0,0 +1,133 @@
+package mal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestErrorResponseIs(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{name: "bad request", status: 400, body: `{"message":"invalid q","error":"bad_request"}`, want: ErrInvalidParameters},
+		{name: "invalid parameters", status: 400, body: `{"message":"","error":"invalid_parameters"}`, want: ErrInvalidParameters},
+		{name: "invalid token", status: 401, body: `{"message":"","error":"invalid_token"}`, want: ErrUnauthorized},
+		{name: "unauthorized without body", status: 401, body: ``, want: ErrUnauthorized},
+		{name: "forbidden", status: 403, body: `{"message":"","error":"forbidden"}`, want: ErrForbidden},
+		{name: "not found", status: 404, body: `{"message":"","error":"not_found"}`, want: ErrNotFound},
+		{name: "not found by error field", status: 400, body: `{"message":"","error":"not_found"}`, want: ErrNotFound},
+		{name: "too many requests", status: 429, body: `{"message":"","error":"too_many_requests"}`, want: ErrRateLimited},
+		{name: "internal", status: 500, body: `{"message":"mal is down","error":"internal"}`, want: ErrServerError},
+		{name: "bad gateway with HTML", status: 502, body: `<html>Bad Gateway</html>`, want: ErrServerError},
+		{name: "unknown", status: 418, body: `{"message":"","error":"teapot"}`, want: nil},
+	}
+	sentinels := []error{ErrInvalidParameters, ErrUnauthorized, ErrForbidden, ErrNotFound, ErrRateLimited, ErrServerError}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mux, teardown := setup()
+			defer teardown()
+
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+
+			req, _ := client.NewRequest(http.MethodGet, ".")
+			_, err := client.Do(context.Background(), req, nil)
+			if err == nil {
+				t.Fatalf("Do expected error, got no error.")
+			}
+			for _, s := range sentinels {
+				if got, want := errors.Is(err, s), s == tt.want; got != want {
+					t.Errorf("errors.Is(err, %q) = %v, want %v", s, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAnimeServiceDeleteMyListItemNotFound(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/anime/1/my_list_status", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodDelete)
+		http.Error(w, `{"message":"","error":"not_found"}`, http.StatusNotFound)
+	})
+
+	_, err := client.Anime.DeleteMyListItem(context.Background(), 1)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Anime.DeleteMyListItem returned err = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestIsTokenExpired(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		header  string
+		body    string
+		expired bool
+	}{
+		{
+			name:    "expired in WWW-Authenticate header",
+			status:  401,
+			header:  `Bearer realm="api",error="invalid_token",error_description="The access token expired"`,
+			body:    `{"error":"invalid_token"}`,
+			expired: true,
+		},
+		{
+			name:    "expired in message",
+			status:  401,
+			body:    `{"message":"token is expired","error":"invalid_token"}`,
+			expired: true,
+		},
+		{
+			name:    "invalid token",
+			status:  401,
+			header:  `Bearer realm="api",error="invalid_token",error_description="The access token is invalid"`,
+			body:    `{"error":"invalid_token"}`,
+			expired: false,
+		},
+		{
+			name:    "not found",
+			status:  404,
+			body:    `{"message":"token expired","error":"not_found"}`,
+			expired: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mux, teardown := setup()
+			defer teardown()
+
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				if tt.header != "" {
+					w.Header().Set("WWW-Authenticate", tt.header)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+
+			req, _ := client.NewRequest(http.MethodGet, ".")
+			_, err := client.Do(context.Background(), req, nil)
+			if got, want := IsTokenExpired(err), tt.expired; got != want {
+				t.Errorf("IsTokenExpired(%v) = %v, want %v", err, got, want)
+			}
+		})
+	}
+}
+
+func TestIsTokenExpiredOtherErrors(t *testing.T) {
+	for _, err := range []error{nil, errors.New("foo"), &ErrorResponse{}} {
+		if IsTokenExpired(err) {
+			t.Errorf("IsTokenExpired(%#v) = true, want false", err)
+		}
+	}
+}
@@ -70,7 +70,7 @@ func ExampleAnimeService_Details() {
 	fmt.Printf("%s\n", a.Title)
 	fmt.Printf("ID: %d\n", a.ID)
 	fmt.Printf("English: %s\n", a.AlternativeTitles.En)
-	fmt.Printf("Type: %s\n", strings.ToUpper(a.MediaType))
+	fmt.Printf("Type: %s\n", a.MediaType)
 	fmt.Printf("Episodes: %d\n", a.NumEpisodes)
 	fmt.Printf("Premiered: %s %d\n", strings.Title(a.StartSeason.Season), a.StartSeason.Year)
 	fmt.Print("Studios: ")
@@ -80,7 +80,7 @@ func ExampleAnimeService_Details() {
 		delim = " "
 	}
 	fmt.Println()
-	fmt.Printf("Source: %s\n", strings.Title(a.Source))
+	fmt.Printf("Source: %s\n", a.Source)
 	fmt.Print("Genres: ")
 	delim = ""
 	for _, g := range a.Genres {
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net/url"
-	"strings"
 
 	"github.com/nstratos/go-myanimelist/mal"
 )
@@ -68,7 +67,7 @@ func ExampleMangaService_Details() {
 	fmt.Printf("%s\n", m.Title)
 	fmt.Printf("ID: %d\n", m.ID)
 	fmt.Printf("English: %s\n", m.AlternativeTitles.En)
-	fmt.Printf("Type: %s\n", strings.Title(m.MediaType))
+	fmt.Printf("Type: %s\n", m.MediaType)
 	fmt.Printf("Volumes: %d\n", m.NumVolumes)
 	fmt.Printf("Chapters: %d\n", m.NumChapters)
 	fmt.Print("Studios: ")
@@ -85,7 +84,7 @@ func ExampleMangaService_Details() {
 		delim = " "
 	}
 	fmt.Println()
-	fmt.Printf("Status: %s\n", strings.Title(m.Status))
+	fmt.Printf("Status: %s\n", m.Status)
 	// Output:
 	// Kiseijuu
 	// ID: 401
@@ -63,3 +63,20 @@ func Example_oAuth2() {
 	}
 	fmt.Printf("ID: %5d, Joined: %v, Username: %s\n", user.ID, user.JoinedAt.Format("Jan 2006"), user.Name)
 }
+
+func Example_clientID() {
+	ctx := context.Background()
+
+	// Create a client ID from https://myanimelist.net/apiconfig. Public
+	// information can be accessed without performing the oauth2 flow.
+	c := mal.NewClient(nil, mal.WithClientID("<Enter your MyAnimeList.net application client ID>"))
+
+	anime, _, err := c.Anime.List(ctx, "hokuto no ken", mal.Limit(3))
+	if err != nil {
+		fmt.Printf("Anime.List error: %v", err)
+		return
+	}
+	for _, a := range anime {
+		fmt.Printf("ID: %5d, %s\n", a.ID, a.Title)
+	}
+}
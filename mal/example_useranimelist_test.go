@@ -56,8 +56,8 @@ func ExampleAnimeService_UpdateMyListStatus() {
 		mal.NumEpisodesWatched(73),
 		mal.Score(8),
 		mal.Comments("You wa shock!"),
-		mal.StartDate(time.Date(2022, 02, 20, 0, 0, 0, 0, time.UTC)),
-		mal.FinishDate(time.Time{}), // Remove an existing date.
+		mal.StartDate{Year: 2022, Month: time.February, Day: 20},
+		mal.FinishDate{}, // Remove an existing date.
 	)
 	if err != nil {
 		fmt.Printf("Anime.UpdateMyListStatus error: %v", err)
@@ -53,8 +53,8 @@ func ExampleMangaService_UpdateMyListStatus() {
 		mal.NumVolumesRead(1),
 		mal.NumChaptersRead(5),
 		mal.Comments("Migi"),
-		mal.StartDate(time.Date(2022, 02, 20, 0, 0, 0, 0, time.UTC)),
-		mal.FinishDate(time.Time{}), // Remove an existing date.
+		mal.StartDate{Year: 2022, Month: time.February, Day: 20},
+		mal.FinishDate{}, // Remove an existing date.
 	)
 	if err != nil {
 		fmt.Printf("Manga.UpdateMyListStatus error: %v", err)
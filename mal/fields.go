@@ -0,0 +1,405 @@
+package mal
+
+import "strings"
+
+// AnimeField is a field of the Anime type that can be requested from the API.
+// Use AnimeFields to turn a list of anime fields into a Fields option. Unlike
+// plain strings, the field constants are checked by the compiler so typos
+// cannot go unnoticed.
+//
+// Example:
+//
+//	mal.AnimeFields(
+//		mal.AnimeFieldSynopsis,
+//		mal.AnimeFieldNumEpisodes,
+//		mal.AnimeMyListStatus(mal.AnimeListStatusFieldPriority, mal.AnimeListStatusFieldComments),
+//	)
+//
+// renders to the same value as:
+//
+//	mal.Fields{"synopsis", "num_episodes", "my_list_status{priority,comments}"}
+type AnimeField string
+
+// The fields of the Anime type.
+const (
+	AnimeFieldID                     AnimeField = "id"
+	AnimeFieldTitle                  AnimeField = "title"
+	AnimeFieldMainPicture            AnimeField = "main_picture"
+	AnimeFieldAlternativeTitles      AnimeField = "alternative_titles"
+	AnimeFieldStartDate              AnimeField = "start_date"
+	AnimeFieldEndDate                AnimeField = "end_date"
+	AnimeFieldSynopsis               AnimeField = "synopsis"
+	AnimeFieldMean                   AnimeField = "mean"
+	AnimeFieldRank                   AnimeField = "rank"
+	AnimeFieldPopularity             AnimeField = "popularity"
+	AnimeFieldNumListUsers           AnimeField = "num_list_users"
+	AnimeFieldNumScoringUsers        AnimeField = "num_scoring_users"
+	AnimeFieldNSFW                   AnimeField = "nsfw"
+	AnimeFieldCreatedAt              AnimeField = "created_at"
+	AnimeFieldUpdatedAt              AnimeField = "updated_at"
+	AnimeFieldMediaType              AnimeField = "media_type"
+	AnimeFieldStatus                 AnimeField = "status"
+	AnimeFieldGenres                 AnimeField = "genres"
+	AnimeFieldMyListStatus           AnimeField = "my_list_status"
+	AnimeFieldNumEpisodes            AnimeField = "num_episodes"
+	AnimeFieldStartSeason            AnimeField = "start_season"
+	AnimeFieldBroadcast              AnimeField = "broadcast"
+	AnimeFieldSource                 AnimeField = "source"
+	AnimeFieldAverageEpisodeDuration AnimeField = "average_episode_duration"
+	AnimeFieldRating                 AnimeField = "rating"
+	AnimeFieldPictures               AnimeField = "pictures"
+	AnimeFieldBackground             AnimeField = "background"
+	AnimeFieldRelatedAnime           AnimeField = "related_anime"
+	AnimeFieldRelatedManga           AnimeField = "related_manga"
+	AnimeFieldRecommendations        AnimeField = "recommendations"
+	AnimeFieldStudios                AnimeField = "studios"
+	AnimeFieldStatistics             AnimeField = "statistics"
+)
+
+// AnimeFields returns a Fields option that requests the provided anime
+// fields. It can be used everywhere the Fields option is accepted.
+func AnimeFields(fields ...AnimeField) Fields {
+	f := make(Fields, len(fields))
+	for i := range fields {
+		f[i] = string(fields[i])
+	}
+	return f
+}
+
+// AnimeMyListStatus returns the my_list_status anime field along with a
+// selection of its subfields, for example my_list_status{priority,comments}.
+func AnimeMyListStatus(fields ...AnimeListStatusField) AnimeField {
+	return AnimeField(nestedField(string(AnimeFieldMyListStatus), animeListStatusFields(fields)))
+}
+
+// AnimeRelatedAnime returns the related_anime anime field along with a
+// selection of the fields of the related anime.
+func AnimeRelatedAnime(fields ...AnimeField) AnimeField {
+	return AnimeField(nestedField(string(AnimeFieldRelatedAnime), AnimeFields(fields...)))
+}
+
+// AnimeRelatedManga returns the related_manga anime field along with a
+// selection of the fields of the related manga.
+func AnimeRelatedManga(fields ...MangaField) AnimeField {
+	return AnimeField(nestedField(string(AnimeFieldRelatedManga), MangaFields(fields...)))
+}
+
+// AnimeRecommendations returns the recommendations anime field along with a
+// selection of the fields of the recommended anime.
+func AnimeRecommendations(fields ...AnimeField) AnimeField {
+	return AnimeField(nestedField(string(AnimeFieldRecommendations), AnimeFields(fields...)))
+}
+
+// UserAnimeListStatus returns the list_status field along with a selection of
+// its subfields. It is meant to be used with UserService.AnimeList to request
+// the status of each anime in the user's list, for example
+// list_status{comments,tags}.
+func UserAnimeListStatus(fields ...AnimeListStatusField) AnimeField {
+	return AnimeField(nestedField("list_status", animeListStatusFields(fields)))
+}
+
+// MangaField is a field of the Manga type that can be requested from the API.
+// Use MangaFields to turn a list of manga fields into a Fields option.
+type MangaField string
+
+// The fields of the Manga type.
+const (
+	MangaFieldID                MangaField = "id"
+	MangaFieldTitle             MangaField = "title"
+	MangaFieldMainPicture       MangaField = "main_picture"
+	MangaFieldAlternativeTitles MangaField = "alternative_titles"
+	MangaFieldStartDate         MangaField = "start_date"
+	MangaFieldSynopsis          MangaField = "synopsis"
+	MangaFieldMean              MangaField = "mean"
+	MangaFieldRank              MangaField = "rank"
+	MangaFieldPopularity        MangaField = "popularity"
+	MangaFieldNumListUsers      MangaField = "num_list_users"
+	MangaFieldNumScoringUsers   MangaField = "num_scoring_users"
+	MangaFieldNSFW              MangaField = "nsfw"
+	MangaFieldCreatedAt         MangaField = "created_at"
+	MangaFieldUpdatedAt         MangaField = "updated_at"
+	MangaFieldMediaType         MangaField = "media_type"
+	MangaFieldStatus            MangaField = "status"
+	MangaFieldGenres            MangaField = "genres"
+	MangaFieldMyListStatus      MangaField = "my_list_status"
+	MangaFieldNumVolumes        MangaField = "num_volumes"
+	MangaFieldNumChapters       MangaField = "num_chapters"
+	MangaFieldAuthors           MangaField = "authors"
+	MangaFieldPictures          MangaField = "pictures"
+	MangaFieldBackground        MangaField = "background"
+	MangaFieldRelatedAnime      MangaField = "related_anime"
+	MangaFieldRelatedManga      MangaField = "related_manga"
+	MangaFieldRecommendations   MangaField = "recommendations"
+	MangaFieldSerialization     MangaField = "serialization"
+)
+
+// MangaFields returns a Fields option that requests the provided manga
+// fields. It can be used everywhere the Fields option is accepted.
+func MangaFields(fields ...MangaField) Fields {
+	f := make(Fields, len(fields))
+	for i := range fields {
+		f[i] = string(fields[i])
+	}
+	return f
+}
+
+// MangaMyListStatus returns the my_list_status manga field along with a
+// selection of its subfields, for example my_list_status{priority,comments}.
+func MangaMyListStatus(fields ...MangaListStatusField) MangaField {
+	return MangaField(nestedField(string(MangaFieldMyListStatus), mangaListStatusFields(fields)))
+}
+
+// MangaRelatedAnime returns the related_anime manga field along with a
+// selection of the fields of the related anime.
+func MangaRelatedAnime(fields ...AnimeField) MangaField {
+	return MangaField(nestedField(string(MangaFieldRelatedAnime), AnimeFields(fields...)))
+}
+
+// MangaRelatedManga returns the related_manga manga field along with a
+// selection of the fields of the related manga.
+func MangaRelatedManga(fields ...MangaField) MangaField {
+	return MangaField(nestedField(string(MangaFieldRelatedManga), MangaFields(fields...)))
+}
+
+// MangaRecommendations returns the recommendations manga field along with a
+// selection of the fields of the recommended manga.
+func MangaRecommendations(fields ...MangaField) MangaField {
+	return MangaField(nestedField(string(MangaFieldRecommendations), MangaFields(fields...)))
+}
+
+// UserMangaListStatus returns the list_status field along with a selection of
+// its subfields. It is meant to be used with UserService.MangaList to request
+// the status of each manga in the user's list, for example
+// list_status{comments,tags}.
+func UserMangaListStatus(fields ...MangaListStatusField) MangaField {
+	return MangaField(nestedField("list_status", mangaListStatusFields(fields)))
+}
+
+// UserField is a field of the User type that can be requested from the API.
+// Use UserFields to turn a list of user fields into a Fields option.
+type UserField string
+
+// The fields of the User type.
+const (
+	UserFieldID              UserField = "id"
+	UserFieldName            UserField = "name"
+	UserFieldPicture         UserField = "picture"
+	UserFieldGender          UserField = "gender"
+	UserFieldBirthday        UserField = "birthday"
+	UserFieldLocation        UserField = "location"
+	UserFieldJoinedAt        UserField = "joined_at"
+	UserFieldAnimeStatistics UserField = "anime_statistics"
+	UserFieldTimeZone        UserField = "time_zone"
+	UserFieldIsSupporter     UserField = "is_supporter"
+)
+
+// UserFields returns a Fields option that requests the provided user fields.
+// It can be used with UserService.MyInfo.
+func UserFields(fields ...UserField) Fields {
+	f := make(Fields, len(fields))
+	for i := range fields {
+		f[i] = string(fields[i])
+	}
+	return f
+}
+
+// AnimeListStatusField is a field of the AnimeListStatus type. It is used as a
+// subfield of my_list_status and list_status. See AnimeMyListStatus and
+// UserAnimeListStatus.
+type AnimeListStatusField string
+
+// The fields of the AnimeListStatus type.
+const (
+	AnimeListStatusFieldStatus             AnimeListStatusField = "status"
+	AnimeListStatusFieldScore              AnimeListStatusField = "score"
+	AnimeListStatusFieldNumEpisodesWatched AnimeListStatusField = "num_episodes_watched"
+	AnimeListStatusFieldIsRewatching       AnimeListStatusField = "is_rewatching"
+	AnimeListStatusFieldUpdatedAt          AnimeListStatusField = "updated_at"
+	AnimeListStatusFieldPriority           AnimeListStatusField = "priority"
+	AnimeListStatusFieldNumTimesRewatched  AnimeListStatusField = "num_times_rewatched"
+	AnimeListStatusFieldRewatchValue       AnimeListStatusField = "rewatch_value"
+	AnimeListStatusFieldTags               AnimeListStatusField = "tags"
+	AnimeListStatusFieldComments           AnimeListStatusField = "comments"
+	AnimeListStatusFieldStartDate          AnimeListStatusField = "start_date"
+	AnimeListStatusFieldFinishDate         AnimeListStatusField = "finish_date"
+)
+
+// MangaListStatusField is a field of the MangaListStatus type. It is used as a
+// subfield of my_list_status and list_status. See MangaMyListStatus and
+// UserMangaListStatus.
+type MangaListStatusField string
+
+// The fields of the MangaListStatus type.
+const (
+	MangaListStatusFieldStatus          MangaListStatusField = "status"
+	MangaListStatusFieldIsRereading     MangaListStatusField = "is_rereading"
+	MangaListStatusFieldNumVolumesRead  MangaListStatusField = "num_volumes_read"
+	MangaListStatusFieldNumChaptersRead MangaListStatusField = "num_chapters_read"
+	MangaListStatusFieldScore           MangaListStatusField = "score"
+	MangaListStatusFieldUpdatedAt       MangaListStatusField = "updated_at"
+	MangaListStatusFieldPriority        MangaListStatusField = "priority"
+	MangaListStatusFieldNumTimesReread  MangaListStatusField = "num_times_reread"
+	MangaListStatusFieldRereadValue     MangaListStatusField = "reread_value"
+	MangaListStatusFieldTags            MangaListStatusField = "tags"
+	MangaListStatusFieldComments        MangaListStatusField = "comments"
+	MangaListStatusFieldStartDate       MangaListStatusField = "start_date"
+	MangaListStatusFieldFinishDate      MangaListStatusField = "finish_date"
+)
+
+// Predefined Fields options that request all the fields of a type. They can be
+// used everywhere the Fields option is accepted, for example:
+//
+//	a, _, err := c.Anime.Details(ctx, 967, mal.AnimeAllFields)
+//
+// Note that only one Fields option takes effect per request, so they cannot be
+// combined with other Fields options.
+var (
+	// AnimeAllFields requests all the fields of the Anime type, including all
+	// the subfields of my_list_status.
+	AnimeAllFields = AnimeFields(
+		AnimeFieldID,
+		AnimeFieldTitle,
+		AnimeFieldMainPicture,
+		AnimeFieldAlternativeTitles,
+		AnimeFieldStartDate,
+		AnimeFieldEndDate,
+		AnimeFieldSynopsis,
+		AnimeFieldMean,
+		AnimeFieldRank,
+		AnimeFieldPopularity,
+		AnimeFieldNumListUsers,
+		AnimeFieldNumScoringUsers,
+		AnimeFieldNSFW,
+		AnimeFieldCreatedAt,
+		AnimeFieldUpdatedAt,
+		AnimeFieldMediaType,
+		AnimeFieldStatus,
+		AnimeFieldGenres,
+		AnimeMyListStatus(animeListStatusAllFields...),
+		AnimeFieldNumEpisodes,
+		AnimeFieldStartSeason,
+		AnimeFieldBroadcast,
+		AnimeFieldSource,
+		AnimeFieldAverageEpisodeDuration,
+		AnimeFieldRating,
+		AnimeFieldPictures,
+		AnimeFieldBackground,
+		AnimeFieldRelatedAnime,
+		AnimeFieldRelatedManga,
+		AnimeFieldRecommendations,
+		AnimeFieldStudios,
+		AnimeFieldStatistics,
+	)
+
+	// MangaAllFields requests all the fields of the Manga type, including all
+	// the subfields of my_list_status.
+	MangaAllFields = MangaFields(
+		MangaFieldID,
+		MangaFieldTitle,
+		MangaFieldMainPicture,
+		MangaFieldAlternativeTitles,
+		MangaFieldStartDate,
+		MangaFieldSynopsis,
+		MangaFieldMean,
+		MangaFieldRank,
+		MangaFieldPopularity,
+		MangaFieldNumListUsers,
+		MangaFieldNumScoringUsers,
+		MangaFieldNSFW,
+		MangaFieldCreatedAt,
+		MangaFieldUpdatedAt,
+		MangaFieldMediaType,
+		MangaFieldStatus,
+		MangaFieldGenres,
+		MangaMyListStatus(mangaListStatusAllFields...),
+		MangaFieldNumVolumes,
+		MangaFieldNumChapters,
+		MangaFieldAuthors,
+		MangaFieldPictures,
+		MangaFieldBackground,
+		MangaFieldRelatedAnime,
+		MangaFieldRelatedManga,
+		MangaFieldRecommendations,
+		MangaFieldSerialization,
+	)
+
+	// UserAllFields requests all the fields of the User type and can be used
+	// with UserService.MyInfo.
+	UserAllFields = UserFields(
+		UserFieldID,
+		UserFieldName,
+		UserFieldPicture,
+		UserFieldGender,
+		UserFieldBirthday,
+		UserFieldLocation,
+		UserFieldJoinedAt,
+		UserFieldAnimeStatistics,
+		UserFieldTimeZone,
+		UserFieldIsSupporter,
+	)
+
+	// AnimeListStatusAllFields requests all the fields of the status of each
+	// anime when used with UserService.AnimeList.
+	AnimeListStatusAllFields = AnimeFields(UserAnimeListStatus(animeListStatusAllFields...))
+
+	// MangaListStatusAllFields requests all the fields of the status of each
+	// manga when used with UserService.MangaList.
+	MangaListStatusAllFields = MangaFields(UserMangaListStatus(mangaListStatusAllFields...))
+)
+
+var animeListStatusAllFields = []AnimeListStatusField{
+	AnimeListStatusFieldStatus,
+	AnimeListStatusFieldScore,
+	AnimeListStatusFieldNumEpisodesWatched,
+	AnimeListStatusFieldIsRewatching,
+	AnimeListStatusFieldUpdatedAt,
+	AnimeListStatusFieldPriority,
+	AnimeListStatusFieldNumTimesRewatched,
+	AnimeListStatusFieldRewatchValue,
+	AnimeListStatusFieldTags,
+	AnimeListStatusFieldComments,
+	AnimeListStatusFieldStartDate,
+	AnimeListStatusFieldFinishDate,
+}
+
+var mangaListStatusAllFields = []MangaListStatusField{
+	MangaListStatusFieldStatus,
+	MangaListStatusFieldIsRereading,
+	MangaListStatusFieldNumVolumesRead,
+	MangaListStatusFieldNumChaptersRead,
+	MangaListStatusFieldScore,
+	MangaListStatusFieldUpdatedAt,
+	MangaListStatusFieldPriority,
+	MangaListStatusFieldNumTimesReread,
+	MangaListStatusFieldRereadValue,
+	MangaListStatusFieldTags,
+	MangaListStatusFieldComments,
+	MangaListStatusFieldStartDate,
+	MangaListStatusFieldFinishDate,
+}
+
+func animeListStatusFields(fields []AnimeListStatusField) Fields {
+	f := make(Fields, len(fields))
+	for i := range fields {
+		f[i] = string(fields[i])
+	}
+	return f
+}
+
+func mangaListStatusFields(fields []MangaListStatusField) Fields {
+	f := make(Fields, len(fields))
+	for i := range fields {
+		f[i] = string(fields[i])
+	}
+	return f
+}
+
+// nestedField renders a field along with a selection of its subfields, for
+// example my_list_status{priority,comments}. If there are no subfields, the
+// name of the field is returned as is.
+func nestedField(name string, subfields Fields) string {
+	if len(subfields) == 0 {
+		return name
+	}
+	return name + "{" + strings.Join(subfields, ",") + "}"
+}
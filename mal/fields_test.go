@@ -0,0 +1,204 @@
+package mal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestFieldBuilders(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Fields
+		want Fields
+	}{
+		{
+			name: "anime fields",
+			in: AnimeFields(
+				AnimeFieldSynopsis,
+				AnimeFieldNumEpisodes,
+				AnimeMyListStatus(AnimeListStatusFieldPriority, AnimeListStatusFieldComments),
+			),
+			want: Fields{"synopsis", "num_episodes", "my_list_status{priority,comments}"},
+		},
+		{
+			name: "anime nested without subfields",
+			in:   AnimeFields(AnimeMyListStatus(), AnimeRelatedAnime()),
+			want: Fields{"my_list_status", "related_anime"},
+		},
+		{
+			name: "anime related",
+			in: AnimeFields(
+				AnimeRelatedAnime(AnimeFieldStartDate, AnimeFieldMediaType),
+				AnimeRelatedManga(MangaFieldNumVolumes),
+				AnimeRecommendations(AnimeFieldMean),
+			),
+			want: Fields{"related_anime{start_date,media_type}", "related_manga{num_volumes}", "recommendations{mean}"},
+		},
+		{
+			name: "user anime list status",
+			in:   AnimeFields(AnimeFieldTitle, UserAnimeListStatus(AnimeListStatusFieldTags, AnimeListStatusFieldFinishDate)),
+			want: Fields{"title", "list_status{tags,finish_date}"},
+		},
+		{
+			name: "manga fields",
+			in: MangaFields(
+				MangaFieldAuthors,
+				MangaMyListStatus(MangaListStatusFieldNumTimesReread, MangaListStatusFieldRereadValue),
+				MangaRelatedAnime(AnimeFieldNumEpisodes),
+				MangaRelatedManga(MangaFieldNumChapters),
+				MangaRecommendations(MangaFieldMean),
+			),
+			want: Fields{"authors", "my_list_status{num_times_reread,reread_value}", "related_anime{num_episodes}", "related_manga{num_chapters}", "recommendations{mean}"},
+		},
+		{
+			name: "user manga list status",
+			in:   MangaFields(UserMangaListStatus(MangaListStatusFieldIsRereading)),
+			want: Fields{"list_status{is_rereading}"},
+		},
+		{
+			name: "user fields",
+			in:   UserFields(UserFieldAnimeStatistics, UserFieldTimeZone),
+			want: Fields{"anime_statistics", "time_zone"},
+		},
+		{
+			name: "empty",
+			in:   AnimeFields(),
+			want: Fields{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := tt.in, tt.want; !reflect.DeepEqual(got, want) {
+				t.Errorf("fields = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestAnimeServiceDetailsAnimeFields(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/anime/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		testURLValues(t, r, urlValues{
+			"fields": "synopsis,my_list_status{priority,comments}",
+		})
+		fmt.Fprint(w, `{"id":1}`)
+	})
+
+	ctx := context.Background()
+	_, _, err := client.Anime.Details(ctx, 1, AnimeFields(
+		AnimeFieldSynopsis,
+		AnimeMyListStatus(AnimeListStatusFieldPriority, AnimeListStatusFieldComments),
+	))
+	if err != nil {
+		t.Errorf("Anime.Details returned error: %v", err)
+	}
+}
+
+func TestUserServiceMyInfoUserFields(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/users/@me", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		testURLValues(t, r, urlValues{
+			"fields": "anime_statistics",
+		})
+		fmt.Fprint(w, `{"id":1}`)
+	})
+
+	ctx := context.Background()
+	_, _, err := client.User.MyInfo(ctx, UserFields(UserFieldAnimeStatistics))
+	if err != nil {
+		t.Errorf("User.MyInfo returned error: %v", err)
+	}
+}
+
+// jsonTags returns the JSON field names of the struct type of v.
+func jsonTags(v interface{}) []string {
+	var tags []string
+	typ := reflect.TypeOf(v)
+	for i := 0; i < typ.NumField(); i++ {
+		tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if tag != "" && tag != "-" {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// fieldNames returns the names of the fields and the names of the subfields
+// of nested fields, keyed by the name of the field they belong to.
+func fieldNames(fields Fields) (names []string, subfields map[string][]string) {
+	subfields = make(map[string][]string)
+	for _, f := range fields {
+		name, sub := f, ""
+		if i := strings.Index(f, "{"); i != -1 {
+			name, sub = f[:i], strings.TrimSuffix(f[i+1:], "}")
+			subfields[name] = strings.Split(sub, ",")
+			sort.Strings(subfields[name])
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, subfields
+}
+
+func TestAllFieldsInSyncWithStructs(t *testing.T) {
+	tests := []struct {
+		name          string
+		fields        Fields
+		typ           interface{}
+		nested        string
+		nestedTyp     interface{}
+		wantAllFields bool
+	}{
+		{name: "AnimeAllFields", fields: AnimeAllFields, typ: Anime{}, nested: "my_list_status", nestedTyp: AnimeListStatus{}, wantAllFields: true},
+		{name: "MangaAllFields", fields: MangaAllFields, typ: Manga{}, nested: "my_list_status", nestedTyp: MangaListStatus{}, wantAllFields: true},
+		{name: "UserAllFields", fields: UserAllFields, typ: User{}, wantAllFields: true},
+		{name: "AnimeListStatusAllFields", fields: AnimeListStatusAllFields, nested: "list_status", nestedTyp: AnimeListStatus{}},
+		{name: "MangaListStatusAllFields", fields: MangaListStatusAllFields, nested: "list_status", nestedTyp: MangaListStatus{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, subfields := fieldNames(tt.fields)
+			if tt.wantAllFields {
+				if got, want := names, jsonTags(tt.typ); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s fields\nhave: %q\nwant: %q", tt.name, got, want)
+				}
+			}
+			if tt.nested != "" {
+				if got, want := subfields[tt.nested], jsonTags(tt.nestedTyp); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s %s subfields\nhave: %q\nwant: %q", tt.name, tt.nested, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestUserServiceAnimeListStatusAllFields(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/users/@me/animelist", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		testURLValues(t, r, urlValues{
+			"fields": "list_status{status,score,num_episodes_watched,is_rewatching,updated_at,priority,num_times_rewatched,rewatch_value,tags,comments,start_date,finish_date}",
+		})
+		fmt.Fprint(w, `{"data":[]}`)
+	})
+
+	ctx := context.Background()
+	_, _, err := client.User.AnimeList(ctx, "@me", AnimeListStatusAllFields)
+	if err != nil {
+		t.Errorf("User.AnimeList returned error: %v", err)
+	}
+}
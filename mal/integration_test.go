@@ -3,12 +3,16 @@ package mal_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
+	"net/http"
+	"os"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/nstratos/go-myanimelist/mal"
+	"github.com/nstratos/go-myanimelist/maltest"
 	"golang.org/x/oauth2"
 )
 
@@ -16,8 +20,17 @@ var (
 	oauth2Token  = flag.String("oauth2-token", "", "MyAnimeList.net oauth2 token to use for integration tests in `JSON` format")
 	clientID     = flag.String("client-id", "", "your registered MyAnimeList.net application client ID")
 	clientSecret = flag.String("client-secret", "", "your registered MyAnimeList.net application client secret; optional if you chose App Type 'other'")
+	record       = flag.Bool("record", false, "record the requests of the integration tests to the cassette used when running them offline")
 )
 
+// integrationCassette holds the requests of the integration tests which are
+// replayed when no oauth2 token is provided. The cassette in the repository is
+// synthetic: its responses were written by hand after the API documentation
+// and not recorded from the live API, so replaying it checks the requests the
+// package sends but not the shape of the real responses. Running with --record
+// replaces it with a real recording.
+const integrationCassette = "testdata/cassettes/integration.json"
+
 func setup(ctx context.Context, t *testing.T) *mal.Client {
 	const tokenFormat = `
 	{
@@ -27,6 +40,14 @@ func setup(ctx context.Context, t *testing.T) *mal.Client {
 		"expiry": "2021-06-01T16:12:56.1319122Z"
 	}`
 	if *oauth2Token == "" || *clientID == "" {
+		rec, err := maltest.NewRecorder(integrationCassette, maltest.Replay, nil)
+		if err == nil {
+			t.Logf("No oauth2 token or client ID provided. Replaying the synthetic requests of %s.", integrationCassette)
+			return mal.NewClient(&http.Client{Transport: rec})
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("loading cassette: %v", err)
+		}
 		t.Log("No oauth2 token or client ID provided.")
 		t.Log("The integration tests are meant to be run with a dedicated test account with empty lists.")
 		t.Log("To run the integration tests use: go test --client-id='<your client ID>' --oauth2-token='<your oauth2 token>'")
@@ -54,7 +75,22 @@ func setup(ctx context.Context, t *testing.T) *mal.Client {
 		},
 	}
 
-	return mal.NewClient(conf.Client(ctx, token))
+	if !*record {
+		return mal.NewClient(conf.Client(ctx, token))
+	}
+	// Only the API requests are recorded. Token refreshes are sent directly
+	// so that no credentials end up in the cassette.
+	rec, err := maltest.NewRecorder(integrationCassette, maltest.Record, nil)
+	if err != nil {
+		t.Fatalf("creating recorder: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := rec.Stop(); err != nil {
+			t.Errorf("saving cassette: %v", err)
+		}
+	})
+	httpClient := &http.Client{Transport: &oauth2.Transport{Source: conf.TokenSource(ctx, token), Base: rec}}
+	return mal.NewClient(httpClient)
 }
 
 func TestIntegration(t *testing.T) {
@@ -127,8 +163,8 @@ func testUpdateUserAnimeList(ctx context.Context, t *testing.T, client *mal.Clie
 			mal.RewatchValue(1),
 			mal.Score(1),
 			mal.Tags{"foo", "bar"},
-			mal.StartDate(time.Date(2022, 02, 20, 0, 0, 0, 0, time.UTC)),
-			mal.FinishDate(time.Time{}),
+			mal.StartDate{Year: 2022, Month: time.February, Day: 20},
+			mal.FinishDate{},
 		); err != nil {
 			t.Fatalf("Anime.UpdateMyListStatus(%d) returned err: %v", id, err)
 		}
@@ -159,8 +195,7 @@ func testUpdateUserAnimeList(ctx context.Context, t *testing.T, client *mal.Clie
 			RewatchValue:       1,
 			Tags:               []string{"foo", "bar"},
 			Comments:           "test comment",
-			StartDate:          "2022-02-20",
-			FinishDate:         "",
+			StartDate:          mal.Date{Year: 2022, Month: time.February, Day: 20},
 		}
 		a.Status.UpdatedAt = time.Time{}
 		if got := a.Status; !reflect.DeepEqual(got, want) {
@@ -205,8 +240,8 @@ func testUpdateUserMangaList(ctx context.Context, t *testing.T, client *mal.Clie
 			mal.RereadValue(1),
 			mal.Score(1),
 			mal.Tags{"foo", "bar"},
-			mal.StartDate(time.Date(2022, 02, 20, 0, 0, 0, 0, time.UTC)),
-			mal.FinishDate(time.Time{}),
+			mal.StartDate{Year: 2022, Month: time.February, Day: 20},
+			mal.FinishDate{},
 		); err != nil {
 			t.Fatalf("Manga.UpdateMyListStatus(%d) returned err: %v", id, err)
 		}
@@ -238,8 +273,7 @@ func testUpdateUserMangaList(ctx context.Context, t *testing.T, client *mal.Clie
 			RereadValue:     1,
 			Tags:            []string{"foo", "bar"},
 			Comments:        "test comment",
-			StartDate:       "2022-02-20",
-			FinishDate:      "",
+			StartDate:       mal.Date{Year: 2022, Month: time.February, Day: 20},
 		}
 		a.Status.UpdatedAt = time.Time{}
 		if got := a.Status; !reflect.DeepEqual(got, want) {
@@ -0,0 +1,314 @@
+package mal
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// pager walks through the pages of a list endpoint and keeps track of the
+// position inside the current page. It is embedded by all the typed iterators
+// which only have to provide a fetch function that stores the page data.
+type pager struct {
+	// MaxItems caps the total number of items the iterator will return. A
+	// value of zero or less means that there is no limit and the iterator will
+	// keep requesting pages until there are no more results.
+	MaxItems int
+
+	// fetch requests the next page, stores its data and reports the number of
+	// items it contains and whether it was the last page.
+	fetch func(ctx context.Context) (n int, resp *Response, last bool, err error)
+
+	resp  *Response
+	err   error
+	last  bool
+	count int // Number of items returned so far.
+	i, n  int // Position in the current page and its length.
+}
+
+// next advances the pager to the next item, fetching a new page if needed. It
+// reports whether there is an item available at index p.i-1 of the current
+// page.
+func (p *pager) next(ctx context.Context) bool {
+	if p.err != nil {
+		return false
+	}
+	if p.MaxItems > 0 && p.count >= p.MaxItems {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+	for p.i >= p.n {
+		if p.last {
+			return false
+		}
+		n, resp, last, err := p.fetch(ctx)
+		if resp != nil {
+			p.resp = resp
+		}
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.i, p.n, p.last = 0, n, last || n == 0
+	}
+	p.i++
+	p.count++
+	return true
+}
+
+// Err returns the first error encountered by the iterator, including context
+// cancellation errors. It should be checked after Next returns false.
+func (p *pager) Err() error { return p.err }
+
+// Response returns the response of the last page that was requested or nil if
+// no page has been requested yet.
+func (p *pager) Response() *Response { return p.resp }
+
+// pageByOffset returns a fetch function which requests successive pages
+// starting from offset and following Response.NextOffset until there are no
+// more pages.
+func pageByOffset(offset int, fetch func(ctx context.Context, offset Offset) (int, *Response, error)) func(context.Context) (int, *Response, bool, error) {
+	return func(ctx context.Context) (int, *Response, bool, error) {
+		n, resp, err := fetch(ctx, Offset(offset))
+		if err != nil {
+			return 0, resp, true, err
+		}
+		last := resp.NextOffset <= offset
+		offset = resp.NextOffset
+		return n, resp, last, nil
+	}
+}
+
+// startOffset returns the offset found in the query values produced by the
+// options passed to an iterator or zero if the Offset option was not used.
+func startOffset(q url.Values) int {
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	return offset
+}
+
+// AnimeIterator iterates over all the anime returned by a list method, such as
+// AnimeService.List, requesting more pages as needed. The page size can be
+// controlled by passing the Limit option to the method that created the
+// iterator and the starting position by passing Offset.
+//
+// Example:
+//
+//	it := c.Anime.ListIterator("hokuto no ken", mal.Limit(100))
+//	it.MaxItems = 500
+//	for it.Next(ctx) {
+//		a := it.Value()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+type AnimeIterator struct {
+	pager
+	page []Anime
+}
+
+// Next advances the iterator to the next anime which will then be available
+// through Value. It returns false when there are no more anime, the MaxItems
+// cap has been reached, ctx is done or an error occurred.
+func (it *AnimeIterator) Next(ctx context.Context) bool { return it.next(ctx) }
+
+// Value returns the current anime. It should only be called after a call to
+// Next has returned true.
+func (it *AnimeIterator) Value() Anime { return it.page[it.i-1] }
+
+// ListIterator returns an iterator over all the anime that match the search
+// term. It accepts the same options as AnimeService.List.
+func (s *AnimeService) ListIterator(search string, options ...Option) *AnimeIterator {
+	options = append(options, optionFromQuery(search))
+	return s.iterator("anime", options...)
+}
+
+// RankingIterator returns an iterator over all the anime of a certain ranking.
+// It accepts the same options as AnimeService.Ranking.
+func (s *AnimeService) RankingIterator(ranking AnimeRanking, options ...Option) *AnimeIterator {
+	options = append(options, optionFromAnimeRanking(ranking))
+	return s.iterator("anime/ranking", options...)
+}
+
+// SuggestedIterator returns an iterator over all the suggested anime for the
+// authorized user. It accepts the same options as AnimeService.Suggested.
+func (s *AnimeService) SuggestedIterator(options ...Option) *AnimeIterator {
+	return s.iterator("anime/suggestions", options...)
+}
+
+// SeasonalIterator returns an iterator over all the anime of a season. It
+// accepts the same options as AnimeService.Seasonal.
+func (s *AnimeService) SeasonalIterator(year int, season AnimeSeason, options ...SeasonalAnimeOption) *AnimeIterator {
+	oo := make([]Option, len(options))
+	for i := range options {
+		oo[i] = optionFromSeasonalAnimeOption(options[i])
+	}
+	return s.iterator(seasonalPath(year, season), oo...)
+}
+
+func (s *AnimeService) iterator(path string, options ...Option) *AnimeIterator {
+	q := url.Values{}
+	for _, o := range options {
+		o.apply(&q)
+	}
+	it := new(AnimeIterator)
+	it.fetch = pageByOffset(startOffset(q), func(ctx context.Context, offset Offset) (int, *Response, error) {
+		page, resp, err := s.list(ctx, path, append(options[:len(options):len(options)], offset)...)
+		it.page = page
+		return len(page), resp, err
+	})
+	return it
+}
+
+// MangaIterator iterates over all the manga returned by a list method, such as
+// MangaService.List, requesting more pages as needed. The page size can be
+// controlled by passing the Limit option to the method that created the
+// iterator and the starting position by passing Offset.
+type MangaIterator struct {
+	pager
+	page []Manga
+}
+
+// Next advances the iterator to the next manga which will then be available
+// through Value. It returns false when there are no more manga, the MaxItems
+// cap has been reached, ctx is done or an error occurred.
+func (it *MangaIterator) Next(ctx context.Context) bool { return it.next(ctx) }
+
+// Value returns the current manga. It should only be called after a call to
+// Next has returned true.
+func (it *MangaIterator) Value() Manga { return it.page[it.i-1] }
+
+// ListIterator returns an iterator over all the manga that match the search
+// term. It accepts the same options as MangaService.List.
+func (s *MangaService) ListIterator(search string, options ...Option) *MangaIterator {
+	options = append(options, optionFromQuery(search))
+	return s.iterator("manga", options...)
+}
+
+// RankingIterator returns an iterator over all the manga of a certain ranking.
+// It accepts the same options as MangaService.Ranking.
+func (s *MangaService) RankingIterator(ranking MangaRanking, options ...Option) *MangaIterator {
+	options = append(options, optionFromMangaRanking(ranking))
+	return s.iterator("manga/ranking", options...)
+}
+
+func (s *MangaService) iterator(path string, options ...Option) *MangaIterator {
+	q := url.Values{}
+	for _, o := range options {
+		o.apply(&q)
+	}
+	it := new(MangaIterator)
+	it.fetch = pageByOffset(startOffset(q), func(ctx context.Context, offset Offset) (int, *Response, error) {
+		page, resp, err := s.list(ctx, path, append(options[:len(options):len(options)], offset)...)
+		it.page = page
+		return len(page), resp, err
+	})
+	return it
+}
+
+// UserAnimeIterator iterates over all the entries of a user's anime list,
+// requesting more pages as needed. The page size can be controlled by passing
+// the Limit option to UserService.AnimeListIterator and the starting position
+// by passing Offset.
+type UserAnimeIterator struct {
+	pager
+	page []UserAnime
+}
+
+// Next advances the iterator to the next entry of the anime list which will
+// then be available through Value. It returns false when there are no more
+// entries, the MaxItems cap has been reached, ctx is done or an error occurred.
+func (it *UserAnimeIterator) Next(ctx context.Context) bool { return it.next(ctx) }
+
+// Value returns the current entry of the anime list. It should only be called
+// after a call to Next has returned true.
+func (it *UserAnimeIterator) Value() UserAnime { return it.page[it.i-1] }
+
+// AnimeListIterator returns an iterator over the whole anime list of the user
+// indicated by username (or use @me). It accepts the same options as
+// UserService.AnimeList.
+func (s *UserService) AnimeListIterator(username string, options ...AnimeListOption) *UserAnimeIterator {
+	q := url.Values{}
+	for _, o := range options {
+		o.animeListApply(&q)
+	}
+	it := new(UserAnimeIterator)
+	it.fetch = pageByOffset(startOffset(q), func(ctx context.Context, offset Offset) (int, *Response, error) {
+		page, resp, err := s.AnimeList(ctx, username, append(options[:len(options):len(options)], offset)...)
+		it.page = page
+		return len(page), resp, err
+	})
+	return it
+}
+
+// UserMangaIterator iterates over all the entries of a user's manga list,
+// requesting more pages as needed. The page size can be controlled by passing
+// the Limit option to UserService.MangaListIterator and the starting position
+// by passing Offset.
+type UserMangaIterator struct {
+	pager
+	page []UserManga
+}
+
+// Next advances the iterator to the next entry of the manga list which will
+// then be available through Value. It returns false when there are no more
+// entries, the MaxItems cap has been reached, ctx is done or an error occurred.
+func (it *UserMangaIterator) Next(ctx context.Context) bool { return it.next(ctx) }
+
+// Value returns the current entry of the manga list. It should only be called
+// after a call to Next has returned true.
+func (it *UserMangaIterator) Value() UserManga { return it.page[it.i-1] }
+
+// MangaListIterator returns an iterator over the whole manga list of the user
+// indicated by username (or use @me). It accepts the same options as
+// UserService.MangaList.
+func (s *UserService) MangaListIterator(username string, options ...MangaListOption) *UserMangaIterator {
+	q := url.Values{}
+	for _, o := range options {
+		o.mangaListApply(&q)
+	}
+	it := new(UserMangaIterator)
+	it.fetch = pageByOffset(startOffset(q), func(ctx context.Context, offset Offset) (int, *Response, error) {
+		page, resp, err := s.MangaList(ctx, username, append(options[:len(options):len(options)], offset)...)
+		it.page = page
+		return len(page), resp, err
+	})
+	return it
+}
+
+// TopicIterator iterates over all the forum topics returned by
+// ForumService.Topics, requesting more pages as needed. The page size can be
+// controlled by passing the Limit option to ForumService.TopicsIterator and
+// the starting position by passing Offset.
+type TopicIterator struct {
+	pager
+	page []Topic
+}
+
+// Next advances the iterator to the next topic which will then be available
+// through Value. It returns false when there are no more topics, the MaxItems
+// cap has been reached, ctx is done or an error occurred.
+func (it *TopicIterator) Next(ctx context.Context) bool { return it.next(ctx) }
+
+// Value returns the current topic. It should only be called after a call to
+// Next has returned true.
+func (it *TopicIterator) Value() Topic { return it.page[it.i-1] }
+
+// TopicsIterator returns an iterator over all the forum topics. It accepts the
+// same options as ForumService.Topics.
+func (s *ForumService) TopicsIterator(options ...TopicsOption) *TopicIterator {
+	q := url.Values{}
+	for _, o := range options {
+		o.topicsApply(&q)
+	}
+	it := new(TopicIterator)
+	it.fetch = pageByOffset(startOffset(q), func(ctx context.Context, offset Offset) (int, *Response, error) {
+		page, resp, err := s.Topics(ctx, append(options[:len(options):len(options)], offset)...)
+		it.page = page
+		return len(page), resp, err
+	})
+	return it
+}
@@ -0,0 +1,290 @@
+package mal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// servePages registers a handler on mux which serves total items with IDs
+// 1..total split in pages according to the limit and offset query values.
+// It returns a pointer to the number of requests served.
+func servePages(t *testing.T, mux *http.ServeMux, pattern string, total int, item func(id int) string) *int {
+	t.Helper()
+	requests := 0
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		requests++
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil {
+			limit = 2
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		data := ""
+		for id := offset + 1; id <= total && id <= offset+limit; id++ {
+			if data != "" {
+				data += ","
+			}
+			data += item(id)
+		}
+		next := ""
+		if offset+limit < total {
+			next = fmt.Sprintf("?offset=%d&limit=%d", offset+limit, limit)
+		}
+		fmt.Fprintf(w, `{"data":[%s],"paging":{"next":%q}}`, data, next)
+	})
+	return &requests
+}
+
+func nodeItem(id int) string { return fmt.Sprintf(`{"node":{"id":%d}}`, id) }
+
+func TestAnimeIterator(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	requests := servePages(t, mux, "/anime", 5, nodeItem)
+
+	ctx := context.Background()
+	it := client.Anime.ListIterator("query", Limit(2))
+	var got []int
+	for it.Next(ctx) {
+		got = append(got, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("AnimeIterator.Err() returned error: %v", err)
+	}
+	if want := []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AnimeIterator returned IDs %v, want %v", got, want)
+	}
+	if got, want := *requests, 3; got != want {
+		t.Errorf("AnimeIterator made %d requests, want %d", got, want)
+	}
+	if it.Response() == nil {
+		t.Error("AnimeIterator.Response() is nil after iteration")
+	}
+}
+
+func TestAnimeIteratorOffset(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	servePages(t, mux, "/anime/ranking", 5, nodeItem)
+
+	ctx := context.Background()
+	it := client.Anime.RankingIterator(AnimeRankingAll, Limit(2), Offset(2))
+	var got []int
+	for it.Next(ctx) {
+		got = append(got, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("AnimeIterator.Err() returned error: %v", err)
+	}
+	if want := []int{3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AnimeIterator returned IDs %v, want %v", got, want)
+	}
+}
+
+func TestAnimeIteratorMaxItems(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	requests := servePages(t, mux, "/anime/suggestions", 10, nodeItem)
+
+	ctx := context.Background()
+	it := client.Anime.SuggestedIterator(Limit(2))
+	it.MaxItems = 3
+	var got []int
+	for it.Next(ctx) {
+		got = append(got, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("AnimeIterator.Err() returned error: %v", err)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AnimeIterator returned IDs %v, want %v", got, want)
+	}
+	if got, want := *requests, 2; got != want {
+		t.Errorf("AnimeIterator made %d requests, want %d", got, want)
+	}
+}
+
+func TestAnimeIteratorContextCanceled(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	servePages(t, mux, "/anime/season/2020/fall", 5, nodeItem)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := client.Anime.SeasonalIterator(2020, AnimeSeasonFall, Limit(2))
+	if !it.Next(ctx) {
+		t.Fatalf("AnimeIterator.Next() returned false, err: %v", it.Err())
+	}
+	cancel()
+	if it.Next(ctx) {
+		t.Error("AnimeIterator.Next() returned true after context was canceled")
+	}
+	if got, want := it.Err(), context.Canceled; got != want {
+		t.Errorf("AnimeIterator.Err() = %v, want %v", got, want)
+	}
+}
+
+func TestAnimeIteratorError(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/anime", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") == "2" {
+			http.Error(w, `{"message":"mal is down","error":"internal"}`, 500)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"node":{"id":1}},{"node":{"id":2}}],"paging":{"next":"?offset=2"}}`)
+	})
+
+	ctx := context.Background()
+	it := client.Anime.ListIterator("query")
+	n := 0
+	for it.Next(ctx) {
+		n++
+	}
+	if got, want := n, 2; got != want {
+		t.Errorf("AnimeIterator returned %d anime before the error, want %d", got, want)
+	}
+	if it.Err() == nil {
+		t.Fatal("AnimeIterator expected internal error, got no error.")
+	}
+	testErrorResponse(t, it.Err(), ErrorResponse{Message: "mal is down", Err: "internal"})
+	testResponseStatusCode(t, it.Response(), http.StatusInternalServerError, "AnimeIterator")
+}
+
+func TestAnimeSeasonalRangeIterator(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	summer := servePages(t, mux, "/anime/season/2022/summer", 3, nodeItem)
+	servePages(t, mux, "/anime/season/2022/fall", 0, nodeItem)
+	winter := servePages(t, mux, "/anime/season/2023/winter", 3, nodeItem)
+
+	ctx := context.Background()
+	it := client.Anime.SeasonalRangeIterator(
+		Season{Year: 2022, Season: AnimeSeasonSummer},
+		Season{Year: 2023, Season: AnimeSeasonWinter},
+		SortSeasonalByAnimeScore, Limit(2), Offset(1),
+	)
+	var got []int
+	for it.Next(ctx) {
+		got = append(got, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("AnimeIterator.Err() returned error: %v", err)
+	}
+	// The offset only applies to the first season and the empty fall season
+	// does not end the iteration.
+	if want := []int{2, 3, 1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AnimeIterator returned IDs %v, want %v", got, want)
+	}
+	if got, want := *summer, 1; got != want {
+		t.Errorf("AnimeIterator made %d requests for summer, want %d", got, want)
+	}
+	if got, want := *winter, 2; got != want {
+		t.Errorf("AnimeIterator made %d requests for winter, want %d", got, want)
+	}
+}
+
+func TestAnimeSeasonalRangeIteratorEmpty(t *testing.T) {
+	client, _, teardown := setup()
+	defer teardown()
+
+	it := client.Anime.SeasonalRangeIterator(
+		Season{Year: 2023, Season: AnimeSeasonWinter},
+		Season{Year: 2022, Season: AnimeSeasonSummer},
+	)
+	if it.Next(context.Background()) {
+		t.Error("AnimeIterator.Next() returned true for an empty season range")
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("AnimeIterator.Err() returned error: %v", err)
+	}
+}
+
+func TestMangaIterator(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	servePages(t, mux, "/manga", 3, nodeItem)
+
+	ctx := context.Background()
+	it := client.Manga.ListIterator("query", Limit(2))
+	var got []int
+	for it.Next(ctx) {
+		got = append(got, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("MangaIterator.Err() returned error: %v", err)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MangaIterator returned IDs %v, want %v", got, want)
+	}
+}
+
+func TestUserAnimeIterator(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	servePages(t, mux, "/users/foo/animelist", 3, nodeItem)
+
+	ctx := context.Background()
+	it := client.User.AnimeListIterator("foo", AnimeStatusWatching, Limit(2))
+	var got []int
+	for it.Next(ctx) {
+		got = append(got, it.Value().Anime.ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("UserAnimeIterator.Err() returned error: %v", err)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UserAnimeIterator returned IDs %v, want %v", got, want)
+	}
+}
+
+func TestUserMangaIterator(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	servePages(t, mux, "/users/foo/mangalist", 3, nodeItem)
+
+	ctx := context.Background()
+	it := client.User.MangaListIterator("foo", MangaStatusReading, Limit(2))
+	var got []int
+	for it.Next(ctx) {
+		got = append(got, it.Value().Manga.ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("UserMangaIterator.Err() returned error: %v", err)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UserMangaIterator returned IDs %v, want %v", got, want)
+	}
+}
+
+func TestTopicIterator(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	servePages(t, mux, "/forum/topics", 3, func(id int) string { return fmt.Sprintf(`{"id":%d}`, id) })
+
+	ctx := context.Background()
+	it := client.Forum.TopicsIterator(Query("foo"), Limit(2))
+	var got []int
+	for it.Next(ctx) {
+		got = append(got, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("TopicIterator.Err() returned error: %v", err)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TopicIterator returned IDs %v, want %v", got, want)
+	}
+}
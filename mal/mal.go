@@ -11,16 +11,35 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	defaultBaseURL = "https://api.myanimelist.net/v2/"
+
+	headerClientID = "X-MAL-CLIENT-ID"
 )
 
 // Client manages communication with the MyAnimeList API.
 type Client struct {
 	client *http.Client
 
+	// clientID is sent with every request in the X-MAL-CLIENT-ID header if it
+	// is not empty.
+	clientID string
+
+	// retry controls if and how failed requests are retried.
+	retry RetryPolicy
+
+	// limiter, if set, is waited on before sending each request.
+	limiter RateLimiter
+
+	// cache, if set, stores the responses of GET requests.
+	cache *responseCache
+
+	// sleep waits between retries. It can be replaced in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+
 	// Base URL for MyAnimeList API requests.
 	BaseURL *url.URL
 
@@ -38,7 +57,11 @@ type Client struct {
 // perform the authentication for you. Such a client is provided by the
 // golang.org/x/oauth2 package. Check out the example directory of the project
 // for a full authentication example.
-func NewClient(httpClient *http.Client) *Client {
+//
+// To access publicly available information without OAuth2, use the
+// WithClientID option. It can also be combined with an OAuth2 http.Client so
+// that both public and authenticated calls go through the same Client.
+func NewClient(httpClient *http.Client, options ...ClientOption) *Client {
 	if httpClient == nil {
 		httpClient = &http.Client{}
 	}
@@ -48,6 +71,10 @@ func NewClient(httpClient *http.Client) *Client {
 	c := &Client{
 		client:  httpClient,
 		BaseURL: baseURL,
+		sleep:   sleep,
+	}
+	for _, o := range options {
+		o(c)
 	}
 
 	c.User = &UserService{client: c}
@@ -58,6 +85,20 @@ func NewClient(httpClient *http.Client) *Client {
 	return c
 }
 
+// ClientOption is an option that configures a Client when passed to
+// NewClient.
+type ClientOption func(c *Client)
+
+// WithClientID is a client option which adds the X-MAL-CLIENT-ID header with
+// the provided client ID to every request. This allows accessing publicly
+// available information without performing the OAuth2 flow. Create a client ID
+// from https://myanimelist.net/apiconfig.
+func WithClientID(clientID string) ClientOption {
+	return func(c *Client) {
+		c.clientID = clientID
+	}
+}
+
 // Response wraps http.Response and is returned in all the library functions
 // that communicate with the MyAnimeList API. Even if an error occurs the
 // response will always be returned along with the actual error so that the
@@ -70,6 +111,15 @@ type Response struct {
 
 	NextOffset int
 	PrevOffset int
+
+	// Attempts is the number of times the request was sent. It is greater than
+	// 1 only if the request was retried according to the RetryPolicy of the
+	// Client and 0 if the response was served from the cache.
+	Attempts int
+
+	// Cached reports whether the response was served from the cache of the
+	// Client instead of the API. See WithCache.
+	Cached bool
 }
 
 // NewRequest creates an API request. A relative URL can be provided in urlStr,
@@ -104,6 +154,9 @@ func (c *Client) NewRequest(method, urlStr string, urlOptions ...func(v *url.Val
 	if len(urlOptions) != 0 {
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	}
+	if c.clientID != "" {
+		req.Header.Set(headerClientID, c.clientID)
+	}
 	return req, nil
 }
 
@@ -112,6 +165,12 @@ func (c *Client) NewRequest(method, urlStr string, urlOptions ...func(v *url.Val
 // io.Writer interface, the raw response body will be written to v, without
 // attempting to first decode it.
 //
+// If the Client was created with the WithRetryPolicy option, requests that
+// fail due to transient errors are retried according to the policy. If it was
+// created with the WithRateLimiter option, every attempt waits on the rate
+// limiter first. If it was created with the WithCache option, responses to GET
+// requests are served from and stored in the cache.
+//
 // If the provided ctx is nil then an error will be returned.
 func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
 	if ctx == nil {
@@ -119,19 +178,79 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 	}
 	req = req.WithContext(ctx)
 
-	dumpRequest(req)
-	resp, err := c.client.Do(req)
+	var (
+		path, key, identity string
+		useCache            bool
+	)
+	if c.cache != nil {
+		path = c.relativePath(req)
+		identity, useCache = c.cacheIdentity(req)
+	}
+	cacheable := useCache && req.Method == http.MethodGet
+	if cacheable {
+		key = c.cache.key(path, identity, req)
+		if resp, ok := c.cache.get(key, req); ok {
+			return decodeResponse(&Response{Response: resp, Cached: true}, v)
+		}
+	}
+
+	var (
+		resp     *http.Response
+		err      error
+		attempts int
+	)
+	for {
+		attempts++
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		dumpRequest(req)
+		resp, err = c.client.Do(req)
+		if !c.retry.shouldRetry(req, resp, err, attempts) {
+			break
+		}
+		wait := c.retry.backoff(attempts, resp)
+		drainBody(resp)
+		if err := c.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		if req, err = rewindRequest(req); err != nil {
+			return nil, err
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 	dumpResponse(resp)
 
-	response := &Response{Response: resp}
+	response := &Response{Response: resp, Attempts: attempts}
 	if err := checkResponse(resp); err != nil {
+		resp.Body.Close()
 		return response, err
 	}
 
+	if useCache {
+		if cacheable {
+			if err := c.cache.set(key, path, resp); err != nil {
+				return response, err
+			}
+		} else {
+			c.cache.invalidate(path, identity)
+		}
+	}
+
+	return decodeResponse(response, v)
+}
+
+// decodeResponse decodes the body of a successful response into v and closes
+// it.
+func decodeResponse(response *Response, v interface{}) (*Response, error) {
+	resp := response.Response
+	defer resp.Body.Close()
+
+	var err error
 	if v != nil {
 		decErr := json.NewDecoder(resp.Body).Decode(v)
 		if decErr == io.EOF {
@@ -145,13 +264,18 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 	return response, err
 }
 
-// An ErrorResponse reports an error caused by an API request.
+// An ErrorResponse reports an error caused by an API request. It can be
+// matched against the sentinel errors of this package, such as ErrNotFound,
+// using errors.Is.
 //
 // https://myanimelist.net/apiconfig/references/api/v2#section/Common-formats
 type ErrorResponse struct {
 	Response *http.Response // HTTP response that caused this error
 	Message  string         `json:"message"`
 	Err      string         `json:"error"`
+
+	// kind is the sentinel error that classifies this error.
+	kind error
 }
 
 func (r *ErrorResponse) Error() string {
@@ -160,6 +284,10 @@ func (r *ErrorResponse) Error() string {
 		r.Response.StatusCode, r.Message, r.Err)
 }
 
+// Unwrap returns the sentinel error that classifies the error response, for
+// example ErrNotFound, or nil if the error could not be classified.
+func (r *ErrorResponse) Unwrap() error { return r.kind }
+
 func checkResponse(r *http.Response) error {
 	if c := r.StatusCode; 200 <= c && c <= 299 {
 		return nil
@@ -172,6 +300,7 @@ func checkResponse(r *http.Response) error {
 	}
 	// Re-populate error response body in case JSON unmarshal fails.
 	r.Body = io.NopCloser(bytes.NewBuffer(data))
+	errorResponse.kind = classifyError(r.StatusCode, errorResponse.Err)
 
 	return errorResponse
 }
@@ -181,6 +181,63 @@ func TestNewRequestBadEndpoint(t *testing.T) {
 	}
 }
 
+func TestNewRequestClientID(t *testing.T) {
+	c := NewClient(nil, WithClientID("foo"))
+
+	req, err := c.NewRequest("GET", "bar")
+	if err != nil {
+		t.Fatalf("NewRequest returned error: %v", err)
+	}
+	if got, want := req.Header.Get("X-MAL-CLIENT-ID"), "foo"; got != want {
+		t.Errorf("NewRequest() X-MAL-CLIENT-ID header = %q, want %q", got, want)
+	}
+}
+
+func TestNewRequestNoClientID(t *testing.T) {
+	c := NewClient(nil)
+
+	req, err := c.NewRequest("GET", "bar")
+	if err != nil {
+		t.Fatalf("NewRequest returned error: %v", err)
+	}
+	if _, ok := req.Header["X-Mal-Client-Id"]; ok {
+		t.Errorf("NewRequest() without client ID set X-MAL-CLIENT-ID header to %q", req.Header.Get("X-MAL-CLIENT-ID"))
+	}
+}
+
+type authTransport struct {
+	token string
+}
+
+func (a authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestDoClientIDWithAuthenticatedClient(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("X-MAL-CLIENT-ID"), "foo"; got != want {
+			t.Errorf("X-MAL-CLIENT-ID header = %q, want %q", got, want)
+		}
+		if got, want := r.Header.Get("Authorization"), "Bearer bar"; got != want {
+			t.Errorf("Authorization header = %q, want %q", got, want)
+		}
+	})
+
+	c := NewClient(&http.Client{Transport: authTransport{token: "bar"}}, WithClientID("foo"))
+	c.BaseURL = client.BaseURL
+
+	req, _ := c.NewRequest("GET", ".")
+	ctx := context.Background()
+	if _, err := c.Do(ctx, req, nil); err != nil {
+		t.Fatalf("Do returned unexpected error: %v", err)
+	}
+}
+
 func TestDo(t *testing.T) {
 	client, mux, teardown := setup()
 	defer teardown()
@@ -18,33 +18,33 @@ type MangaService struct {
 
 // Manga represents a MyAnimeList manga.
 type Manga struct {
-	ID                int                `json:"id"`
-	Title             string             `json:"title"`
-	MainPicture       Picture            `json:"main_picture"`
-	AlternativeTitles Titles             `json:"alternative_titles"`
-	StartDate         string             `json:"start_date"`
-	Synopsis          string             `json:"synopsis"`
-	Mean              float64            `json:"mean"`
-	Rank              int                `json:"rank"`
-	Popularity        int                `json:"popularity"`
-	NumListUsers      int                `json:"num_list_users"`
-	NumScoringUsers   int                `json:"num_scoring_users"`
-	Nsfw              string             `json:"nsfw"`
-	CreatedAt         time.Time          `json:"created_at"`
-	UpdatedAt         time.Time          `json:"updated_at"`
-	MediaType         string             `json:"media_type"`
-	Status            string             `json:"status"`
-	Genres            []Genre            `json:"genres"`
-	MyListStatus      MangaListStatus    `json:"my_list_status"`
-	NumVolumes        int                `json:"num_volumes"`
-	NumChapters       int                `json:"num_chapters"`
-	Authors           []Author           `json:"authors"`
-	Pictures          []Picture          `json:"pictures"`
-	Background        string             `json:"background"`
-	RelatedAnime      []RelatedAnime     `json:"related_anime"`
-	RelatedManga      []RelatedManga     `json:"related_manga"`
-	Recommendations   []RecommendedManga `json:"recommendations"`
-	Serialization     []Serialization    `json:"serialization"`
+	ID                int                   `json:"id"`
+	Title             string                `json:"title"`
+	MainPicture       Picture               `json:"main_picture"`
+	AlternativeTitles Titles                `json:"alternative_titles"`
+	StartDate         Date                  `json:"start_date"`
+	Synopsis          string                `json:"synopsis"`
+	Mean              float64               `json:"mean"`
+	Rank              int                   `json:"rank"`
+	Popularity        int                   `json:"popularity"`
+	NumListUsers      int                   `json:"num_list_users"`
+	NumScoringUsers   int                   `json:"num_scoring_users"`
+	Nsfw              NSFWLevel             `json:"nsfw"`
+	CreatedAt         time.Time             `json:"created_at"`
+	UpdatedAt         time.Time             `json:"updated_at"`
+	MediaType         MangaMediaType        `json:"media_type"`
+	Status            MangaPublishingStatus `json:"status"`
+	Genres            []Genre               `json:"genres"`
+	MyListStatus      MangaListStatus       `json:"my_list_status"`
+	NumVolumes        int                   `json:"num_volumes"`
+	NumChapters       int                   `json:"num_chapters"`
+	Authors           []Author              `json:"authors"`
+	Pictures          []Picture             `json:"pictures"`
+	Background        string                `json:"background"`
+	RelatedAnime      []RelatedAnime        `json:"related_anime"`
+	RelatedManga      []RelatedManga        `json:"related_manga"`
+	Recommendations   []RecommendedManga    `json:"recommendations"`
+	Serialization     []Serialization       `json:"serialization"`
 }
 
 // Person is usually the creator of a manga.
@@ -0,0 +1,72 @@
+package mal
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestMemoryCache(maxEntries int) (*MemoryCache, *time.Time) {
+	c := NewMemoryCache(maxEntries)
+	now := time.Date(2022, 2, 20, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestMemoryCacheGetSet(t *testing.T) {
+	c, _ := newTestMemoryCache(0)
+
+	if _, ok := c.Get("foo"); ok {
+		t.Errorf("Get on empty cache returned ok")
+	}
+	c.Set("foo", []byte("bar"), time.Minute)
+	if got, ok := c.Get("foo"); !ok || string(got) != "bar" {
+		t.Errorf("Get(%q) = %q, %v, want %q, true", "foo", got, ok, "bar")
+	}
+	c.Set("foo", []byte("baz"), time.Minute)
+	if got, ok := c.Get("foo"); !ok || string(got) != "baz" {
+		t.Errorf("Get(%q) after overwrite = %q, %v, want %q, true", "foo", got, ok, "baz")
+	}
+	c.Delete("foo")
+	if _, ok := c.Get("foo"); ok {
+		t.Errorf("Get after Delete returned ok")
+	}
+}
+
+func TestMemoryCacheExpiration(t *testing.T) {
+	c, now := newTestMemoryCache(0)
+
+	c.Set("foo", []byte("bar"), time.Minute)
+	*now = now.Add(59 * time.Second)
+	if _, ok := c.Get("foo"); !ok {
+		t.Errorf("Get before expiration returned not ok")
+	}
+	*now = now.Add(time.Second)
+	if _, ok := c.Get("foo"); ok {
+		t.Errorf("Get after expiration returned ok")
+	}
+	if got, want := c.Len(), 0; got != want {
+		t.Errorf("Len = %d, want %d", got, want)
+	}
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	c, _ := newTestMemoryCache(2)
+
+	c.Set("a", []byte("1"), time.Minute)
+	c.Set("b", []byte("2"), time.Minute)
+	// Using "a" makes "b" the least recently used entry.
+	c.Get("a")
+	c.Set("c", []byte("3"), time.Minute)
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("least recently used entry was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("Get(%q) returned not ok", key)
+		}
+	}
+	if got, want := c.Len(), 2; got != want {
+		t.Errorf("Len = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,112 @@
+package mal
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRateLimitEvery = 1 * time.Second
+	defaultRateLimitBurst = 3
+)
+
+// RateLimiter limits the rate of the requests sent by a Client. Before every
+// request, including retries, the Client calls Wait which should block until
+// the request is allowed to proceed or ctx is done.
+//
+// Since the Client is safe for concurrent use, implementations must also be
+// safe for concurrent use.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// WithRateLimiter is a client option which makes all requests sent through
+// the Client wait on the provided rate limiter. The same limiter can be shared
+// between multiple Clients so that all of them respect a common rate, for
+// example when they act on behalf of different users of the same application.
+func WithRateLimiter(l RateLimiter) ClientOption {
+	return func(c *Client) {
+		c.limiter = l
+	}
+}
+
+// TokenBucketLimiter is a RateLimiter that implements the token bucket
+// algorithm. The bucket starts full with burst tokens and one token is added
+// every time interval. Each request consumes a token and waits if there are
+// none left.
+type TokenBucketLimiter struct {
+	every time.Duration
+	burst int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	// now and sleep can be replaced in tests.
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewTokenBucketLimiter returns a rate limiter which allows one request every
+// time interval with bursts of up to burst requests. If every or burst is zero
+// or negative, the defaults of one request per second with bursts of 3
+// requests are used instead.
+func NewTokenBucketLimiter(every time.Duration, burst int) *TokenBucketLimiter {
+	if every <= 0 {
+		every = defaultRateLimitEvery
+	}
+	if burst <= 0 {
+		burst = defaultRateLimitBurst
+	}
+	return &TokenBucketLimiter{
+		every:  every,
+		burst:  burst,
+		tokens: float64(burst),
+		now:    time.Now,
+		sleep:  sleep,
+	}
+}
+
+// Wait blocks until a token is available or ctx is done, in which case the
+// context error is returned.
+func (l *TokenBucketLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.refill(l.now())
+	// Reserve a token even if there are none left. The deficit is the time
+	// the caller needs to wait for its token to be added to the bucket.
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens * float64(l.every))
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	if err := l.sleep(ctx, wait); err != nil {
+		// Give back the reserved token as it was not used.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// refill adds the tokens accumulated since the last refill without exceeding
+// the size of the bucket.
+func (l *TokenBucketLimiter) refill(now time.Time) {
+	if !l.last.IsZero() {
+		elapsed := now.Sub(l.last)
+		l.tokens += float64(elapsed) / float64(l.every)
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+}
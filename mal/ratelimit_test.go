@@ -0,0 +1,166 @@
+package mal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestTokenBucketLimiter returns a limiter which uses a fake clock that is
+// advanced by sleeping. The waits are recorded in the returned slice.
+func newTestTokenBucketLimiter(every time.Duration, burst int) (*TokenBucketLimiter, *[]time.Duration) {
+	l := NewTokenBucketLimiter(every, burst)
+	now := time.Date(2022, 2, 20, 0, 0, 0, 0, time.UTC)
+	waits := new([]time.Duration)
+	l.now = func() time.Time { return now }
+	l.sleep = func(ctx context.Context, d time.Duration) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		*waits = append(*waits, d)
+		now = now.Add(d)
+		return nil
+	}
+	return l, waits
+}
+
+func TestTokenBucketLimiterWait(t *testing.T) {
+	l, waits := newTestTokenBucketLimiter(time.Second, 2)
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("Wait #%d returned error: %v", i+1, err)
+		}
+	}
+	// The first 2 requests use the burst, the rest wait for a token each.
+	want := []time.Duration{time.Second, time.Second}
+	if got := *waits; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenBucketLimiter waited %v, want %v", got, want)
+	}
+}
+
+func TestTokenBucketLimiterRefill(t *testing.T) {
+	l, waits := newTestTokenBucketLimiter(time.Second, 2)
+
+	ctx := context.Background()
+	_ = l.Wait(ctx)
+	_ = l.Wait(ctx)
+	// Advance the clock enough for more tokens than the burst.
+	_ = l.sleep(ctx, 10*time.Second)
+	*waits = nil
+	for i := 0; i < 3; i++ {
+		_ = l.Wait(ctx)
+	}
+	// The bucket cannot hold more than 2 tokens so the third request waits.
+	want := []time.Duration{time.Second}
+	if got := *waits; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenBucketLimiter waited %v, want %v", got, want)
+	}
+}
+
+func TestTokenBucketLimiterContextCanceled(t *testing.T) {
+	l, _ := newTestTokenBucketLimiter(time.Second, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("Wait returned error: %v", err)
+	}
+	cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait returned err = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestTokenBucketLimiterConcurrent(t *testing.T) {
+	l := NewTokenBucketLimiter(time.Millisecond, 5)
+
+	ctx := context.Background()
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 15; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.Wait(ctx); err != nil {
+				t.Errorf("Wait returned error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	// 5 requests use the burst and the other 10 need at least 10ms.
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("15 concurrent requests took %v, want at least %v", elapsed, 10*time.Millisecond)
+	}
+}
+
+type countingLimiter struct {
+	waits int
+	err   error
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.waits++
+	return l.err
+}
+
+func TestDoRateLimiter(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	limiter := new(countingLimiter)
+	WithRateLimiter(limiter)(client)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		req, _ := client.NewRequest(http.MethodGet, ".")
+		if _, err := client.Do(ctx, req, nil); err != nil {
+			t.Fatalf("Do returned unexpected error: %v", err)
+		}
+	}
+	if got, want := limiter.waits, 3; got != want {
+		t.Errorf("rate limiter waited %d times, want %d", got, want)
+	}
+}
+
+func TestDoRateLimiterRetries(t *testing.T) {
+	client, mux, _, teardown := setupRetry(RetryPolicy{MaxAttempts: 3})
+	defer teardown()
+	limiter := new(countingLimiter)
+	WithRateLimiter(limiter)(client)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"","error":"too_many_requests"}`, http.StatusTooManyRequests)
+	})
+
+	req, _ := client.NewRequest(http.MethodGet, ".")
+	_, _ = client.Do(context.Background(), req, nil)
+	if got, want := limiter.waits, 3; got != want {
+		t.Errorf("rate limiter waited %d times, want %d", got, want)
+	}
+}
+
+func TestDoRateLimiterError(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	limiter := &countingLimiter{err: context.DeadlineExceeded}
+	WithRateLimiter(limiter)(client)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request was sent even though the rate limiter returned an error")
+	})
+
+	req, _ := client.NewRequest(http.MethodGet, ".")
+	_, err := client.Do(context.Background(), req, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do returned err = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,185 @@
+package mal
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMinBackoff = 1 * time.Second
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy controls how a Client retries requests that failed due to
+// transient errors such as connection resets, 5xx server errors or 429 Too
+// Many Requests. Retries are disabled by default and can be enabled using the
+// WithRetryPolicy option.
+//
+// Only GET requests are retried by default as they are idempotent. The PATCH
+// requests sent by AnimeService.UpdateMyListStatus and
+// MangaService.UpdateMyListStatus are retried only if RetryPatch is set.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request will be sent,
+	// including the first attempt. A value of 1 or less disables retries.
+	MaxAttempts int
+
+	// MinBackoff is the wait before the first retry. Each following retry
+	// waits twice as long as the previous one with some random jitter added.
+	// Defaults to 1 second.
+	MinBackoff time.Duration
+
+	// MaxBackoff caps the wait between two attempts. It does not apply when
+	// the API responds with a Retry-After header which is always honored.
+	// Defaults to 30 seconds.
+	MaxBackoff time.Duration
+
+	// RetryPatch enables retrying PATCH requests which are used for updating
+	// the user's anime and manga lists.
+	RetryPatch bool
+}
+
+// DefaultRetryPolicy is a retry policy with sensible values that can be passed
+// to WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  defaultMinBackoff,
+	MaxBackoff:  defaultMaxBackoff,
+}
+
+// WithRetryPolicy is a client option which enables retrying requests that
+// failed due to transient errors according to the provided policy.
+func WithRetryPolicy(p RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// shouldRetry reports whether the request should be sent again after the
+// attempt-th attempt resulted in resp or err.
+func (p RetryPolicy) shouldRetry(req *http.Request, resp *http.Response, err error, attempt int) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPatch:
+		if !p.RetryPatch {
+			return false
+		}
+	default:
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false // Body cannot be sent again.
+	}
+	if err != nil {
+		// Do not retry if the error was caused by the context being canceled
+		// or its deadline being exceeded.
+		return req.Context().Err() == nil
+	}
+	switch c := resp.StatusCode; {
+	case c == http.StatusTooManyRequests:
+		return true
+	case c == http.StatusNotImplemented:
+		return false
+	case c >= 500:
+		return true
+	}
+	return false
+}
+
+// backoff returns how long to wait before the next attempt. It honors the
+// Retry-After header of resp if present.
+func (p RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+	lo, hi := p.MinBackoff, p.MaxBackoff
+	if lo <= 0 {
+		lo = defaultMinBackoff
+	}
+	if hi <= 0 {
+		hi = defaultMaxBackoff
+	}
+	d := lo
+	for i := 1; i < attempt && d < hi; i++ {
+		d *= 2
+	}
+	if d > hi {
+		d = hi
+	}
+	// Keep half of the backoff and randomize the other half to avoid many
+	// clients retrying at the same time.
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseRetryAfter parses the value of a Retry-After header which can either be
+// a number of seconds or an HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	d := time.Until(t)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+// rewindRequest returns a copy of req with a fresh body so that it can be sent
+// again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+// drainBody reads and closes the body of a response that is about to be
+// discarded so that the underlying connection can be reused.
+func drainBody(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+}
+
+// sleep waits for d or until ctx is done, in which case it returns the context
+// error.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,265 @@
+package mal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// setupRetry is like setup but the returned client retries requests according
+// to policy. Instead of sleeping, the client records the waits between the
+// attempts in the returned slice.
+func setupRetry(policy RetryPolicy) (client *Client, mux *http.ServeMux, waits *[]time.Duration, teardown func()) {
+	client, mux, teardown = setup()
+	WithRetryPolicy(policy)(client)
+	waits = new([]time.Duration)
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return ctx.Err()
+	}
+	return client, mux, waits, teardown
+}
+
+func TestDoRetryServerError(t *testing.T) {
+	client, mux, waits, teardown := setupRetry(RetryPolicy{MaxAttempts: 3})
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			http.Error(w, `{"message":"","error":"internal"}`, http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"id":1}`)
+	})
+
+	req, _ := client.NewRequest(http.MethodGet, ".")
+	a := new(Anime)
+	resp, err := client.Do(context.Background(), req, a)
+	if err != nil {
+		t.Fatalf("Do returned unexpected error: %v", err)
+	}
+	if got, want := a, (&Anime{ID: 1}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Do decoded %+v, want %+v", got, want)
+	}
+	if got, want := resp.Attempts, 3; got != want {
+		t.Errorf("resp.Attempts = %d, want %d", got, want)
+	}
+	if got, want := len(*waits), 2; got != want {
+		t.Fatalf("Do waited %d times, want %d", got, want)
+	}
+	// First wait is between MinBackoff/2 and MinBackoff, second between
+	// MinBackoff and 2*MinBackoff.
+	for i, w := range *waits {
+		lo, hi := defaultMinBackoff<<i/2, defaultMinBackoff<<i
+		if w < lo || w > hi {
+			t.Errorf("wait #%d = %v, want between %v and %v", i+1, w, lo, hi)
+		}
+	}
+}
+
+func TestDoRetryAfter(t *testing.T) {
+	client, mux, waits, teardown := setupRetry(RetryPolicy{MaxAttempts: 2})
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "42")
+			http.Error(w, `{"message":"","error":"too_many_requests"}`, http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+
+	req, _ := client.NewRequest(http.MethodGet, ".")
+	resp, err := client.Do(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("Do returned unexpected error: %v", err)
+	}
+	if got, want := resp.Attempts, 2; got != want {
+		t.Errorf("resp.Attempts = %d, want %d", got, want)
+	}
+	if got, want := *waits, []time.Duration{42 * time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Do waited %v, want %v", got, want)
+	}
+}
+
+func TestDoRetryExhausted(t *testing.T) {
+	client, mux, _, teardown := setupRetry(RetryPolicy{MaxAttempts: 3})
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"mal is down","error":"internal"}`, http.StatusInternalServerError)
+	})
+
+	req, _ := client.NewRequest(http.MethodGet, ".")
+	resp, err := client.Do(context.Background(), req, nil)
+	if err == nil {
+		t.Fatal("Do expected internal error, got no error.")
+	}
+	testErrorResponse(t, err, ErrorResponse{Message: "mal is down", Err: "internal"})
+	testResponseStatusCode(t, resp, http.StatusInternalServerError, "Do")
+	if got, want := resp.Attempts, 3; got != want {
+		t.Errorf("resp.Attempts = %d, want %d", got, want)
+	}
+}
+
+func TestDoNoRetryClientError(t *testing.T) {
+	client, mux, _, teardown := setupRetry(RetryPolicy{MaxAttempts: 3})
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"","error":"not_found"}`, http.StatusNotFound)
+	})
+
+	req, _ := client.NewRequest(http.MethodGet, ".")
+	resp, err := client.Do(context.Background(), req, nil)
+	if err == nil {
+		t.Fatal("Do expected not found error, got no error.")
+	}
+	if got, want := resp.Attempts, 1; got != want {
+		t.Errorf("resp.Attempts = %d, want %d", got, want)
+	}
+}
+
+func TestDoNoRetryByDefault(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"","error":"internal"}`, http.StatusServiceUnavailable)
+	})
+
+	req, _ := client.NewRequest(http.MethodGet, ".")
+	resp, err := client.Do(context.Background(), req, nil)
+	if err == nil {
+		t.Fatal("Do expected internal error, got no error.")
+	}
+	if got, want := resp.Attempts, 1; got != want {
+		t.Errorf("resp.Attempts = %d, want %d", got, want)
+	}
+}
+
+func TestDoRetryPatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		retryPatch   bool
+		wantStatus   int
+		wantAttempts int
+	}{
+		{name: "disabled by default", retryPatch: false, wantStatus: http.StatusBadGateway, wantAttempts: 1},
+		{name: "enabled", retryPatch: true, wantStatus: http.StatusOK, wantAttempts: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mux, _, teardown := setupRetry(RetryPolicy{MaxAttempts: 2, RetryPatch: tt.retryPatch})
+			defer teardown()
+
+			requests := 0
+			mux.HandleFunc("/anime/1/my_list_status", func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, http.MethodPatch)
+				testBody(t, r, "score=8")
+				requests++
+				if requests == 1 {
+					http.Error(w, `{"message":"","error":"internal"}`, http.StatusBadGateway)
+					return
+				}
+				fmt.Fprint(w, `{"score":8}`)
+			})
+
+			_, resp, _ := client.Anime.UpdateMyListStatus(context.Background(), 1, Score(8))
+			testResponseStatusCode(t, resp, tt.wantStatus, "Anime.UpdateMyListStatus")
+			if got, want := resp.Attempts, tt.wantAttempts; got != want {
+				t.Errorf("resp.Attempts = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+type flakyTransport struct {
+	failures int
+}
+
+func (f *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if f.failures > 0 {
+		f.failures--
+		return nil, fmt.Errorf("connection reset by peer")
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestDoRetryRoundTripError(t *testing.T) {
+	client, mux, _, teardown := setupRetry(RetryPolicy{MaxAttempts: 3})
+	defer teardown()
+	client.client = &http.Client{Transport: &flakyTransport{failures: 2}}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+
+	req, _ := client.NewRequest(http.MethodGet, ".")
+	resp, err := client.Do(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("Do returned unexpected error: %v", err)
+	}
+	if got, want := resp.Attempts, 3; got != want {
+		t.Errorf("resp.Attempts = %d, want %d", got, want)
+	}
+}
+
+func TestDoRetryContextCanceled(t *testing.T) {
+	client, mux, _, teardown := setupRetry(RetryPolicy{MaxAttempts: 3})
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return sleep(ctx, d)
+	}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"","error":"internal"}`, http.StatusServiceUnavailable)
+	})
+
+	req, _ := client.NewRequest(http.MethodGet, ".")
+	_, err := client.Do(ctx, req, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Do returned err = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   time.Duration
+		wantOK bool
+	}{
+		{in: "", want: 0, wantOK: false},
+		{in: "5", want: 5 * time.Second, wantOK: true},
+		{in: "-1", want: 0, wantOK: false},
+		{in: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOK: true},
+		{in: "foo", want: 0, wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffMax(t *testing.T) {
+	p := RetryPolicy{MinBackoff: time.Second, MaxBackoff: 4 * time.Second}
+	for attempt := 1; attempt < 10; attempt++ {
+		if d := p.backoff(attempt, nil); d > p.MaxBackoff {
+			t.Errorf("backoff(%d) = %v, want at most %v", attempt, d, p.MaxBackoff)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package mal
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// seasons are the anime seasons in the order they occur in a year.
+var seasons = [...]AnimeSeason{AnimeSeasonWinter, AnimeSeasonSpring, AnimeSeasonSummer, AnimeSeasonFall}
+
+// index returns the position of s in the year or -1 if s is not a valid
+// season.
+func (s AnimeSeason) index() int {
+	for i := range seasons {
+		if seasons[i] == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// Season is an anime season of a specific year, such as fall 2023.
+type Season struct {
+	Year   int
+	Season AnimeSeason
+}
+
+// SeasonOf returns the season that t falls in, according to the calendar of
+// its location. Use t.In(mal.JST) to get the season as it is in Japan.
+func SeasonOf(t time.Time) Season {
+	return Season{Year: t.Year(), Season: seasons[(t.Month()-1)/3]}
+}
+
+// ParseSeason parses a season in the format "fall 2023". The season name is
+// case insensitive.
+func ParseSeason(s string) (Season, error) {
+	parts := strings.Fields(s)
+	if len(parts) != 2 {
+		return Season{}, fmt.Errorf("mal: invalid season %q", s)
+	}
+	season := AnimeSeason(strings.ToLower(parts[0]))
+	year, err := strconv.Atoi(parts[1])
+	if err != nil || season.index() < 0 || year <= 0 {
+		return Season{}, fmt.Errorf("mal: invalid season %q", s)
+	}
+	return Season{Year: year, Season: season}, nil
+}
+
+// String returns the season in the format "fall 2023" accepted by ParseSeason.
+func (s Season) String() string {
+	return fmt.Sprintf("%s %d", s.Season, s.Year)
+}
+
+// valid reports whether s has a known season name.
+func (s Season) valid() bool {
+	return s.Season.index() >= 0
+}
+
+// add returns the season n seasons after s, or before if n is negative. s must
+// be valid.
+func (s Season) add(n int) Season {
+	i := s.Year*len(seasons) + s.Season.index() + n
+	year, idx := i/len(seasons), i%len(seasons)
+	if idx < 0 {
+		year, idx = year-1, idx+len(seasons)
+	}
+	return Season{Year: year, Season: seasons[idx]}
+}
+
+// Next returns the season that follows s, for example winter 2024 after fall
+// 2023. If s does not have a known season name, it is returned unchanged.
+func (s Season) Next() Season {
+	if !s.valid() {
+		return s
+	}
+	return s.add(1)
+}
+
+// Prev returns the season that precedes s, for example fall 2023 before winter
+// 2024. If s does not have a known season name, it is returned unchanged.
+func (s Season) Prev() Season {
+	if !s.valid() {
+		return s
+	}
+	return s.add(-1)
+}
+
+// Compare compares s with other and returns -1 if s is before other, +1 if it
+// is after and 0 if they are the same season.
+func (s Season) Compare(other Season) int {
+	if c := cmpInt(s.Year, other.Year); c != 0 {
+		return c
+	}
+	return cmpInt(s.Season.index(), other.Season.index())
+}
+
+// Before reports whether s is before other.
+func (s Season) Before(other Season) bool { return s.Compare(other) < 0 }
+
+// After reports whether s is after other.
+func (s Season) After(other Season) bool { return s.Compare(other) > 0 }
+
+// MarshalText implements encoding.TextMarshaler using the format of String.
+func (s Season) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseSeason.
+func (s *Season) UnmarshalText(text []byte) error {
+	season, err := ParseSeason(string(text))
+	if err != nil {
+		return err
+	}
+	*s = season
+	return nil
+}
+
+// SeasonRange returns the seasons from from to to, inclusive, in order. It
+// returns nil if from is after to or if any of them does not have a known
+// season name.
+func SeasonRange(from, to Season) []Season {
+	if !from.valid() || !to.valid() || from.After(to) {
+		return nil
+	}
+	var ss []Season
+	for s := from; !s.After(to); s = s.Next() {
+		ss = append(ss, s)
+	}
+	return ss
+}
+
+// SeasonalFor is like Seasonal but takes the year and season as a Season.
+func (s *AnimeService) SeasonalFor(ctx context.Context, season Season, options ...SeasonalAnimeOption) ([]Anime, *Response, error) {
+	return s.Seasonal(ctx, season.Year, season.Season, options...)
+}
+
+// SeasonalRangeIterator returns an iterator over all the anime of every season
+// from from to to, inclusive, requesting every page of a season before moving
+// to the next one. It accepts the same options as AnimeService.Seasonal. The
+// Offset option only applies to the first season. Anime that air for more than
+// one season are returned once for each of them.
+func (s *AnimeService) SeasonalRangeIterator(from, to Season, options ...SeasonalAnimeOption) *AnimeIterator {
+	oo := make([]Option, len(options))
+	q := url.Values{}
+	for i := range options {
+		oo[i] = optionFromSeasonalAnimeOption(options[i])
+		oo[i].apply(&q)
+	}
+	offset := startOffset(q)
+	remaining := SeasonRange(from, to)
+	it := new(AnimeIterator)
+	// fetch requests the pages of remaining[0].
+	var fetch func(context.Context) (int, *Response, bool, error)
+	it.fetch = func(ctx context.Context) (int, *Response, bool, error) {
+		for len(remaining) > 0 {
+			if fetch == nil {
+				path := seasonalPath(remaining[0].Year, remaining[0].Season)
+				fetch = pageByOffset(offset, func(ctx context.Context, offset Offset) (int, *Response, error) {
+					page, resp, err := s.list(ctx, path, append(oo[:len(oo):len(oo)], offset)...)
+					it.page = page
+					return len(page), resp, err
+				})
+				offset = 0
+			}
+			n, resp, last, err := fetch(ctx)
+			if err != nil {
+				return 0, resp, true, err
+			}
+			if last || n == 0 {
+				remaining, fetch = remaining[1:], nil
+			}
+			// Seasons without anime are skipped since an empty page
+			// would end the iteration.
+			if n > 0 || len(remaining) == 0 {
+				return n, resp, len(remaining) == 0, nil
+			}
+		}
+		return 0, nil, true, nil
+	}
+	return it
+}
@@ -0,0 +1,134 @@
+package mal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSeasonOf(t *testing.T) {
+	tests := []struct {
+		month time.Month
+		want  AnimeSeason
+	}{
+		{time.January, AnimeSeasonWinter},
+		{time.March, AnimeSeasonWinter},
+		{time.April, AnimeSeasonSpring},
+		{time.June, AnimeSeasonSpring},
+		{time.July, AnimeSeasonSummer},
+		{time.September, AnimeSeasonSummer},
+		{time.October, AnimeSeasonFall},
+		{time.December, AnimeSeasonFall},
+	}
+	for _, tt := range tests {
+		got := SeasonOf(time.Date(2023, tt.month, 15, 0, 0, 0, 0, time.UTC))
+		if want := (Season{Year: 2023, Season: tt.want}); got != want {
+			t.Errorf("SeasonOf(%s) = %v, want %v", tt.month, got, want)
+		}
+	}
+
+	// The last evening of fall in UTC is already winter in Japan.
+	nye := time.Date(2023, time.December, 31, 20, 0, 0, 0, time.UTC)
+	if got, want := SeasonOf(nye.In(JST)), (Season{Year: 2024, Season: AnimeSeasonWinter}); got != want {
+		t.Errorf("SeasonOf in JST = %v, want %v", got, want)
+	}
+}
+
+func TestSeasonNextPrev(t *testing.T) {
+	tests := []struct {
+		s, next Season
+	}{
+		{Season{2023, AnimeSeasonWinter}, Season{2023, AnimeSeasonSpring}},
+		{Season{2023, AnimeSeasonSpring}, Season{2023, AnimeSeasonSummer}},
+		{Season{2023, AnimeSeasonSummer}, Season{2023, AnimeSeasonFall}},
+		{Season{2023, AnimeSeasonFall}, Season{2024, AnimeSeasonWinter}},
+	}
+	for _, tt := range tests {
+		if got := tt.s.Next(); got != tt.next {
+			t.Errorf("%v.Next() = %v, want %v", tt.s, got, tt.next)
+		}
+		if got := tt.next.Prev(); got != tt.s {
+			t.Errorf("%v.Prev() = %v, want %v", tt.next, got, tt.s)
+		}
+	}
+
+	invalid := Season{2023, "autumn"}
+	if got := invalid.Next(); got != invalid {
+		t.Errorf("%v.Next() = %v, want it unchanged", invalid, got)
+	}
+	if got := invalid.Prev(); got != invalid {
+		t.Errorf("%v.Prev() = %v, want it unchanged", invalid, got)
+	}
+}
+
+func TestParseSeason(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Season
+		wantErr bool
+	}{
+		{in: "fall 2023", want: Season{2023, AnimeSeasonFall}},
+		{in: "Winter 1999", want: Season{1999, AnimeSeasonWinter}},
+		{in: "  SUMMER   2020 ", want: Season{2020, AnimeSeasonSummer}},
+		{in: "", wantErr: true},
+		{in: "fall", wantErr: true},
+		{in: "2023 fall", wantErr: true},
+		{in: "autumn 2023", wantErr: true},
+		{in: "fall 20x3", wantErr: true},
+		{in: "fall -1", wantErr: true},
+		{in: "fall 2023 extra", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSeason(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSeason(%q) returned error %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSeason(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSeasonText(t *testing.T) {
+	s := Season{Year: 2023, Season: AnimeSeasonFall}
+	text, err := s.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText returned error: %v", err)
+	}
+	if got, want := string(text), "fall 2023"; got != want {
+		t.Errorf("MarshalText = %q, want %q", got, want)
+	}
+	var got Season
+	if err := got.UnmarshalText(text); err != nil {
+		t.Fatalf("UnmarshalText returned error: %v", err)
+	}
+	if got != s {
+		t.Errorf("UnmarshalText = %v, want %v", got, s)
+	}
+	if err := got.UnmarshalText([]byte("fall")); err == nil {
+		t.Error("UnmarshalText expected error for an invalid season")
+	}
+}
+
+func TestSeasonRange(t *testing.T) {
+	from := Season{2022, AnimeSeasonSummer}
+	to := Season{2023, AnimeSeasonWinter}
+	want := []Season{
+		{2022, AnimeSeasonSummer},
+		{2022, AnimeSeasonFall},
+		{2023, AnimeSeasonWinter},
+	}
+	if got := SeasonRange(from, to); !reflect.DeepEqual(got, want) {
+		t.Errorf("SeasonRange(%v, %v) = %v, want %v", from, to, got, want)
+	}
+	if got := SeasonRange(from, from); !reflect.DeepEqual(got, []Season{from}) {
+		t.Errorf("SeasonRange(%v, %v) = %v, want only %v", from, from, got, from)
+	}
+	if got := SeasonRange(to, from); got != nil {
+		t.Errorf("SeasonRange(%v, %v) = %v, want nil", to, from, got)
+	}
+	if got := SeasonRange(Season{2022, "autumn"}, to); got != nil {
+		t.Errorf("SeasonRange with invalid season = %v, want nil", got)
+	}
+}
@@ -83,8 +83,8 @@ type AnimeListStatus struct {
 	RewatchValue       int         `json:"rewatch_value"`
 	Tags               []string    `json:"tags"`
 	Comments           string      `json:"comments"`
-	StartDate          string      `json:"start_date"`
-	FinishDate         string      `json:"finish_date"`
+	StartDate          Date        `json:"start_date"`
+	FinishDate         Date        `json:"finish_date"`
 }
 
 // animeList represents the anime list of a user.
@@ -199,34 +199,57 @@ func (c Comments) updateMyAnimeListStatusApply(v *url.Values) { v.Set("comments"
 func (c Comments) updateMyMangaListStatusApply(v *url.Values) { v.Set("comments", string(c)) }
 
 // StartDate is an option that allows to update the start date of anime and manga
-// in the user's list.
-type StartDate time.Time
-
-func formatMALDate(d time.Time) string {
-	if d.IsZero() {
-		return ""
-	}
-	return d.Format("2006-01-02")
-}
+// in the user's list. The zero StartDate removes an existing date.
+type StartDate Date
 
 func (d StartDate) updateMyAnimeListStatusApply(v *url.Values) {
-	v.Set("start_date", formatMALDate(time.Time(d)))
+	v.Set("start_date", Date(d).String())
 }
 func (d StartDate) updateMyMangaListStatusApply(v *url.Values) {
-	v.Set("start_date", formatMALDate(time.Time(d)))
+	v.Set("start_date", Date(d).String())
 }
 
 // FinishDate is an option that allows to update the finish date of anime and manga
-// in the user's list.
-type FinishDate time.Time
+// in the user's list. The zero FinishDate removes an existing date.
+type FinishDate Date
 
 func (d FinishDate) updateMyAnimeListStatusApply(v *url.Values) {
-	v.Set("finish_date", formatMALDate(time.Time(d)))
+	v.Set("finish_date", Date(d).String())
 }
 func (d FinishDate) updateMyMangaListStatusApply(v *url.Values) {
-	v.Set("finish_date", formatMALDate(time.Time(d)))
+	v.Set("finish_date", Date(d).String())
 }
 
+// Clear is an option that removes the value of a field of an anime or manga in
+// the user's list when updating it. If the same field is also set by another
+// option, the option that comes last wins.
+type Clear string
+
+const (
+	// ClearScore removes the score of a list item.
+	ClearScore Clear = "score"
+	// ClearTags removes all the tags of a list item.
+	ClearTags Clear = "tags"
+	// ClearComments removes the comments of a list item.
+	ClearComments Clear = "comments"
+	// ClearStartDate removes the start date of a list item.
+	ClearStartDate Clear = "start_date"
+	// ClearFinishDate removes the finish date of a list item.
+	ClearFinishDate Clear = "finish_date"
+)
+
+// value returns the value which clears the field. A score of 0 means that
+// the item is not scored while the other fields are cleared when empty.
+func (c Clear) value() string {
+	if c == ClearScore {
+		return "0"
+	}
+	return ""
+}
+
+func (c Clear) updateMyAnimeListStatusApply(v *url.Values) { v.Set(string(c), c.value()) }
+func (c Clear) updateMyMangaListStatusApply(v *url.Values) { v.Set(string(c), c.value()) }
+
 // UpdateMyListStatus adds the anime specified by animeID to the user's anime
 // list with one or more options added to update the status. If the anime
 // already exists in the list, only the status is updated.
@@ -251,7 +274,7 @@ func (s *AnimeService) UpdateMyListStatus(ctx context.Context, animeID int, opti
 }
 
 // DeleteMyListItem deletes an anime from the user's list. If the anime does not
-// exist in the user's list, 404 Not Found error is returned.
+// exist in the user's list, an error matching ErrNotFound is returned.
 func (s *AnimeService) DeleteMyListItem(ctx context.Context, animeID int) (*Response, error) {
 	u := fmt.Sprintf("anime/%d/my_list_status", animeID)
 	req, err := s.client.NewRequest(http.MethodDelete, u)
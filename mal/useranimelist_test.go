@@ -135,8 +135,8 @@ func TestAnimeServiceUpdateMyListStatus(t *testing.T) {
 		RewatchValue(1),
 		Tags{"foo", "bar"},
 		Comments("comments"),
-		StartDate(time.Date(2022, 02, 20, 0, 0, 0, 0, time.UTC)),
-		FinishDate(time.Time{}),
+		StartDate{Year: 2022, Month: time.February, Day: 20},
+		FinishDate{},
 	)
 	if err != nil {
 		t.Errorf("Anime.UpdateMyListStatus returned error: %v", err)
@@ -153,14 +153,40 @@ func TestAnimeServiceUpdateMyListStatus(t *testing.T) {
 		Tags:               []string{"foo", "bar"},
 		Comments:           "comments",
 		UpdatedAt:          time.Date(2018, 04, 25, 15, 59, 52, 0, time.UTC),
-		StartDate:          "2022-02-20",
-		FinishDate:         "",
+		StartDate:          Date{Year: 2022, Month: time.February, Day: 20},
 	}
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("Anime.UpdateMyListStatus returned\nhave: %+v\n\nwant: %+v", got, want)
 	}
 }
 
+func TestAnimeServiceUpdateMyListStatusClear(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/anime/1/my_list_status", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPatch)
+		testContentType(t, r, "application/x-www-form-urlencoded")
+		testBody(t, r, "comments=&finish_date=&score=0&start_date=&status=watching&tags=")
+		fmt.Fprint(w, `{"status":"watching","score":0}`)
+	})
+
+	ctx := context.Background()
+	_, _, err := client.Anime.UpdateMyListStatus(ctx, 1,
+		AnimeStatusWatching,
+		Score(8),
+		Tags{"foo"},
+		ClearScore,
+		ClearTags,
+		ClearComments,
+		ClearStartDate,
+		ClearFinishDate,
+	)
+	if err != nil {
+		t.Errorf("Anime.UpdateMyListStatus returned error: %v", err)
+	}
+}
+
 func TestAnimeServiceUpdateMyListStatusError(t *testing.T) {
 	client, mux, teardown := setup()
 	defer teardown()
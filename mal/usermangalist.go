@@ -45,8 +45,8 @@ type MangaListStatus struct {
 	RereadValue     int         `json:"reread_value"`
 	Tags            []string    `json:"tags"`
 	Comments        string      `json:"comments"`
-	StartDate       string      `json:"start_date"`
-	FinishDate      string      `json:"finish_date"`
+	StartDate       Date        `json:"start_date"`
+	FinishDate      Date        `json:"finish_date"`
 }
 
 // mangaList represents the anime list of a user.
@@ -200,7 +200,7 @@ func (s *MangaService) UpdateMyListStatus(ctx context.Context, mangaID int, opti
 }
 
 // DeleteMyListItem deletes a manga from the user's list. If the manga does not
-// exist in the user's list, 404 Not Found error is returned.
+// exist in the user's list, an error matching ErrNotFound is returned.
 func (s *MangaService) DeleteMyListItem(ctx context.Context, mangaID int) (*Response, error) {
 	u := fmt.Sprintf("manga/%d/my_list_status", mangaID)
 	req, err := s.client.NewRequest(http.MethodDelete, u)
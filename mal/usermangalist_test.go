@@ -130,8 +130,8 @@ func TestMangaServiceUpdateMyListStatus(t *testing.T) {
 		RereadValue(1),
 		Tags{"foo", "bar"},
 		Comments("comments"),
-		StartDate(time.Date(2022, 02, 20, 0, 0, 0, 0, time.UTC)),
-		FinishDate(time.Time{}),
+		StartDate{Year: 2022, Month: time.February, Day: 20},
+		FinishDate{},
 	)
 	if err != nil {
 		t.Errorf("Manga.UpdateMyListStatus returned error: %v", err)
@@ -149,14 +149,40 @@ func TestMangaServiceUpdateMyListStatus(t *testing.T) {
 		Tags:            []string{"foo", "bar"},
 		Comments:        "comments",
 		UpdatedAt:       time.Date(2018, 04, 25, 15, 59, 52, 0, time.UTC),
-		StartDate:       "2022-02-20",
-		FinishDate:      "",
+		StartDate:       Date{Year: 2022, Month: time.February, Day: 20},
 	}
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("Manga.UpdateMyListStatus returned\nhave: %+v\n\nwant: %+v", got, want)
 	}
 }
 
+func TestMangaServiceUpdateMyListStatusClear(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/manga/1/my_list_status", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPatch)
+		testContentType(t, r, "application/x-www-form-urlencoded")
+		testBody(t, r, "comments=&finish_date=&score=0&start_date=&status=reading&tags=")
+		fmt.Fprint(w, `{"status":"reading","score":0}`)
+	})
+
+	ctx := context.Background()
+	_, _, err := client.Manga.UpdateMyListStatus(ctx, 1,
+		MangaStatusReading,
+		Score(8),
+		Tags{"foo"},
+		ClearScore,
+		ClearTags,
+		ClearComments,
+		ClearStartDate,
+		ClearFinishDate,
+	)
+	if err != nil {
+		t.Errorf("Manga.UpdateMyListStatus returned error: %v", err)
+	}
+}
+
 func TestMangaServiceUpdateMyListStatusError(t *testing.T) {
 	client, mux, teardown := setup()
 	defer teardown()
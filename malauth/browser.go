@@ -0,0 +1,22 @@
+package malauth
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// OpenBrowser opens url in the default browser of the user. It can be passed
+// to Authenticate.
+func OpenBrowser(url string) error {
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return exec.Command("xdg-open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	case "darwin":
+		return exec.Command("open", url).Start()
+	default:
+		return fmt.Errorf("malauth: opening browser: unsupported operating system: %v", runtime.GOOS)
+	}
+}
@@ -0,0 +1,206 @@
+// Package malauth implements the OAuth2 authorization flow of MyAnimeList.
+//
+// MyAnimeList uses the authorization code grant with PKCE, supporting only the
+// plain code challenge method, which means the code verifier is sent as the
+// code challenge. The typical flow for a command line application is:
+//
+//	conf := malauth.NewConfig(clientID, clientSecret, "http://localhost:8080/callback")
+//	token, err := malauth.Authenticate(ctx, conf, malauth.OpenBrowser)
+//	if err != nil {
+//		// ...
+//	}
+//	c := mal.NewClient(conf.Client(ctx, token))
+//
+// Authenticate starts a local server on the redirect URL to capture the
+// authorization code automatically. The redirect URL must match the App
+// Redirect URL of the application in https://myanimelist.net/apiconfig.
+//
+// Applications that handle the redirect themselves, such as web applications,
+// can use NewCodeVerifier, NewState, AuthCodeURL and Exchange instead.
+//
+// Tokens are persisted with a TokenStore. NewClient returns an HTTP client for
+// a stored token which saves every refreshed token back to the store.
+//
+// Authorization documentation:
+//
+// https://myanimelist.net/apiconfig/references/authorization
+package malauth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"golang.org/x/oauth2"
+)
+
+// Endpoint is the OAuth2 endpoint of MyAnimeList. The client credentials are
+// sent in the request body as MyAnimeList does not support HTTP Basic
+// authentication.
+var Endpoint = oauth2.Endpoint{
+	AuthURL:   "https://myanimelist.net/v1/oauth2/authorize",
+	TokenURL:  "https://myanimelist.net/v1/oauth2/token",
+	AuthStyle: oauth2.AuthStyleInParams,
+}
+
+// NewConfig returns an OAuth2 configuration for a MyAnimeList application.
+// The client secret can be empty if the App Type of the application is
+// 'other'. The redirect URL can be empty if the application has only one
+// App Redirect URL.
+func NewConfig(clientID, clientSecret, redirectURL string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Endpoint:     Endpoint,
+		RedirectURL:  redirectURL,
+	}
+}
+
+const (
+	// MinCodeVerifierLength is the minimum length of a code verifier.
+	MinCodeVerifierLength = 43
+	// MaxCodeVerifierLength is the maximum length of a code verifier.
+	MaxCodeVerifierLength = 128
+)
+
+// unreserved are the characters allowed in a code verifier.
+const unreserved = "ABCDEFGHIJKLMNOPQRSTUVWXYZ" +
+	"abcdefghijklmnopqrstuvwxyz" +
+	"0123456789-._~"
+
+// NewCodeVerifier returns a code verifier of the maximum length, a high-entropy
+// cryptographic random string.
+func NewCodeVerifier() (string, error) {
+	return GenerateCodeVerifier(MaxCodeVerifierLength)
+}
+
+// GenerateCodeVerifier returns a code verifier of the given length which must
+// be between MinCodeVerifierLength and MaxCodeVerifierLength.
+func GenerateCodeVerifier(length int) (string, error) {
+	if length < MinCodeVerifierLength || length > MaxCodeVerifierLength {
+		return "", fmt.Errorf("malauth: code verifier length %d must be between %d and %d",
+			length, MinCodeVerifierLength, MaxCodeVerifierLength)
+	}
+	return randomString(length)
+}
+
+// NewState returns a random state that protects the flow against CSRF
+// attacks. It should be validated when the user is redirected back.
+func NewState() (string, error) {
+	return randomString(32)
+}
+
+// randomString returns a cryptographic random string of unreserved
+// characters. Random bytes that would make some characters more likely than
+// others are discarded.
+func randomString(length int) (string, error) {
+	const max = 256 - 256%len(unreserved)
+	s := make([]byte, 0, length)
+	buf := make([]byte, length)
+	for len(s) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) < max && len(s) < length {
+				s = append(s, unreserved[int(b)%len(unreserved)])
+			}
+		}
+	}
+	return string(s), nil
+}
+
+// AuthCodeURL returns the URL where the user needs to be redirected to allow
+// the application to access their MyAnimeList data. The code verifier is set
+// as the plain code challenge.
+func AuthCodeURL(conf *oauth2.Config, state, codeVerifier string) string {
+	return conf.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("code_challenge", codeVerifier),
+		oauth2.SetAuthURLParam("code_challenge_method", "plain"),
+	)
+}
+
+// Exchange exchanges the authorization code for a token. The code verifier
+// must be the one used in AuthCodeURL.
+func Exchange(ctx context.Context, conf *oauth2.Config, code, codeVerifier string) (*oauth2.Token, error) {
+	token, err := conf.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("malauth: exchanging code for token: %w", err)
+	}
+	return token, nil
+}
+
+// Authenticate performs the whole authorization flow. It listens on the
+// redirect URL of conf, calls open with the authorization URL, which usually
+// opens it in the user's browser, waits for the user to be redirected back and
+// exchanges the authorization code for a token.
+//
+// The redirect URL must be an http URL with a loopback host, such as
+// http://localhost:8080/callback. If it is empty, a random port of 127.0.0.1
+// is used, which only works if the application accepts that redirect URL.
+//
+// Authenticate returns when the token is received or ctx is done.
+func Authenticate(ctx context.Context, conf *oauth2.Config, open func(authURL string) error) (*oauth2.Token, error) {
+	redirectURL := conf.RedirectURL
+	if redirectURL == "" {
+		redirectURL = "http://127.0.0.1:0/callback"
+	}
+	l, err := ListenRedirect(redirectURL)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+
+	c := *conf
+	c.RedirectURL = l.RedirectURL()
+
+	codeVerifier, err := NewCodeVerifier()
+	if err != nil {
+		return nil, fmt.Errorf("malauth: generating code verifier: %w", err)
+	}
+	state, err := NewState()
+	if err != nil {
+		return nil, fmt.Errorf("malauth: generating state: %w", err)
+	}
+	if err := open(AuthCodeURL(&c, state, codeVerifier)); err != nil {
+		return nil, fmt.Errorf("malauth: opening authorization URL: %w", err)
+	}
+	code, err := l.Wait(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	return Exchange(ctx, &c, code, codeVerifier)
+}
+
+// ErrAccessDenied is returned when the user does not allow the application to
+// access their data.
+var ErrAccessDenied = errors.New("malauth: access denied")
+
+// AuthError is an error returned to the redirect URL by the authorization
+// server.
+type AuthError struct {
+	Code        string // The error code, e.g. access_denied.
+	Description string // A human-readable description, if any.
+}
+
+func (e *AuthError) Error() string {
+	if e.Description == "" {
+		return "malauth: authorization failed: " + e.Code
+	}
+	return fmt.Sprintf("malauth: authorization failed: %s: %s", e.Code, e.Description)
+}
+
+// Is makes an access_denied AuthError match ErrAccessDenied.
+func (e *AuthError) Is(target error) bool {
+	return target == ErrAccessDenied && e.Code == "access_denied"
+}
+
+// authErrorFromQuery returns the error of a redirect URL query, if any.
+func authErrorFromQuery(q url.Values) error {
+	if q.Get("error") == "" {
+		return nil
+	}
+	return &AuthError{Code: q.Get("error"), Description: q.Get("error_description")}
+}
@@ -0,0 +1,169 @@
+package malauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGenerateCodeVerifier(t *testing.T) {
+	for _, n := range []int{MinCodeVerifierLength, 64, MaxCodeVerifierLength} {
+		v, err := GenerateCodeVerifier(n)
+		if err != nil {
+			t.Fatalf("GenerateCodeVerifier(%d) returned error: %v", n, err)
+		}
+		if len(v) != n {
+			t.Errorf("GenerateCodeVerifier(%d) returned length %d", n, len(v))
+		}
+		if i := strings.IndexFunc(v, func(r rune) bool { return !strings.ContainsRune(unreserved, r) }); i != -1 {
+			t.Errorf("GenerateCodeVerifier(%d) = %q contains invalid character %q", n, v, v[i])
+		}
+	}
+	for _, n := range []int{0, MinCodeVerifierLength - 1, MaxCodeVerifierLength + 1} {
+		if _, err := GenerateCodeVerifier(n); err == nil {
+			t.Errorf("GenerateCodeVerifier(%d) expected error", n)
+		}
+	}
+	v1, _ := NewCodeVerifier()
+	v2, _ := NewCodeVerifier()
+	if v1 == v2 {
+		t.Errorf("NewCodeVerifier returned the same verifier twice: %q", v1)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	conf := NewConfig("id", "secret", "http://localhost:8080/callback")
+	u, err := url.Parse(AuthCodeURL(conf, "st", "verifier"))
+	if err != nil {
+		t.Fatalf("AuthCodeURL returned invalid URL: %v", err)
+	}
+	if got, want := u.Scheme+"://"+u.Host+u.Path, Endpoint.AuthURL; got != want {
+		t.Errorf("AuthCodeURL base = %q, want %q", got, want)
+	}
+	want := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"id"},
+		"redirect_uri":          {"http://localhost:8080/callback"},
+		"state":                 {"st"},
+		"code_challenge":        {"verifier"},
+		"code_challenge_method": {"plain"},
+	}
+	if got := u.Query(); got.Encode() != want.Encode() {
+		t.Errorf("AuthCodeURL query = %v, want %v", got, want)
+	}
+}
+
+// tokenServer is a fake token endpoint which records the last token request.
+type tokenServer struct {
+	*httptest.Server
+
+	mu   sync.Mutex
+	form url.Values
+}
+
+func newTokenServer(t *testing.T) *tokenServer {
+	t.Helper()
+	ts := new(tokenServer)
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parsing token request: %v", err)
+		}
+		ts.mu.Lock()
+		ts.form = r.PostForm
+		ts.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"access","refresh_token":"refresh","token_type":"Bearer","expires_in":3600}`)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// checkForm checks the parameters of the last token request.
+func (ts *tokenServer) checkForm(t *testing.T, code, verifier string) {
+	t.Helper()
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	want := map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     "id",
+		"client_secret": "secret",
+		"code":          code,
+		"code_verifier": verifier,
+	}
+	for k, v := range want {
+		if got := ts.form.Get(k); got != v {
+			t.Errorf("token request %s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	ts := newTokenServer(t)
+	conf := NewConfig("id", "secret", "")
+	conf.Endpoint.TokenURL = ts.URL
+
+	token, err := Exchange(context.Background(), conf, "abc", "verifier")
+	if err != nil {
+		t.Fatalf("Exchange returned error: %v", err)
+	}
+	if token.AccessToken != "access" || token.RefreshToken != "refresh" {
+		t.Errorf("Exchange returned token %+v", token)
+	}
+	ts.checkForm(t, "abc", "verifier")
+}
+
+func TestAuthenticate(t *testing.T) {
+	ts := newTokenServer(t)
+	conf := NewConfig("id", "secret", "")
+	conf.Endpoint.TokenURL = ts.URL
+
+	// open plays the user who allows the application: the browser is
+	// redirected back with the code and the state of the authorization URL.
+	var challenge string
+	open := func(authURL string) error {
+		u, err := url.Parse(authURL)
+		if err != nil {
+			return err
+		}
+		q := u.Query()
+		challenge = q.Get("code_challenge")
+		go func() {
+			resp, err := http.Get(q.Get("redirect_uri") + "?code=abc&state=" + url.QueryEscape(q.Get("state")))
+			if err != nil {
+				t.Errorf("redirect returned error: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	token, err := Authenticate(ctx, conf, open)
+	if err != nil {
+		t.Fatalf("Authenticate returned error: %v", err)
+	}
+	if token.AccessToken != "access" {
+		t.Errorf("Authenticate returned token %+v", token)
+	}
+	ts.checkForm(t, "abc", challenge)
+	if conf.RedirectURL != "" {
+		t.Errorf("Authenticate modified conf.RedirectURL to %q", conf.RedirectURL)
+	}
+}
+
+func TestAuthenticateOpenError(t *testing.T) {
+	conf := NewConfig("id", "", "")
+	open := func(string) error { return errors.New("no browser") }
+	if _, err := Authenticate(context.Background(), conf, open); err == nil || !strings.Contains(err.Error(), "no browser") {
+		t.Errorf("Authenticate returned err = %v, want open error", err)
+	}
+}
@@ -0,0 +1,203 @@
+package malauth
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/mal"
+	"golang.org/x/oauth2"
+)
+
+const defaultPoolIdleTimeout = 10 * time.Minute
+
+// PoolConfig configures a ClientPool.
+type PoolConfig struct {
+	// Config is the OAuth2 configuration of the application, used to refresh
+	// the tokens.
+	Config *oauth2.Config
+
+	// Store holds the tokens of the users, keyed by user ID. Refreshed tokens
+	// are saved back to it.
+	Store TokenStore
+
+	// Transport sends the requests of all the clients, including the token
+	// refresh requests, so that they share connections. Defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// RateLimiter, if set, is shared by all the clients so that the
+	// application as a whole stays below the API limits.
+	RateLimiter mal.RateLimiter
+
+	// Cache, if Cache.Cache is set, enables caching the responses of all the
+	// clients in the same cache. The Identity of each client is set to its
+	// user ID so that users never see the responses of another user. Cached
+	// lists are invalidated when a user updates their list even if they were
+	// cached by a client that has since been evicted.
+	Cache mal.CacheConfig
+
+	// Options are additional options applied to every client, for example
+	// mal.WithRetryPolicy. They are applied before RateLimiter and Cache,
+	// which take precedence over the same options in Options.
+	Options []mal.ClientOption
+
+	// IdleTimeout is how long a client is kept after it was last returned by
+	// ClientPool.Client. Defaults to 10 minutes.
+	IdleTimeout time.Duration
+}
+
+// ClientPool manages the clients of a service that acts on behalf of many
+// MyAnimeList users. Clients are created the first time they are requested,
+// using the token stored for the user, and they are reused until they are idle
+// for longer than the IdleTimeout. All clients share the same transport, rate
+// limiter and cache. It is safe for concurrent use.
+type ClientPool struct {
+	conf PoolConfig
+	ctx  context.Context // Carries the shared transport for token refreshes.
+
+	mu      sync.Mutex
+	ll      *list.List // Front is the most recently used.
+	clients map[string]*list.Element
+
+	// now returns the current time. It can be replaced in tests.
+	now func() time.Time
+}
+
+type poolEntry struct {
+	userID   string
+	client   *mal.Client
+	lastUsed time.Time
+}
+
+// NewClientPool returns an empty ClientPool.
+func NewClientPool(conf PoolConfig) *ClientPool {
+	if conf.Transport == nil {
+		conf.Transport = http.DefaultTransport
+	}
+	if conf.IdleTimeout <= 0 {
+		conf.IdleTimeout = defaultPoolIdleTimeout
+	}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: conf.Transport})
+	return &ClientPool{
+		conf:    conf,
+		ctx:     ctx,
+		ll:      list.New(),
+		clients: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// Client returns the client of the user with the given ID, creating it if
+// needed. It returns ErrTokenNotFound if the store has no token for the user.
+// Idle clients are evicted before returning.
+func (p *ClientPool) Client(userID string) (*mal.Client, error) {
+	if c, ok := p.lookup(userID); ok {
+		return c, nil
+	}
+	// The token is loaded from the store without holding the lock, so that a
+	// slow store does not block the clients of other users. If another
+	// goroutine created a client for the user in the meantime, that one is
+	// returned instead.
+	c, err := p.newClient(userID)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	if e, ok := p.clients[userID]; ok {
+		return p.use(e, now), nil
+	}
+	p.clients[userID] = p.ll.PushFront(&poolEntry{userID: userID, client: c, lastUsed: now})
+	return c, nil
+}
+
+// lookup evicts the idle clients and returns the client of the user, if it is
+// in the pool.
+func (p *ClientPool) lookup(userID string) (*mal.Client, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	p.evictIdle(now)
+	e, ok := p.clients[userID]
+	if !ok {
+		return nil, false
+	}
+	return p.use(e, now), true
+}
+
+// use marks the client of e as the most recently used and returns it.
+func (p *ClientPool) use(e *list.Element, now time.Time) *mal.Client {
+	p.ll.MoveToFront(e)
+	entry := e.Value.(*poolEntry)
+	entry.lastUsed = now
+	return entry.client
+}
+
+// newClient creates the client of a user. The Options of the pool are applied
+// first so that they cannot replace the shared rate limiter or the cache with
+// the Identity of the user.
+func (p *ClientPool) newClient(userID string) (*mal.Client, error) {
+	httpClient, err := NewClient(p.ctx, p.conf.Config, p.conf.Store, userID)
+	if err != nil {
+		return nil, err
+	}
+	options := append([]mal.ClientOption(nil), p.conf.Options...)
+	if p.conf.RateLimiter != nil {
+		options = append(options, mal.WithRateLimiter(p.conf.RateLimiter))
+	}
+	if p.conf.Cache.Cache != nil {
+		cache := p.conf.Cache
+		cache.Identity = userID
+		options = append(options, mal.WithCache(cache))
+	}
+	return mal.NewClient(httpClient, options...), nil
+}
+
+// Remove removes the client of the user, for example after they revoke the
+// access of the application. The next call to Client creates a new client
+// using the token in the store.
+func (p *ClientPool) Remove(userID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e, ok := p.clients[userID]; ok {
+		p.remove(e)
+	}
+}
+
+// EvictIdle removes the clients that have been idle for longer than the
+// IdleTimeout and returns how many were removed. Idle clients are also evicted
+// by Client, so calling EvictIdle is only needed to release memory when the
+// pool is not used for a while.
+func (p *ClientPool) EvictIdle() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.evictIdle(p.now())
+}
+
+func (p *ClientPool) evictIdle(now time.Time) int {
+	n := 0
+	for e := p.ll.Back(); e != nil; e = p.ll.Back() {
+		if now.Sub(e.Value.(*poolEntry).lastUsed) <= p.conf.IdleTimeout {
+			break
+		}
+		p.remove(e)
+		n++
+	}
+	return n
+}
+
+func (p *ClientPool) remove(e *list.Element) {
+	p.ll.Remove(e)
+	delete(p.clients, e.Value.(*poolEntry).userID)
+}
+
+// Len returns the number of clients in the pool.
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.ll.Len()
+}
@@ -0,0 +1,238 @@
+package malauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/mal"
+	"github.com/nstratos/go-myanimelist/maltest"
+	"golang.org/x/oauth2"
+)
+
+// countingTransport counts the requests it sends.
+type countingTransport struct {
+	n int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.n, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// countingLimiter counts the requests that wait for it.
+type countingLimiter struct {
+	n int32
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	atomic.AddInt32(&l.n, 1)
+	return nil
+}
+
+func newTestPool(t *testing.T) (*ClientPool, *maltest.Server, *countingTransport, *countingLimiter) {
+	t.Helper()
+	s := maltest.NewServer()
+	t.Cleanup(s.Close)
+	s.AddAnime(mal.Anime{ID: 1, Title: "Cowboy Bebop", NumEpisodes: 26})
+	s.AddUser(mal.User{ID: 1, Name: "alice"})
+	s.AddUser(mal.User{ID: 2, Name: "bob"})
+
+	store := NewMemoryTokenStore()
+	for _, name := range []string{"alice", "bob"} {
+		if err := store.Save(name, &oauth2.Token{AccessToken: s.Token(name)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	transport := new(countingTransport)
+	limiter := new(countingLimiter)
+	baseURL := s.Client("").BaseURL
+	p := NewClientPool(PoolConfig{
+		Config:      NewConfig("id", "", ""),
+		Store:       store,
+		Transport:   transport,
+		RateLimiter: limiter,
+		Cache:       mal.CacheConfig{Cache: mal.NewMemoryCache(100)},
+		Options:     []mal.ClientOption{func(c *mal.Client) { c.BaseURL = baseURL }},
+		IdleTimeout: time.Minute,
+	})
+	return p, s, transport, limiter
+}
+
+func TestClientPool(t *testing.T) {
+	p, _, transport, limiter := newTestPool(t)
+
+	ctx := context.Background()
+	for _, name := range []string{"alice", "bob", "alice", "bob"} {
+		c, err := p.Client(name)
+		if err != nil {
+			t.Fatalf("Client(%q) returned error: %v", name, err)
+		}
+		// The shared cache must not serve the info of one user to another.
+		u, _, err := c.User.MyInfo(ctx)
+		if err != nil {
+			t.Fatalf("User.MyInfo for %q returned error: %v", name, err)
+		}
+		if u.Name != name {
+			t.Errorf("User.MyInfo for %q returned user %q", name, u.Name)
+		}
+	}
+	if p.Len() != 2 {
+		t.Errorf("pool has %d clients, want 2", p.Len())
+	}
+	c1, _ := p.Client("alice")
+	c2, _ := p.Client("alice")
+	if c1 != c2 {
+		t.Errorf("Client returned a different client for the same user")
+	}
+	if got := atomic.LoadInt32(&transport.n); got != 2 {
+		t.Errorf("shared transport sent %d requests, want 2 with the second calls cached", got)
+	}
+	if got := atomic.LoadInt32(&limiter.n); got != 2 {
+		t.Errorf("shared limiter was used %d times, want 2", got)
+	}
+
+	if _, err := p.Client("carol"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Client for user without token returned err = %v, want %v", err, ErrTokenNotFound)
+	}
+
+	p.Remove("alice")
+	if c3, _ := p.Client("alice"); c3 == c1 {
+		t.Errorf("Client returned the removed client")
+	}
+}
+
+func TestClientPoolEvictIdle(t *testing.T) {
+	p, _, _, _ := newTestPool(t)
+	now := time.Date(2022, 2, 20, 10, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	alice, _ := p.Client("alice")
+	now = now.Add(40 * time.Second)
+	p.Client("bob")
+	now = now.Add(40 * time.Second)
+
+	if n := p.EvictIdle(); n != 1 {
+		t.Errorf("EvictIdle removed %d clients, want 1", n)
+	}
+	if p.Len() != 1 {
+		t.Errorf("pool has %d clients, want 1", p.Len())
+	}
+	if c, _ := p.Client("alice"); c == alice {
+		t.Errorf("Client returned the evicted client")
+	}
+
+	// Client also evicts idle clients.
+	now = now.Add(2 * time.Minute)
+	p.Client("alice")
+	if p.Len() != 1 {
+		t.Errorf("pool has %d clients after Client, want 1", p.Len())
+	}
+}
+
+func TestClientPoolUpdateAfterEviction(t *testing.T) {
+	p, s, _, _ := newTestPool(t)
+	now := time.Date(2022, 2, 20, 10, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+	s.SetAnimeListStatus("alice", 1, mal.AnimeListStatus{Status: mal.AnimeStatusWatching, NumEpisodesWatched: 3})
+
+	ctx := context.Background()
+	listStatus := func(c *mal.Client) (mal.AnimeListStatus, bool) {
+		t.Helper()
+		list, resp, err := c.User.AnimeList(ctx, "@me", mal.Fields{"list_status"})
+		if err != nil {
+			t.Fatalf("User.AnimeList returned error: %v", err)
+		}
+		if len(list) != 1 {
+			t.Fatalf("User.AnimeList returned %d entries, want 1", len(list))
+		}
+		return list[0].Status, resp.Cached
+	}
+
+	evicted, _ := p.Client("alice")
+	listStatus(evicted)
+	now = now.Add(2 * time.Minute)
+	c, _ := p.Client("alice")
+	if c == evicted {
+		t.Fatal("Client returned the idle client")
+	}
+	if _, _, err := c.Anime.UpdateMyListStatus(ctx, 1, mal.NumEpisodesWatched(10)); err != nil {
+		t.Fatalf("Anime.UpdateMyListStatus returned error: %v", err)
+	}
+	// The list cached by the evicted client is stale.
+	status, cached := listStatus(c)
+	if cached {
+		t.Errorf("User.AnimeList after an update was served from the cache")
+	}
+	if status.NumEpisodesWatched != 10 {
+		t.Errorf("User.AnimeList returned %d episodes watched, want 10", status.NumEpisodesWatched)
+	}
+}
+
+func TestClientPoolCacheOption(t *testing.T) {
+	p, _, _, _ := newTestPool(t)
+	// A cache in the options, without a per-user Identity, must not replace
+	// the cache of the pool.
+	p.conf.Options = append(p.conf.Options, mal.WithCache(mal.CacheConfig{Cache: mal.NewMemoryCache(100), Identity: "shared"}))
+
+	ctx := context.Background()
+	for _, name := range []string{"alice", "bob", "alice", "bob"} {
+		c, err := p.Client(name)
+		if err != nil {
+			t.Fatalf("Client(%q) returned error: %v", name, err)
+		}
+		u, _, err := c.User.MyInfo(ctx)
+		if err != nil {
+			t.Fatalf("User.MyInfo for %q returned error: %v", name, err)
+		}
+		if u.Name != name {
+			t.Errorf("User.MyInfo for %q returned user %q", name, u.Name)
+		}
+	}
+}
+
+// blockingStore is a TokenStore whose Load of a user blocks until release is
+// closed.
+type blockingStore struct {
+	TokenStore
+	user    string
+	loading chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStore) Load(key string) (*oauth2.Token, error) {
+	if key == s.user {
+		close(s.loading)
+		<-s.release
+	}
+	return s.TokenStore.Load(key)
+}
+
+func TestClientPoolSlowStore(t *testing.T) {
+	p, _, _, _ := newTestPool(t)
+	store := &blockingStore{TokenStore: p.conf.Store, user: "alice", loading: make(chan struct{}), release: make(chan struct{})}
+	p.conf.Store = store
+
+	done := make(chan *mal.Client)
+	go func() {
+		c, _ := p.Client("alice")
+		done <- c
+	}()
+	<-store.loading
+	// The clients of other users are created while the token of alice is
+	// loading.
+	if _, err := p.Client("bob"); err != nil {
+		t.Fatalf("Client(%q) returned error: %v", "bob", err)
+	}
+	close(store.release)
+	alice := <-done
+	if alice == nil {
+		t.Fatal("Client(\"alice\") returned no client")
+	}
+	if c, _ := p.Client("alice"); c != alice {
+		t.Errorf("Client returned a different client for the same user")
+	}
+}
@@ -0,0 +1,119 @@
+package malauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// RedirectListener is a local HTTP server that receives the redirect from
+// MyAnimeList after the user allows the application, which means the user
+// does not need to copy the authorization code from the browser.
+type RedirectListener struct {
+	srv       *http.Server
+	url       *url.URL
+	redirects chan url.Values
+}
+
+// ListenRedirect starts a RedirectListener for redirectURL which must be an
+// http URL with a loopback host such as localhost or 127.0.0.1. If the port of
+// redirectURL is 0, a random port is chosen and RedirectURL reports it.
+func ListenRedirect(redirectURL string) (*RedirectListener, error) {
+	u, err := url.Parse(redirectURL)
+	if err != nil {
+		return nil, fmt.Errorf("malauth: parsing redirect URL: %w", err)
+	}
+	if u.Scheme != "http" {
+		return nil, fmt.Errorf("malauth: redirect URL %q must use the http scheme", redirectURL)
+	}
+	if !isLoopback(u.Hostname()) {
+		return nil, fmt.Errorf("malauth: redirect URL %q must have a loopback host", redirectURL)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return nil, fmt.Errorf("malauth: listening on redirect URL: %w", err)
+	}
+	u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(ln.Addr().(*net.TCPAddr).Port))
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	l := &RedirectListener{url: u, redirects: make(chan url.Values, 1)}
+	l.srv = &http.Server{Handler: http.HandlerFunc(l.serveHTTP)}
+	go l.srv.Serve(ln)
+	return l, nil
+}
+
+// isLoopback reports whether host is localhost or a loopback IP address.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// RedirectURL returns the redirect URL that the listener serves, with the
+// actual port it listens on.
+func (l *RedirectListener) RedirectURL() string {
+	return l.url.String()
+}
+
+// Wait waits for the redirect and returns the authorization code. It returns
+// an error if the state of the redirect does not match state, if the redirect
+// contains an error, in which case it is an *AuthError, or if ctx is done
+// first.
+func (l *RedirectListener) Wait(ctx context.Context, state string) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case q := <-l.redirects:
+		if err := authErrorFromQuery(q); err != nil {
+			return "", err
+		}
+		if q.Get("state") != state {
+			return "", errors.New("malauth: redirect state does not match")
+		}
+		code := q.Get("code")
+		if code == "" {
+			return "", errors.New("malauth: redirect is missing the authorization code")
+		}
+		return code, nil
+	}
+}
+
+// Close stops the listener.
+func (l *RedirectListener) Close() error {
+	return l.srv.Close()
+}
+
+func (l *RedirectListener) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet || r.URL.Path != l.url.Path {
+		http.NotFound(w, r)
+		return
+	}
+	q := r.URL.Query()
+	select {
+	case l.redirects <- q:
+	default:
+		// A redirect has already been received.
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if q.Get("error") != "" {
+		fmt.Fprint(w, redirectPage("Authorization failed. You can close this window."))
+		return
+	}
+	fmt.Fprint(w, redirectPage("Authorization complete. You can close this window."))
+}
+
+func redirectPage(msg string) string {
+	return "<!DOCTYPE html><html><head><title>MyAnimeList authorization</title></head><body><p>" + msg + "</p></body></html>\n"
+}
@@ -0,0 +1,121 @@
+package malauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListenRedirectInvalidURL(t *testing.T) {
+	for _, u := range []string{
+		"https://localhost:8080/callback",
+		"http://example.com:8080/callback",
+		"http://192.168.1.1:8080/callback",
+		"://bad",
+	} {
+		if l, err := ListenRedirect(u); err == nil {
+			l.Close()
+			t.Errorf("ListenRedirect(%q) expected error", u)
+		}
+	}
+}
+
+// redirect sends a request to the listener like a browser being redirected.
+func redirect(t *testing.T, l *RedirectListener, rawQuery string) int {
+	t.Helper()
+	resp, err := http.Get(l.RedirectURL() + "?" + rawQuery)
+	if err != nil {
+		t.Fatalf("redirect returned error: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func listen(t *testing.T) *RedirectListener {
+	t.Helper()
+	l, err := ListenRedirect("http://127.0.0.1:0/callback")
+	if err != nil {
+		t.Fatalf("ListenRedirect returned error: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func TestRedirectListenerWait(t *testing.T) {
+	l := listen(t)
+	if !strings.HasPrefix(l.RedirectURL(), "http://127.0.0.1:") || strings.HasPrefix(l.RedirectURL(), "http://127.0.0.1:0/") {
+		t.Errorf("RedirectURL = %q, want the actual port", l.RedirectURL())
+	}
+	resp, err := http.Get(strings.TrimSuffix(l.RedirectURL(), "/callback") + "/favicon.ico")
+	if err != nil {
+		t.Fatalf("request returned error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("other path returned status %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+
+	if status := redirect(t, l, "code=abc&state=st"); status != http.StatusOK {
+		t.Errorf("redirect returned status %d, want %d", status, http.StatusOK)
+	}
+	code, err := l.Wait(context.Background(), "st")
+	if err != nil {
+		t.Fatalf("Wait returned error: %v", err)
+	}
+	if code != "abc" {
+		t.Errorf("Wait returned code %q, want %q", code, "abc")
+	}
+}
+
+func TestRedirectListenerWaitErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawQuery string
+		check    func(error) bool
+	}{
+		{
+			name:     "state mismatch",
+			rawQuery: "code=abc&state=other",
+			check:    func(err error) bool { return err != nil && strings.Contains(err.Error(), "state") },
+		},
+		{
+			name:     "missing code",
+			rawQuery: "state=st",
+			check:    func(err error) bool { return err != nil && strings.Contains(err.Error(), "code") },
+		},
+		{
+			name:     "access denied",
+			rawQuery: "error=access_denied&error_description=denied&state=st",
+			check:    func(err error) bool { return errors.Is(err, ErrAccessDenied) },
+		},
+		{
+			name:     "other error",
+			rawQuery: "error=invalid_request&state=st",
+			check: func(err error) bool {
+				var aerr *AuthError
+				return errors.As(err, &aerr) && aerr.Code == "invalid_request" && !errors.Is(err, ErrAccessDenied)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := listen(t)
+			redirect(t, l, tt.rawQuery)
+			if _, err := l.Wait(context.Background(), "st"); !tt.check(err) {
+				t.Errorf("Wait returned unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRedirectListenerWaitContext(t *testing.T) {
+	l := listen(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Wait(ctx, "st"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait returned err = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
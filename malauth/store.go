@@ -0,0 +1,215 @@
+package malauth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrTokenNotFound is returned by a TokenStore when there is no token stored
+// for a key.
+var ErrTokenNotFound = errors.New("malauth: token not found")
+
+// TokenStore persists OAuth2 tokens by key, for example a user ID, so that
+// users do not need to authenticate every time the application starts.
+// Implementations must be safe for concurrent use.
+type TokenStore interface {
+	// Load returns the token stored for key or ErrTokenNotFound.
+	Load(key string) (*oauth2.Token, error)
+	// Save stores the token for key, replacing any previous token.
+	Save(key string, token *oauth2.Token) error
+	// Delete removes the token stored for key. Deleting a missing token is
+	// not an error.
+	Delete(key string) error
+}
+
+// MemoryTokenStore is a TokenStore which keeps the tokens in memory. It is
+// mostly useful for tests and short-lived processes.
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]oauth2.Token
+}
+
+// NewMemoryTokenStore returns an empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string]oauth2.Token)}
+}
+
+// Load returns a copy of the token stored for key.
+func (s *MemoryTokenStore) Load(key string) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tokens[key]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return &t, nil
+}
+
+// Save stores a copy of token for key.
+func (s *MemoryTokenStore) Save(key string, token *oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[key] = *token
+	return nil
+}
+
+// Delete removes the token stored for key.
+func (s *MemoryTokenStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, key)
+	return nil
+}
+
+// FileTokenStore is a TokenStore which stores each token as JSON in a file of
+// a directory. The files are only readable by the current user and they are
+// replaced atomically so that a crash while saving never leaves a corrupted
+// token behind.
+type FileTokenStore struct {
+	dir  string
+	aead cipher.AEAD // nil if the files are not encrypted.
+
+	// mu serializes saves and deletes so that the last save wins.
+	mu sync.Mutex
+}
+
+// NewFileTokenStore returns a FileTokenStore which stores the tokens in dir.
+// The directory is created if it does not exist.
+func NewFileTokenStore(dir string) (*FileTokenStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("malauth: creating token store directory: %w", err)
+	}
+	return &FileTokenStore{dir: dir}, nil
+}
+
+// NewEncryptedFileTokenStore returns a FileTokenStore which encrypts the tokens
+// with AES-GCM before writing them to dir. The key must be 16, 24 or 32 bytes
+// long to select AES-128, AES-192 or AES-256 and it must be kept outside of
+// dir, for example in an environment variable or a secret manager.
+func NewEncryptedFileTokenStore(dir string, key []byte) (*FileTokenStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("malauth: creating token cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("malauth: creating token cipher: %w", err)
+	}
+	s, err := NewFileTokenStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	s.aead = aead
+	return s, nil
+}
+
+// path returns the path of the file of key. Keys are hashed so that any key
+// is a valid file name.
+func (s *FileTokenStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Load reads the token stored for key.
+func (s *FileTokenStore) Load(key string) (*oauth2.Token, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("malauth: reading token: %w", err)
+	}
+	if s.aead != nil {
+		if data, err = s.open(data, key); err != nil {
+			return nil, err
+		}
+	}
+	t := new(oauth2.Token)
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("malauth: decoding token: %w", err)
+	}
+	return t, nil
+}
+
+// Save writes the token for key to a temporary file first and then renames it
+// over the previous token.
+func (s *FileTokenStore) Save(key string, token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("malauth: encoding token: %w", err)
+	}
+	if s.aead != nil {
+		if data, err = s.seal(data, key); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// CreateTemp creates the file with mode 0600.
+	f, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("malauth: saving token: %w", err)
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path(key))
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("malauth: saving token: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the file of the token stored for key.
+func (s *FileTokenStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("malauth: deleting token: %w", err)
+	}
+	return nil
+}
+
+// seal encrypts data and prepends the random nonce. The key of the token is
+// used as additional data so that a file cannot be swapped with the file of
+// another key.
+func (s *FileTokenStore) seal(data []byte, key string) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("malauth: encrypting token: %w", err)
+	}
+	return s.aead.Seal(nonce, nonce, data, []byte(key)), nil
+}
+
+// open decrypts data sealed by seal.
+func (s *FileTokenStore) open(data []byte, key string) ([]byte, error) {
+	n := s.aead.NonceSize()
+	if len(data) < n {
+		return nil, errors.New("malauth: decrypting token: file is too short")
+	}
+	plain, err := s.aead.Open(nil, data[:n], data[n:], []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("malauth: decrypting token: %w", err)
+	}
+	return plain, nil
+}
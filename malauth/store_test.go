@@ -0,0 +1,145 @@
+package malauth
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func testToken(access string) *oauth2.Token {
+	return &oauth2.Token{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		RefreshToken: "refresh-" + access,
+		Expiry:       time.Date(2021, 6, 1, 16, 12, 56, 0, time.UTC),
+	}
+}
+
+func TestTokenStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) TokenStore{
+		"memory": func(t *testing.T) TokenStore { return NewMemoryTokenStore() },
+		"file": func(t *testing.T) TokenStore {
+			s, err := NewFileTokenStore(filepath.Join(t.TempDir(), "tokens"))
+			if err != nil {
+				t.Fatalf("NewFileTokenStore returned error: %v", err)
+			}
+			return s
+		},
+		"encrypted file": func(t *testing.T) TokenStore {
+			s, err := NewEncryptedFileTokenStore(t.TempDir(), make([]byte, 32))
+			if err != nil {
+				t.Fatalf("NewEncryptedFileTokenStore returned error: %v", err)
+			}
+			return s
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			if _, err := s.Load("alice"); !errors.Is(err, ErrTokenNotFound) {
+				t.Errorf("Load of missing token returned err = %v, want %v", err, ErrTokenNotFound)
+			}
+			for _, access := range []string{"a1", "a2"} {
+				if err := s.Save("alice", testToken(access)); err != nil {
+					t.Fatalf("Save returned error: %v", err)
+				}
+			}
+			if err := s.Save("bob", testToken("b1")); err != nil {
+				t.Fatalf("Save returned error: %v", err)
+			}
+			got, err := s.Load("alice")
+			if err != nil {
+				t.Fatalf("Load returned error: %v", err)
+			}
+			if want := testToken("a2"); !reflect.DeepEqual(got, want) {
+				t.Errorf("Load returned %+v, want %+v", got, want)
+			}
+			if err := s.Delete("alice"); err != nil {
+				t.Fatalf("Delete returned error: %v", err)
+			}
+			if err := s.Delete("alice"); err != nil {
+				t.Errorf("Delete of missing token returned error: %v", err)
+			}
+			if _, err := s.Load("alice"); !errors.Is(err, ErrTokenNotFound) {
+				t.Errorf("Load of deleted token returned err = %v, want %v", err, ErrTokenNotFound)
+			}
+			if got, err := s.Load("bob"); err != nil || got.AccessToken != "b1" {
+				t.Errorf("Load(bob) = %+v, %v, want token b1", got, err)
+			}
+		})
+	}
+}
+
+func TestFileTokenStoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileTokenStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileTokenStore returned error: %v", err)
+	}
+	if err := s.Save("alice", testToken("a1")); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("token store directory has %d files, want 1 without temporary files", len(entries))
+	}
+	if runtime.GOOS == "windows" {
+		return
+	}
+	info, err := entries[0].Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("token file has mode %v, want %v", perm, os.FileMode(0o600))
+	}
+}
+
+func TestEncryptedFileTokenStore(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{1}, 32)
+	s, err := NewEncryptedFileTokenStore(dir, key)
+	if err != nil {
+		t.Fatalf("NewEncryptedFileTokenStore returned error: %v", err)
+	}
+	if err := s.Save("alice", testToken("secret-access")); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	if err := s.Save("bob", testToken("b1")); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	data, err := os.ReadFile(s.path("alice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("secret-access")) {
+		t.Errorf("encrypted token file contains the access token")
+	}
+
+	other, _ := NewEncryptedFileTokenStore(dir, bytes.Repeat([]byte{2}, 32))
+	if _, err := other.Load("alice"); err == nil {
+		t.Errorf("Load with a different key expected error")
+	}
+
+	// A file moved to another key must not decrypt.
+	if err := os.Rename(s.path("alice"), s.path("bob")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Load("bob"); err == nil {
+		t.Errorf("Load of swapped token file expected error")
+	}
+
+	if _, err := NewEncryptedFileTokenStore(dir, []byte("short")); err == nil {
+		t.Errorf("NewEncryptedFileTokenStore with invalid key length expected error")
+	}
+}
@@ -0,0 +1,81 @@
+package malauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// StoreTokenSource returns a TokenSource which returns the tokens of src and
+// saves them to store under key as soon as they change, that is when src
+// refreshes the token. The token t is the token that is already stored, if
+// any, and it is not saved again.
+//
+// If saving fails, Token returns the error so that a rotated refresh token is
+// never silently lost. Saving is retried on the next call.
+func StoreTokenSource(store TokenStore, key string, t *oauth2.Token, src oauth2.TokenSource) oauth2.TokenSource {
+	return &storeTokenSource{store: store, key: key, last: t, src: src}
+}
+
+type storeTokenSource struct {
+	store TokenStore
+	key   string
+	src   oauth2.TokenSource
+
+	mu   sync.Mutex
+	last *oauth2.Token // The last token that was saved.
+}
+
+// Token implements oauth2.TokenSource.
+func (s *storeTokenSource) Token() (*oauth2.Token, error) {
+	t, err := s.src.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sameToken(s.last, t) {
+		return t, nil
+	}
+	if err := s.store.Save(s.key, t); err != nil {
+		return nil, fmt.Errorf("malauth: saving refreshed token: %w", err)
+	}
+	s.last = t
+	return t, nil
+}
+
+func sameToken(t1, t2 *oauth2.Token) bool {
+	if t1 == nil || t2 == nil {
+		return t1 == t2
+	}
+	return t1.AccessToken == t2.AccessToken &&
+		t1.RefreshToken == t2.RefreshToken &&
+		t1.Expiry.Equal(t2.Expiry)
+}
+
+// NewTokenSource loads the token stored for key and returns a TokenSource which
+// refreshes it using conf and saves every refreshed token back to store. It
+// returns ErrTokenNotFound if no token is stored for key, in which case the user
+// needs to authenticate first.
+func NewTokenSource(ctx context.Context, conf *oauth2.Config, store TokenStore, key string) (oauth2.TokenSource, error) {
+	t, err := store.Load(key)
+	if err != nil {
+		return nil, err
+	}
+	return StoreTokenSource(store, key, t, conf.TokenSource(ctx, t)), nil
+}
+
+// NewClient returns an HTTP client which authenticates its requests with the
+// token stored for key, refreshing it when it expires and saving the refreshed
+// token to store. The client can be passed to mal.NewClient for long-running
+// use. It returns ErrTokenNotFound if no token is stored for key.
+func NewClient(ctx context.Context, conf *oauth2.Config, store TokenStore, key string) (*http.Client, error) {
+	ts, err := NewTokenSource(ctx, conf, store, key)
+	if err != nil {
+		return nil, err
+	}
+	return oauth2.NewClient(ctx, oauth2.ReuseTokenSource(nil, ts)), nil
+}
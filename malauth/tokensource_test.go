@@ -0,0 +1,116 @@
+package malauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// sequenceTokenSource returns its tokens in order, repeating the last one.
+type sequenceTokenSource struct {
+	tokens []*oauth2.Token
+}
+
+func (s *sequenceTokenSource) Token() (*oauth2.Token, error) {
+	t := s.tokens[0]
+	if len(s.tokens) > 1 {
+		s.tokens = s.tokens[1:]
+	}
+	return t, nil
+}
+
+// countingStore counts the saves and can be made to fail.
+type countingStore struct {
+	TokenStore
+	saves int
+	err   error
+}
+
+func (s *countingStore) Save(key string, token *oauth2.Token) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.saves++
+	return s.TokenStore.Save(key, token)
+}
+
+func TestStoreTokenSource(t *testing.T) {
+	store := &countingStore{TokenStore: NewMemoryTokenStore()}
+	initial := testToken("a1")
+	src := &sequenceTokenSource{tokens: []*oauth2.Token{initial, testToken("a1"), testToken("a2"), testToken("a2")}}
+	ts := StoreTokenSource(store, "alice", initial, src)
+
+	for i := 0; i < 2; i++ {
+		if _, err := ts.Token(); err != nil {
+			t.Fatalf("Token returned error: %v", err)
+		}
+	}
+	if store.saves != 0 {
+		t.Errorf("unchanged token was saved %d times", store.saves)
+	}
+
+	// The refreshed token fails to save and is saved on the next call.
+	store.err = errors.New("disk full")
+	if _, err := ts.Token(); !errors.Is(err, store.err) {
+		t.Errorf("Token returned err = %v, want %v", err, store.err)
+	}
+	store.err = nil
+	tok, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token returned error: %v", err)
+	}
+	if tok.AccessToken != "a2" || store.saves != 1 {
+		t.Errorf("Token returned %q with %d saves, want a2 with 1 save", tok.AccessToken, store.saves)
+	}
+	if saved, _ := store.Load("alice"); saved == nil || saved.AccessToken != "a2" {
+		t.Errorf("stored token = %+v, want a2", saved)
+	}
+}
+
+func TestNewClientSavesRefreshedToken(t *testing.T) {
+	ts := newTokenServer(t)
+	var gotAuth string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer api.Close()
+
+	conf := NewConfig("id", "secret", "")
+	conf.Endpoint.TokenURL = ts.URL
+	store := NewMemoryTokenStore()
+	expired := testToken("old")
+	expired.Expiry = time.Now().Add(-time.Hour)
+	if err := store.Save("alice", expired); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := NewClient(ctx, conf, store, "bob"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("NewClient for missing token returned err = %v, want %v", err, ErrTokenNotFound)
+	}
+	client, err := NewClient(ctx, conf, store, "alice")
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+	resp, err := client.Get(api.URL)
+	if err != nil {
+		t.Fatalf("request returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if want := "Bearer access"; gotAuth != want {
+		t.Errorf("request Authorization = %q, want %q", gotAuth, want)
+	}
+	saved, err := store.Load("alice")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if saved.AccessToken != "access" || saved.RefreshToken != "refresh" {
+		t.Errorf("stored token = %+v, want the refreshed token", saved)
+	}
+}
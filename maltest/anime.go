@@ -0,0 +1,251 @@
+package maltest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// animeObject returns the JSON object of an anime as seen by user u, filtered
+// by the requested fields.
+func (s *Server) animeObject(a mal.Anime, u *user, fs fieldSet) object {
+	o := toObject(a)
+	delete(o, "my_list_status")
+	if u != nil {
+		if status, ok := u.animeList[a.ID]; ok {
+			o["my_list_status"] = toObject(status)
+		}
+	}
+	return filterObject(o, fs, defaultNodeFields)
+}
+
+// sortedAnime returns the anime of the catalog in ascending ID order, keeping
+// those for which keep returns true. NSFW anime are only kept if nsfw is
+// true.
+func (s *Server) sortedAnime(nsfw bool, keep func(a mal.Anime) bool) []mal.Anime {
+	var anime []mal.Anime
+	for _, a := range s.anime {
+		if !nsfw && a.NSFW == "black" {
+			continue
+		}
+		if keep(a) {
+			anime = append(anime, a)
+		}
+	}
+	sort.Slice(anime, func(i, j int) bool { return anime[i].ID < anime[j].ID })
+	return anime
+}
+
+// animePage returns the requested page of anime wrapped in nodes.
+func (s *Server) animePage(r *request, anime []mal.Anime) (*page, *apiError) {
+	fs := r.fields()
+	objects := make([]object, len(anime))
+	for i, a := range anime {
+		objects[i] = s.animeObject(a, r.user, fs)
+	}
+	return paginate(r, nodes(objects))
+}
+
+func (s *Server) animeList(r *request) (interface{}, *apiError) {
+	q, e := searchQuery(r)
+	if e != nil {
+		return nil, e
+	}
+	anime := s.sortedAnime(r.nsfw(), func(a mal.Anime) bool {
+		return matchesQuery(q, a.Title, a.AlternativeTitles)
+	})
+	return s.animePage(r, anime)
+}
+
+func (s *Server) animeDetails(r *request, idStr string) (interface{}, *apiError) {
+	id, e := parseID(idStr)
+	if e != nil {
+		return nil, e
+	}
+	a, ok := s.anime[id]
+	if !ok {
+		return nil, errNotFound()
+	}
+	return s.animeObject(a, r.user, r.fields()), nil
+}
+
+// animeRankings maps the anime ranking types to the media types they include.
+var animeRankings = map[string]string{
+	"all":          "",
+	"airing":       "",
+	"upcoming":     "",
+	"tv":           "tv",
+	"ova":          "ova",
+	"movie":        "movie",
+	"special":      "special",
+	"bypopularity": "",
+	"favorite":     "",
+}
+
+// animeRanking ranks the anime by their Rank. Anime with a zero Rank are not
+// ranked. The bypopularity and favorite ranking types rank by Popularity
+// instead.
+func (s *Server) animeRanking(r *request) (interface{}, *apiError) {
+	rankingType := r.query("ranking_type")
+	mediaType, ok := animeRankings[rankingType]
+	if !ok {
+		return nil, errInvalidParameters(fmt.Sprintf("invalid ranking_type %q", rankingType))
+	}
+	byPopularity := rankingType == "bypopularity" || rankingType == "favorite"
+	rank := func(a mal.Anime) int {
+		if byPopularity {
+			return a.Popularity
+		}
+		return a.Rank
+	}
+	anime := s.sortedAnime(r.nsfw(), func(a mal.Anime) bool {
+		switch {
+		case rank(a) <= 0:
+			return false
+		case rankingType == "airing":
+			return a.Status == "currently_airing"
+		case rankingType == "upcoming":
+			return a.Status == "not_yet_aired"
+		case mediaType != "":
+			return string(a.MediaType) == mediaType
+		}
+		return true
+	})
+	sort.SliceStable(anime, func(i, j int) bool { return rank(anime[i]) < rank(anime[j]) })
+	p, e := s.animePage(r, anime)
+	if e != nil {
+		return nil, e
+	}
+	_, offset, _ := pageParams(r)
+	addRanking(p, offset)
+	return p, nil
+}
+
+// addRanking adds the position of each entry of a ranking page.
+func addRanking(p *page, offset int) {
+	for i, item := range p.Data {
+		item.(object)["ranking"] = object{"rank": offset + i + 1}
+	}
+}
+
+var animeSeasons = map[string]bool{"winter": true, "spring": true, "summer": true, "fall": true}
+
+func (s *Server) animeSeasonal(r *request, yearStr, season string) (interface{}, *apiError) {
+	year, e := parseID(yearStr)
+	if e != nil {
+		return nil, e
+	}
+	if !animeSeasons[season] {
+		return nil, errInvalidParameters(fmt.Sprintf("invalid season %q", season))
+	}
+	anime := s.sortedAnime(r.nsfw(), func(a mal.Anime) bool {
+		return a.StartSeason.Year == year && a.StartSeason.Season == season
+	})
+	switch sortBy := r.query("sort"); sortBy {
+	case "":
+	case "anime_score":
+		sort.SliceStable(anime, func(i, j int) bool { return anime[i].Mean > anime[j].Mean })
+	case "anime_num_list_users":
+		sort.SliceStable(anime, func(i, j int) bool { return anime[i].NumListUsers > anime[j].NumListUsers })
+	default:
+		return nil, errInvalidParameters(fmt.Sprintf("invalid sort %q", sortBy))
+	}
+	p, e := s.animePage(r, anime)
+	if e != nil {
+		return nil, e
+	}
+	return struct {
+		*page
+		Season mal.StartSeason `json:"season"`
+	}{p, mal.StartSeason{Year: year, Season: season}}, nil
+}
+
+func (s *Server) animeSuggestions(r *request) (interface{}, *apiError) {
+	u, e := r.me()
+	if e != nil {
+		return nil, e
+	}
+	var anime []mal.Anime
+	for _, id := range u.suggestions {
+		a, ok := s.anime[id]
+		if !ok || (!r.nsfw() && a.NSFW == "black") {
+			continue
+		}
+		anime = append(anime, a)
+	}
+	return s.animePage(r, anime)
+}
+
+// updateMyAnimeListStatus adds or updates an anime in the list of the
+// authenticated user. New entries without a status are added as
+// plan_to_watch.
+func (s *Server) updateMyAnimeListStatus(r *request, idStr string) (interface{}, *apiError) {
+	u, e := r.me()
+	if e != nil {
+		return nil, e
+	}
+	id, e := parseID(idStr)
+	if e != nil {
+		return nil, e
+	}
+	if _, ok := s.anime[id]; !ok {
+		return nil, errNotFound()
+	}
+	if err := r.ParseForm(); err != nil {
+		return nil, errInvalidParameters(err.Error())
+	}
+	status, ok := u.animeList[id]
+	if !ok {
+		status.Status = mal.AnimeStatusPlanToWatch
+	}
+	f := formParser{form: r.PostForm}
+	if f.has("status") {
+		v := f.form.Get("status")
+		if !animeStatuses[v] {
+			return nil, errInvalidParameters(fmt.Sprintf("invalid status %q", v))
+		}
+		status.Status = mal.AnimeStatus(v)
+	}
+	f.int("score", 0, 10, &status.Score)
+	f.int("num_watched_episodes", 0, -1, &status.NumEpisodesWatched)
+	f.bool("is_rewatching", &status.IsRewatching)
+	f.int("priority", 0, 2, &status.Priority)
+	f.int("num_times_rewatched", 0, -1, &status.NumTimesRewatched)
+	f.int("rewatch_value", 0, 5, &status.RewatchValue)
+	f.tags("tags", &status.Tags)
+	f.string("comments", &status.Comments)
+	f.date("start_date", &status.StartDate)
+	f.date("finish_date", &status.FinishDate)
+	if f.err != nil {
+		return nil, f.err
+	}
+	status.UpdatedAt = s.now().UTC().Truncate(time.Second)
+	u.animeList[id] = status
+	return status, nil
+}
+
+var animeStatuses = map[string]bool{
+	"watching":      true,
+	"completed":     true,
+	"on_hold":       true,
+	"dropped":       true,
+	"plan_to_watch": true,
+}
+
+func (s *Server) deleteMyAnimeListItem(r *request, idStr string) (interface{}, *apiError) {
+	u, e := r.me()
+	if e != nil {
+		return nil, e
+	}
+	id, e := parseID(idStr)
+	if e != nil {
+		return nil, e
+	}
+	if _, ok := u.animeList[id]; !ok {
+		return nil, errNotFound()
+	}
+	delete(u.animeList, id)
+	return []interface{}{}, nil
+}
@@ -0,0 +1,144 @@
+package maltest
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// fieldSet is a parsed fields query parameter. Each field maps to its
+// subfields, which are nil if none were requested, e.g.
+// "title,my_list_status{score}" is parsed as:
+//
+//	fieldSet{"title": nil, "my_list_status": fieldSet{"score": nil}}
+type fieldSet map[string]fieldSet
+
+// parseFields parses the value of a fields query parameter.
+func parseFields(s string) fieldSet {
+	fs := fieldSet{}
+	for _, f := range splitFields(s) {
+		name, sub := f, ""
+		if i := strings.Index(f, "{"); i != -1 {
+			name, sub = f[:i], strings.TrimSuffix(f[i+1:], "}")
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if sub != "" {
+			fs[name] = parseFields(sub)
+		} else if _, ok := fs[name]; !ok {
+			fs[name] = nil
+		}
+	}
+	return fs
+}
+
+// splitFields splits s at the commas that are not inside braces.
+func splitFields(s string) []string {
+	var (
+		fields []string
+		depth  int
+		start  int
+	)
+	for i, r := range s {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				fields = append(fields, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(fields, s[start:])
+}
+
+// without returns a copy of fs without the field name.
+func (fs fieldSet) without(name string) fieldSet {
+	out := make(fieldSet, len(fs))
+	for k, v := range fs {
+		if k != name {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// object is a JSON object as decoded by encoding/json.
+type object = map[string]interface{}
+
+// toObject converts v, which must encode to a JSON object, to an object.
+func toObject(v interface{}) object {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic("maltest: " + err.Error())
+	}
+	o := object{}
+	if err := json.Unmarshal(data, &o); err != nil {
+		panic("maltest: " + err.Error())
+	}
+	return o
+}
+
+// Default fields returned by the API when no fields are requested.
+var (
+	defaultNodeFields            = []string{"id", "title", "main_picture"}
+	defaultUserFields            = []string{"id", "name", "picture", "location", "joined_at"}
+	defaultAnimeListStatusFields = []string{"status", "score", "num_episodes_watched", "is_rewatching", "updated_at", "start_date", "finish_date"}
+	defaultMangaListStatusFields = []string{"status", "is_rereading", "num_volumes_read", "num_chapters_read", "score", "updated_at", "start_date", "finish_date"}
+)
+
+// filterObject returns the default fields of o and the fields of fs which are
+// present in o. Nested fields are filtered using their subfields.
+func filterObject(o object, fs fieldSet, defaults []string) object {
+	out := object{}
+	for _, k := range defaults {
+		if v, ok := o[k]; ok {
+			out[k] = v
+		}
+	}
+	for k, sub := range fs {
+		if v, ok := o[k]; ok {
+			out[k] = filterValue(v, sub)
+		}
+	}
+	return out
+}
+
+// filterValue filters a nested field by its subfields, if any were requested.
+// In arrays of nodes, such as related_anime, the subfields apply to each node
+// in addition to the default fields.
+func filterValue(v interface{}, sub fieldSet) interface{} {
+	if sub == nil {
+		return v
+	}
+	switch v := v.(type) {
+	case object:
+		return filterObject(v, sub, nil)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			o, ok := e.(object)
+			if !ok {
+				out[i] = e
+				continue
+			}
+			node, ok := o["node"].(object)
+			if !ok {
+				out[i] = filterValue(o, sub)
+				continue
+			}
+			c := object{}
+			for k, v := range o {
+				c[k] = v
+			}
+			c["node"] = filterObject(node, sub, defaultNodeFields)
+			out[i] = c
+		}
+		return out
+	}
+	return v
+}
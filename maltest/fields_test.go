@@ -0,0 +1,53 @@
+package maltest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		in   string
+		want fieldSet
+	}{
+		{in: "", want: fieldSet{}},
+		{in: "title,synopsis", want: fieldSet{"title": nil, "synopsis": nil}},
+		{
+			in:   "my_list_status{score,tags},related_anime{media_type}",
+			want: fieldSet{"my_list_status": {"score": nil, "tags": nil}, "related_anime": {"media_type": nil}},
+		},
+		{in: "list_status{start_date{year}}", want: fieldSet{"list_status": {"start_date": {"year": nil}}}},
+	}
+	for _, tt := range tests {
+		if got := parseFields(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseFields(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFilterObject(t *testing.T) {
+	o := object{
+		"id":       1.0,
+		"title":    "Monster",
+		"synopsis": "A doctor.",
+		"my_list_status": object{
+			"status": "reading",
+			"score":  9.0,
+		},
+		"related_manga": []interface{}{
+			object{"node": object{"id": 2.0, "title": "Another Monster", "num_volumes": 1.0}, "relation_type": "side_story"},
+		},
+	}
+	fs := parseFields("my_list_status{score},related_manga{num_volumes},missing")
+	want := object{
+		"id":             1.0,
+		"title":          "Monster",
+		"my_list_status": object{"score": 9.0},
+		"related_manga": []interface{}{
+			object{"node": object{"id": 2.0, "title": "Another Monster", "num_volumes": 1.0}, "relation_type": "side_story"},
+		},
+	}
+	if got := filterObject(o, fs, defaultNodeFields); !reflect.DeepEqual(got, want) {
+		t.Errorf("filterObject\nhave: %v\nwant: %v", got, want)
+	}
+}
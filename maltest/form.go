@@ -0,0 +1,91 @@
+package maltest
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// formParser parses the form of an update request, keeping the first error.
+type formParser struct {
+	form url.Values
+	err  *apiError
+}
+
+func (f *formParser) has(key string) bool {
+	_, ok := f.form[key]
+	return ok
+}
+
+func (f *formParser) fail(key, value string) {
+	if f.err == nil {
+		f.err = errInvalidParameters(fmt.Sprintf("invalid %s %q", key, value))
+	}
+}
+
+// int parses an integer between min and max. A negative max means there is no
+// maximum.
+func (f *formParser) int(key string, min, max int, dst *int) {
+	if !f.has(key) {
+		return
+	}
+	v := f.form.Get(key)
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min || (max >= 0 && n > max) {
+		f.fail(key, v)
+		return
+	}
+	*dst = n
+}
+
+func (f *formParser) bool(key string, dst *bool) {
+	if !f.has(key) {
+		return
+	}
+	switch v := f.form.Get(key); v {
+	case "true", "1":
+		*dst = true
+	case "false", "0":
+		*dst = false
+	default:
+		f.fail(key, v)
+	}
+}
+
+func (f *formParser) string(key string, dst *string) {
+	if f.has(key) {
+		*dst = f.form.Get(key)
+	}
+}
+
+// tags parses comma-separated tags. An empty value clears the tags.
+func (f *formParser) tags(key string, dst *[]string) {
+	if !f.has(key) {
+		return
+	}
+	var tags []string
+	for _, t := range strings.Split(f.form.Get(key), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	*dst = tags
+}
+
+// date parses a date in one of the formats 2006, 2006-01 or 2006-01-02. An
+// empty value clears the date.
+func (f *formParser) date(key string, dst *mal.Date) {
+	if !f.has(key) {
+		return
+	}
+	v := f.form.Get(key)
+	d, err := mal.ParseDate(v)
+	if err != nil {
+		f.fail(key, v)
+		return
+	}
+	*dst = d
+}
@@ -0,0 +1,104 @@
+package maltest
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// forumTopics searches the topics of the forum. At least one of the q,
+// board_id, subboard_id, user_name and topic_user_name query parameters is
+// required. The topics are sorted by their last post, most recent first.
+func (s *Server) forumTopics(r *request) (interface{}, *apiError) {
+	q := strings.ToLower(r.query("q"))
+	userName := strings.ToLower(r.query("user_name"))
+	topicUserName := strings.ToLower(r.query("topic_user_name"))
+	boardID, e := optionalID(r, "board_id")
+	if e != nil {
+		return nil, e
+	}
+	subboardID, e := optionalID(r, "subboard_id")
+	if e != nil {
+		return nil, e
+	}
+	if q == "" && userName == "" && topicUserName == "" && boardID == 0 && subboardID == 0 {
+		return nil, errInvalidParameters("q, board_id, subboard_id, user_name or topic_user_name is required")
+	}
+	if sortBy := r.query("sort"); sortBy != "" && sortBy != "recent" {
+		return nil, errInvalidParameters("invalid sort " + strconv.Quote(sortBy))
+	}
+
+	var topics []Topic
+	for _, t := range s.topics {
+		switch {
+		case q != "" && !strings.Contains(strings.ToLower(t.Title), q):
+		case boardID != 0 && t.BoardID != boardID:
+		case subboardID != 0 && t.SubboardID != subboardID:
+		case topicUserName != "" && strings.ToLower(t.CreatedBy.Name) != topicUserName:
+		case userName != "" && !postedBy(t, userName):
+		default:
+			topics = append(topics, t)
+		}
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		ti, tj := topics[i].LastPostCreatedAt, topics[j].LastPostCreatedAt
+		if ti.Equal(tj) {
+			return topics[i].ID > topics[j].ID
+		}
+		return ti.After(tj)
+	})
+	items := make([]interface{}, len(topics))
+	for i := range topics {
+		items[i] = topics[i].Topic
+	}
+	return paginate(r, items)
+}
+
+// postedBy reports whether the user with the lowercase name created the topic
+// or one of its posts.
+func postedBy(t Topic, name string) bool {
+	if strings.ToLower(t.CreatedBy.Name) == name {
+		return true
+	}
+	for _, p := range t.Posts {
+		if strings.ToLower(p.CreatedBy.Name) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func optionalID(r *request, key string) (int, *apiError) {
+	if r.query(key) == "" {
+		return 0, nil
+	}
+	return parseID(r.query(key))
+}
+
+// forumTopicDetails returns a page of the posts of a topic.
+func (s *Server) forumTopicDetails(r *request, idStr string) (interface{}, *apiError) {
+	id, e := parseID(idStr)
+	if e != nil {
+		return nil, e
+	}
+	t, ok := s.topics[id]
+	if !ok {
+		return nil, errNotFound()
+	}
+	posts := make([]interface{}, len(t.Posts))
+	for i := range t.Posts {
+		posts[i] = t.Posts[i]
+	}
+	p, e := paginate(r, posts)
+	if e != nil {
+		return nil, e
+	}
+	return object{
+		"data": object{
+			"title": t.Title,
+			"posts": p.Data,
+			"poll":  t.Poll,
+		},
+		"paging": p.Paging,
+	}, nil
+}
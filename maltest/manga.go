@@ -0,0 +1,187 @@
+package maltest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// mangaObject returns the JSON object of a manga as seen by user u, filtered
+// by the requested fields.
+func (s *Server) mangaObject(m mal.Manga, u *user, fs fieldSet) object {
+	o := toObject(m)
+	delete(o, "my_list_status")
+	if u != nil {
+		if status, ok := u.mangaList[m.ID]; ok {
+			o["my_list_status"] = toObject(status)
+		}
+	}
+	return filterObject(o, fs, defaultNodeFields)
+}
+
+// sortedManga returns the manga of the catalog in ascending ID order, keeping
+// those for which keep returns true. NSFW manga are only kept if nsfw is
+// true.
+func (s *Server) sortedManga(nsfw bool, keep func(m mal.Manga) bool) []mal.Manga {
+	var manga []mal.Manga
+	for _, m := range s.manga {
+		if !nsfw && m.Nsfw == "black" {
+			continue
+		}
+		if keep(m) {
+			manga = append(manga, m)
+		}
+	}
+	sort.Slice(manga, func(i, j int) bool { return manga[i].ID < manga[j].ID })
+	return manga
+}
+
+// mangaPage returns the requested page of manga wrapped in nodes.
+func (s *Server) mangaPage(r *request, manga []mal.Manga) (*page, *apiError) {
+	fs := r.fields()
+	objects := make([]object, len(manga))
+	for i, m := range manga {
+		objects[i] = s.mangaObject(m, r.user, fs)
+	}
+	return paginate(r, nodes(objects))
+}
+
+func (s *Server) mangaList(r *request) (interface{}, *apiError) {
+	q, e := searchQuery(r)
+	if e != nil {
+		return nil, e
+	}
+	manga := s.sortedManga(r.nsfw(), func(m mal.Manga) bool {
+		return matchesQuery(q, m.Title, m.AlternativeTitles)
+	})
+	return s.mangaPage(r, manga)
+}
+
+func (s *Server) mangaDetails(r *request, idStr string) (interface{}, *apiError) {
+	id, e := parseID(idStr)
+	if e != nil {
+		return nil, e
+	}
+	m, ok := s.manga[id]
+	if !ok {
+		return nil, errNotFound()
+	}
+	return s.mangaObject(m, r.user, r.fields()), nil
+}
+
+// mangaRankings maps the manga ranking types to the media types they include.
+var mangaRankings = map[string]string{
+	"all":          "",
+	"manga":        "manga",
+	"oneshots":     "one_shot",
+	"doujin":       "doujinshi",
+	"lightnovels":  "light_novel",
+	"novels":       "novel",
+	"manhwa":       "manhwa",
+	"manhua":       "manhua",
+	"bypopularity": "",
+	"favorite":     "",
+}
+
+// mangaRanking ranks the manga by their Rank. Manga with a zero Rank are not
+// ranked. The bypopularity and favorite ranking types rank by Popularity
+// instead.
+func (s *Server) mangaRanking(r *request) (interface{}, *apiError) {
+	rankingType := r.query("ranking_type")
+	mediaType, ok := mangaRankings[rankingType]
+	if !ok {
+		return nil, errInvalidParameters(fmt.Sprintf("invalid ranking_type %q", rankingType))
+	}
+	byPopularity := rankingType == "bypopularity" || rankingType == "favorite"
+	rank := func(m mal.Manga) int {
+		if byPopularity {
+			return m.Popularity
+		}
+		return m.Rank
+	}
+	manga := s.sortedManga(r.nsfw(), func(m mal.Manga) bool {
+		return rank(m) > 0 && (mediaType == "" || string(m.MediaType) == mediaType)
+	})
+	sort.SliceStable(manga, func(i, j int) bool { return rank(manga[i]) < rank(manga[j]) })
+	p, e := s.mangaPage(r, manga)
+	if e != nil {
+		return nil, e
+	}
+	_, offset, _ := pageParams(r)
+	addRanking(p, offset)
+	return p, nil
+}
+
+// updateMyMangaListStatus adds or updates a manga in the list of the
+// authenticated user. New entries without a status are added as plan_to_read.
+func (s *Server) updateMyMangaListStatus(r *request, idStr string) (interface{}, *apiError) {
+	u, e := r.me()
+	if e != nil {
+		return nil, e
+	}
+	id, e := parseID(idStr)
+	if e != nil {
+		return nil, e
+	}
+	if _, ok := s.manga[id]; !ok {
+		return nil, errNotFound()
+	}
+	if err := r.ParseForm(); err != nil {
+		return nil, errInvalidParameters(err.Error())
+	}
+	status, ok := u.mangaList[id]
+	if !ok {
+		status.Status = mal.MangaStatusPlanToRead
+	}
+	f := formParser{form: r.PostForm}
+	if f.has("status") {
+		v := f.form.Get("status")
+		if !mangaStatuses[v] {
+			return nil, errInvalidParameters(fmt.Sprintf("invalid status %q", v))
+		}
+		status.Status = mal.MangaStatus(v)
+	}
+	f.bool("is_rereading", &status.IsRereading)
+	f.int("score", 0, 10, &status.Score)
+	f.int("num_volumes_read", 0, -1, &status.NumVolumesRead)
+	f.int("num_chapters_read", 0, -1, &status.NumChaptersRead)
+	f.int("priority", 0, 2, &status.Priority)
+	f.int("num_times_reread", 0, -1, &status.NumTimesReread)
+	f.int("reread_value", 0, 5, &status.RereadValue)
+	f.tags("tags", &status.Tags)
+	f.string("comments", &status.Comments)
+	f.date("start_date", &status.StartDate)
+	f.date("finish_date", &status.FinishDate)
+	if f.err != nil {
+		return nil, f.err
+	}
+	status.UpdatedAt = s.now().UTC().Truncate(time.Second)
+	u.mangaList[id] = status
+	return status, nil
+}
+
+var mangaStatuses = map[string]bool{
+	"reading":      true,
+	"completed":    true,
+	"on_hold":      true,
+	"dropped":      true,
+	"plan_to_read": true,
+}
+
+func (s *Server) deleteMyMangaListItem(r *request, idStr string) (interface{}, *apiError) {
+	u, e := r.me()
+	if e != nil {
+		return nil, e
+	}
+	id, e := parseID(idStr)
+	if e != nil {
+		return nil, e
+	}
+	if _, ok := u.mangaList[id]; !ok {
+		return nil, errNotFound()
+	}
+	delete(u.mangaList, id)
+	return []interface{}{}, nil
+}
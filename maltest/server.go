@@ -0,0 +1,586 @@
+// Package maltest provides an in-memory fake of the MyAnimeList API v2 for
+// testing code that uses the mal package.
+//
+// A Server is seeded with anime, manga, users, list statuses and forum topics
+// and serves them like the real API does, including paging links, filtering
+// of the returned fields using the fields parameter and error responses:
+//
+//	srv := maltest.NewServer()
+//	defer srv.Close()
+//
+//	srv.AddAnime(mal.Anime{ID: 1, Title: "Cowboy Bebop", NumEpisodes: 26})
+//	srv.AddUser(mal.User{Name: "alice"})
+//
+//	c := srv.Client("alice")
+//	_, _, err := c.Anime.UpdateMyListStatus(ctx, 1, mal.AnimeStatusWatching)
+//	// ...
+//	status, _ := srv.AnimeListStatus("alice", 1)
+//
+// The server keeps the state of the user lists so updates and deletions are
+// visible in subsequent requests. Errors can be injected with FailNext.
+package maltest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/mal"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// apiPath is the path of the fake API, same as the real API.
+	apiPath = "/v2/"
+
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// Server is a fake MyAnimeList API server. It is safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the fake API, for use as mal.Client.BaseURL.
+	URL string
+
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	anime    map[int]mal.Anime
+	manga    map[int]mal.Manga
+	users    map[string]*user
+	tokens   map[string]string
+	forum    mal.Forum
+	topics   map[int]Topic
+	failures []*failure
+
+	// now returns the current time. It can be replaced in tests.
+	now func() time.Time
+}
+
+// user is a user of the server along with their lists.
+type user struct {
+	mal.User
+	animeList   map[int]mal.AnimeListStatus
+	mangaList   map[int]mal.MangaListStatus
+	suggestions []int
+}
+
+// Topic is a forum topic served by the Server along with the board it belongs
+// to and its posts.
+type Topic struct {
+	mal.Topic
+	BoardID    int
+	SubboardID int
+	Posts      []mal.Post
+	Poll       *mal.Poll
+}
+
+// failure is an error response injected with FailNext.
+type failure struct {
+	method, path string
+	remaining    int
+	status       int
+	err          string
+}
+
+// NewServer starts and returns a new Server with no data. The caller should
+// call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		anime:  make(map[int]mal.Anime),
+		manga:  make(map[int]mal.Manga),
+		users:  make(map[string]*user),
+		tokens: make(map[string]string),
+		topics: make(map[int]Topic),
+		now:    time.Now,
+	}
+	s.srv = httptest.NewServer(s)
+	s.URL = s.srv.URL + apiPath
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a mal.Client which sends requests to the server on behalf of
+// the user with the given name, as if it had been authenticated with OAuth2.
+// If username is empty, the client only accesses public information using a
+// client ID.
+func (s *Server) Client(username string, options ...mal.ClientOption) *mal.Client {
+	var httpClient *http.Client
+	if username != "" {
+		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: s.Token(username)})
+		httpClient = oauth2.NewClient(context.Background(), ts)
+	} else {
+		options = append([]mal.ClientOption{mal.WithClientID("maltest")}, options...)
+	}
+	c := mal.NewClient(httpClient, options...)
+	c.BaseURL, _ = url.Parse(s.URL)
+	return c
+}
+
+// Token returns an access token which authenticates requests on behalf of the
+// user with the given name. It is sent as a Bearer token in the Authorization
+// header.
+func (s *Server) Token(username string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token := "maltest-" + url.PathEscape(username)
+	s.tokens[token] = username
+	return token
+}
+
+// AddAnime adds anime to the catalog of the server, replacing any anime with
+// the same ID.
+func (s *Server) AddAnime(anime ...mal.Anime) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range anime {
+		s.anime[a.ID] = a
+	}
+}
+
+// AddManga adds manga to the catalog of the server, replacing any manga with
+// the same ID.
+func (s *Server) AddManga(manga ...mal.Manga) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range manga {
+		s.manga[m.ID] = m
+	}
+}
+
+// AddUser adds a user to the server or replaces the information of an existing
+// user with the same name. Users are looked up by name case-insensitively. If
+// the ID of the user is zero, one is assigned.
+func (s *Server) AddUser(u mal.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addUser(u)
+}
+
+func (s *Server) addUser(u mal.User) *user {
+	if u.ID == 0 {
+		u.ID = int64(len(s.users) + 1)
+	}
+	if existing, ok := s.users[strings.ToLower(u.Name)]; ok {
+		existing.User = u
+		return existing
+	}
+	usr := &user{
+		User:      u,
+		animeList: make(map[int]mal.AnimeListStatus),
+		mangaList: make(map[int]mal.MangaListStatus),
+	}
+	s.users[strings.ToLower(u.Name)] = usr
+	return usr
+}
+
+// user returns the user with the given name, adding it if it does not exist.
+func (s *Server) user(username string) *user {
+	if u, ok := s.users[strings.ToLower(username)]; ok {
+		return u
+	}
+	return s.addUser(mal.User{Name: username})
+}
+
+// SetAnimeListStatus sets the status of an anime in the list of a user. The
+// user is added if it does not exist.
+func (s *Server) SetAnimeListStatus(username string, animeID int, status mal.AnimeListStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user(username).animeList[animeID] = status
+}
+
+// AnimeListStatus returns the status of an anime in the list of a user and
+// whether the anime is in the list.
+func (s *Server) AnimeListStatus(username string, animeID int) (mal.AnimeListStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[strings.ToLower(username)]
+	if !ok {
+		return mal.AnimeListStatus{}, false
+	}
+	status, ok := u.animeList[animeID]
+	return status, ok
+}
+
+// SetMangaListStatus sets the status of a manga in the list of a user. The
+// user is added if it does not exist.
+func (s *Server) SetMangaListStatus(username string, mangaID int, status mal.MangaListStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user(username).mangaList[mangaID] = status
+}
+
+// MangaListStatus returns the status of a manga in the list of a user and
+// whether the manga is in the list.
+func (s *Server) MangaListStatus(username string, mangaID int) (mal.MangaListStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[strings.ToLower(username)]
+	if !ok {
+		return mal.MangaListStatus{}, false
+	}
+	status, ok := u.mangaList[mangaID]
+	return status, ok
+}
+
+// SetSuggestions sets the anime suggested to a user, in order. The user is
+// added if it does not exist.
+func (s *Server) SetSuggestions(username string, animeIDs ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user(username).suggestions = animeIDs
+}
+
+// SetBoards sets the forum boards returned by the server.
+func (s *Server) SetBoards(f mal.Forum) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.forum = f
+}
+
+// AddTopic adds topics to the forum of the server, replacing any topic with
+// the same ID.
+func (s *Server) AddTopic(topics ...Topic) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range topics {
+		s.topics[t.ID] = t
+	}
+}
+
+// FailNext makes the next n requests with the given method and path fail with
+// the status code and error, e.g.:
+//
+//	srv.FailNext(1, http.MethodGet, "anime/1", http.StatusServiceUnavailable, "")
+//
+// The path is relative to URL and does not include the query. If errField is
+// empty, a description of the status code is used.
+func (s *Server) FailNext(n int, method, path string, statusCode int, errField string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if errField == "" {
+		errField = strings.ToLower(strings.ReplaceAll(http.StatusText(statusCode), " ", "_"))
+	}
+	s.failures = append(s.failures, &failure{
+		method:    method,
+		path:      strings.Trim(path, "/"),
+		remaining: n,
+		status:    statusCode,
+		err:       errField,
+	})
+}
+
+// injectedFailure returns the next injected failure of a request, if any.
+func (s *Server) injectedFailure(method, path string) *failure {
+	for i, f := range s.failures {
+		if f.method != method || f.path != path {
+			continue
+		}
+		f.remaining--
+		if f.remaining <= 0 {
+			s.failures = append(s.failures[:i], s.failures[i+1:]...)
+		}
+		return f
+	}
+	return nil
+}
+
+// apiError is an error response of the API.
+type apiError struct {
+	status  int
+	Message string `json:"message"`
+	Err     string `json:"error"`
+}
+
+func errInvalidParameters(message string) *apiError {
+	return &apiError{status: http.StatusBadRequest, Message: message, Err: "invalid_parameters"}
+}
+
+func errNotFound() *apiError {
+	return &apiError{status: http.StatusNotFound, Err: "not_found"}
+}
+
+func errInvalidToken() *apiError {
+	return &apiError{status: http.StatusUnauthorized, Err: "invalid_token"}
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, e *apiError) {
+	if e.status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+	writeJSON(w, e.status, e)
+}
+
+// request is an API request being handled by the server.
+type request struct {
+	*http.Request
+	// path is the path relative to the API path, split into segments.
+	path []string
+	// user is the authenticated user or nil if the request uses a client
+	// ID.
+	user *user
+}
+
+func (r *request) query(key string) string { return r.URL.Query().Get(key) }
+
+func (r *request) fields() fieldSet { return parseFields(r.query("fields")) }
+
+// nsfw reports whether the nsfw query parameter allows NSFW entries.
+func (r *request) nsfw() bool {
+	nsfw, _ := strconv.ParseBool(r.query("nsfw"))
+	return nsfw
+}
+
+// me returns the authenticated user or an error if the request does not
+// access the API on behalf of a user.
+func (r *request) me() (*user, *apiError) {
+	if r.user == nil {
+		return nil, errInvalidToken()
+	}
+	return r.user, nil
+}
+
+// ServeHTTP handles requests to the fake API. It allows the Server to be
+// mounted on another http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !strings.HasPrefix(r.URL.Path, apiPath) {
+		writeError(w, errNotFound())
+		return
+	}
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, apiPath), "/")
+	if f := s.injectedFailure(r.Method, path); f != nil {
+		writeError(w, &apiError{status: f.status, Err: f.err})
+		return
+	}
+	req := &request{Request: r, path: strings.Split(path, "/")}
+	if e := s.authenticate(req); e != nil {
+		writeError(w, e)
+		return
+	}
+	v, e := s.route(req)
+	if e != nil {
+		writeError(w, e)
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+// authenticate sets the user of a request based on its access token. Requests
+// without an access token must have a client ID.
+func (s *Server) authenticate(r *request) *apiError {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		if r.Header.Get("X-MAL-CLIENT-ID") == "" {
+			return errInvalidToken()
+		}
+		return nil
+	}
+	token := strings.TrimPrefix(auth, "Bearer ")
+	username, ok := s.tokens[token]
+	if !ok {
+		return errInvalidToken()
+	}
+	u, ok := s.users[strings.ToLower(username)]
+	if !ok {
+		return errInvalidToken()
+	}
+	r.user = u
+	return nil
+}
+
+// route dispatches a request to its handler and returns the value to be
+// encoded as the JSON response.
+func (s *Server) route(r *request) (interface{}, *apiError) {
+	p := r.path
+	get, patch, del := r.Method == http.MethodGet, r.Method == http.MethodPatch, r.Method == http.MethodDelete
+	switch {
+	case get && match(p, "anime"):
+		return s.animeList(r)
+	case get && match(p, "anime", "ranking"):
+		return s.animeRanking(r)
+	case get && match(p, "anime", "suggestions"):
+		return s.animeSuggestions(r)
+	case get && match(p, "anime", "season", "*", "*"):
+		return s.animeSeasonal(r, p[2], p[3])
+	case get && match(p, "anime", "*"):
+		return s.animeDetails(r, p[1])
+	case patch && match(p, "anime", "*", "my_list_status"):
+		return s.updateMyAnimeListStatus(r, p[1])
+	case del && match(p, "anime", "*", "my_list_status"):
+		return s.deleteMyAnimeListItem(r, p[1])
+	case get && match(p, "manga"):
+		return s.mangaList(r)
+	case get && match(p, "manga", "ranking"):
+		return s.mangaRanking(r)
+	case get && match(p, "manga", "*"):
+		return s.mangaDetails(r, p[1])
+	case patch && match(p, "manga", "*", "my_list_status"):
+		return s.updateMyMangaListStatus(r, p[1])
+	case del && match(p, "manga", "*", "my_list_status"):
+		return s.deleteMyMangaListItem(r, p[1])
+	case get && match(p, "users", "*"):
+		return s.userInfo(r, p[1])
+	case get && match(p, "users", "*", "animelist"):
+		return s.userAnimeList(r, p[1])
+	case get && match(p, "users", "*", "mangalist"):
+		return s.userMangaList(r, p[1])
+	case get && match(p, "forum", "boards"):
+		return s.forum, nil
+	case get && match(p, "forum", "topics"):
+		return s.forumTopics(r)
+	case get && match(p, "forum", "topic", "*"):
+		return s.forumTopicDetails(r, p[2])
+	}
+	return nil, errNotFound()
+}
+
+// match reports whether the path segments match the pattern segments, where
+// "*" matches any segment.
+func match(path []string, pattern ...string) bool {
+	if len(path) != len(pattern) {
+		return false
+	}
+	for i := range pattern {
+		if pattern[i] != "*" && pattern[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseID parses the ID path segment of a request.
+func parseID(s string) (int, *apiError) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, errInvalidParameters(fmt.Sprintf("invalid id %q", s))
+	}
+	return id, nil
+}
+
+// paging holds the links to the previous and next page of a list.
+type paging struct {
+	Previous string `json:"previous,omitempty"`
+	Next     string `json:"next,omitempty"`
+}
+
+// page is a page of a list response.
+type page struct {
+	Data   []interface{} `json:"data"`
+	Paging paging        `json:"paging"`
+}
+
+// paginate returns the page of items requested by the limit and offset query
+// parameters of r.
+func paginate(r *request, items []interface{}) (*page, *apiError) {
+	limit, offset, e := pageParams(r)
+	if e != nil {
+		return nil, e
+	}
+	p := &page{Data: []interface{}{}}
+	if offset < len(items) {
+		end := offset + limit
+		if end > len(items) {
+			end = len(items)
+		}
+		p.Data = items[offset:end]
+	}
+	p.Paging = pagingLinks(r, limit, offset, len(items))
+	return p, nil
+}
+
+// pageParams returns the limit and offset query parameters of r.
+func pageParams(r *request) (limit, offset int, e *apiError) {
+	limit, offset = defaultLimit, 0
+	if v := r.query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxLimit {
+			return 0, 0, errInvalidParameters(fmt.Sprintf("invalid limit %q", v))
+		}
+		limit = n
+	}
+	if v := r.query("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, errInvalidParameters(fmt.Sprintf("invalid offset %q", v))
+		}
+		offset = n
+	}
+	return limit, offset, nil
+}
+
+// pagingLinks returns the links to the pages before and after the page at
+// offset of a list with total items.
+func pagingLinks(r *request, limit, offset, total int) paging {
+	link := func(offset int) string {
+		q := r.URL.Query()
+		q.Set("offset", strconv.Itoa(offset))
+		q.Set("limit", strconv.Itoa(limit))
+		u := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path, RawQuery: q.Encode()}
+		return u.String()
+	}
+	var p paging
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		p.Previous = link(prev)
+	}
+	if offset+limit < total {
+		p.Next = link(offset + limit)
+	}
+	return p
+}
+
+// nodes wraps objects as list entries with a node, as returned by the list
+// endpoints.
+func nodes(objects []object) []interface{} {
+	items := make([]interface{}, len(objects))
+	for i, o := range objects {
+		items[i] = object{"node": o}
+	}
+	return items
+}
+
+// matchesQuery reports whether a title or one of the alternative titles
+// contains the search query q, ignoring case.
+func matchesQuery(q, title string, alt mal.Titles) bool {
+	q = strings.ToLower(q)
+	titles := append([]string{title, alt.En, alt.Ja}, alt.Synonyms...)
+	for _, t := range titles {
+		if t != "" && strings.Contains(strings.ToLower(t), q) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchQuery returns the q query parameter of a search request.
+func searchQuery(r *request) (string, *apiError) {
+	q := strings.TrimSpace(r.query("q"))
+	if q == "" {
+		return "", errInvalidParameters("q is required")
+	}
+	return q, nil
+}
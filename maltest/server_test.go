@@ -0,0 +1,428 @@
+package maltest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	s := NewServer()
+	t.Cleanup(s.Close)
+	s.now = func() time.Time { return time.Date(2022, 2, 20, 10, 0, 0, 0, time.UTC) }
+	s.AddAnime(
+		mal.Anime{ID: 1, Title: "Cowboy Bebop", Synopsis: "Space.", NumEpisodes: 26, Rank: 30, Popularity: 40, MediaType: "tv", Status: "finished_airing", Mean: 8.8, StartSeason: mal.StartSeason{Year: 1998, Season: "spring"}},
+		mal.Anime{ID: 5, Title: "Cowboy Bebop: Tengoku no Tobira", NumEpisodes: 1, Rank: 200, Popularity: 600, MediaType: "movie", Status: "finished_airing", Mean: 8.4, StartSeason: mal.StartSeason{Year: 2001, Season: "summer"}},
+		mal.Anime{ID: 6, Title: "Trigun", NumEpisodes: 26, Rank: 300, Popularity: 200, MediaType: "tv", Status: "finished_airing", Mean: 8.2, StartSeason: mal.StartSeason{Year: 1998, Season: "spring"}, AlternativeTitles: mal.Titles{En: "Trigun"}},
+		mal.Anime{ID: 7, Title: "Adult Only", NSFW: "black"},
+	)
+	s.AddManga(
+		mal.Manga{ID: 1, Title: "Monster", NumVolumes: 18, Rank: 5, MediaType: "manga"},
+		mal.Manga{ID: 2, Title: "Berserk", NumVolumes: 41, Rank: 1, MediaType: "manga"},
+		mal.Manga{ID: 3, Title: "Spice and Wolf", NumVolumes: 22, Rank: 50, MediaType: "light_novel"},
+	)
+	s.AddUser(mal.User{ID: 42, Name: "alice", Location: "Athens"})
+	return s
+}
+
+func TestAnimeDetailsFields(t *testing.T) {
+	s := newTestServer(t)
+	c := s.Client("")
+
+	ctx := context.Background()
+	a, _, err := c.Anime.Details(ctx, 1)
+	if err != nil {
+		t.Fatalf("Anime.Details returned error: %v", err)
+	}
+	if want := (&mal.Anime{ID: 1, Title: "Cowboy Bebop"}); !reflect.DeepEqual(a, want) {
+		t.Errorf("Anime.Details without fields returned\nhave: %+v\nwant: %+v", a, want)
+	}
+
+	a, _, err = c.Anime.Details(ctx, 1, mal.Fields{"synopsis", "num_episodes"})
+	if err != nil {
+		t.Fatalf("Anime.Details returned error: %v", err)
+	}
+	if want := (&mal.Anime{ID: 1, Title: "Cowboy Bebop", Synopsis: "Space.", NumEpisodes: 26}); !reflect.DeepEqual(a, want) {
+		t.Errorf("Anime.Details with fields returned\nhave: %+v\nwant: %+v", a, want)
+	}
+}
+
+func TestAnimeDetailsNotFound(t *testing.T) {
+	s := newTestServer(t)
+	c := s.Client("")
+
+	_, _, err := c.Anime.Details(context.Background(), 999)
+	if !errors.Is(err, mal.ErrNotFound) {
+		t.Errorf("Anime.Details returned err = %v, want %v", err, mal.ErrNotFound)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	s := newTestServer(t)
+
+	ctx := context.Background()
+	// Without a client ID or token.
+	c := mal.NewClient(nil)
+	c.BaseURL = s.Client("").BaseURL
+	if _, _, err := c.Anime.Details(ctx, 1); !errors.Is(err, mal.ErrUnauthorized) {
+		t.Errorf("Anime.Details without credentials returned err = %v, want %v", err, mal.ErrUnauthorized)
+	}
+	// A client ID does not allow access to user data.
+	if _, _, err := s.Client("").User.MyInfo(ctx); !errors.Is(err, mal.ErrUnauthorized) {
+		t.Errorf("User.MyInfo with client ID returned err = %v, want %v", err, mal.ErrUnauthorized)
+	}
+}
+
+func TestAnimeListPaging(t *testing.T) {
+	s := newTestServer(t)
+	c := s.Client("")
+
+	ctx := context.Background()
+	anime, resp, err := c.Anime.List(ctx, "cowboy", mal.Limit(1))
+	if err != nil {
+		t.Fatalf("Anime.List returned error: %v", err)
+	}
+	if got, want := ids(anime), []int{1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Anime.List returned IDs %v, want %v", got, want)
+	}
+	if got, want := resp.NextOffset, 1; got != want {
+		t.Errorf("Anime.List returned NextOffset %d, want %d", got, want)
+	}
+
+	it := c.Anime.ListIterator("cowboy", mal.Limit(1))
+	var all []mal.Anime
+	for it.Next(ctx) {
+		all = append(all, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("ListIterator returned error: %v", err)
+	}
+	if got, want := ids(all), []int{1, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListIterator returned IDs %v, want %v", got, want)
+	}
+}
+
+func TestAnimeListSearch(t *testing.T) {
+	s := newTestServer(t)
+	c := s.Client("")
+
+	ctx := context.Background()
+	tests := []struct {
+		q       string
+		options []mal.Option
+		want    []int
+	}{
+		{q: "trigun", want: []int{6}},
+		{q: "adult", want: nil},
+		{q: "adult", options: []mal.Option{mal.NSFW(true)}, want: []int{7}},
+	}
+	for _, tt := range tests {
+		anime, _, err := c.Anime.List(ctx, tt.q, tt.options...)
+		if err != nil {
+			t.Fatalf("Anime.List(%q) returned error: %v", tt.q, err)
+		}
+		if got := ids(anime); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Anime.List(%q) returned IDs %v, want %v", tt.q, got, tt.want)
+		}
+	}
+
+	if _, _, err := c.Anime.List(ctx, ""); !errors.Is(err, mal.ErrInvalidParameters) {
+		t.Errorf("Anime.List with empty query returned err = %v, want %v", err, mal.ErrInvalidParameters)
+	}
+}
+
+func TestAnimeRanking(t *testing.T) {
+	s := newTestServer(t)
+	c := s.Client("")
+
+	ctx := context.Background()
+	tests := []struct {
+		ranking mal.AnimeRanking
+		want    []int
+	}{
+		{mal.AnimeRankingAll, []int{1, 5, 6}},
+		{mal.AnimeRankingTV, []int{1, 6}},
+		{mal.AnimeRankingMovie, []int{5}},
+		{mal.AnimeRankingByPopularity, []int{1, 6, 5}},
+		{mal.AnimeRankingAiring, nil},
+	}
+	for _, tt := range tests {
+		anime, _, err := c.Anime.Ranking(ctx, tt.ranking)
+		if err != nil {
+			t.Fatalf("Anime.Ranking(%q) returned error: %v", tt.ranking, err)
+		}
+		if got := ids(anime); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Anime.Ranking(%q) returned IDs %v, want %v", tt.ranking, got, tt.want)
+		}
+	}
+}
+
+func TestAnimeSeasonal(t *testing.T) {
+	s := newTestServer(t)
+	c := s.Client("")
+
+	anime, _, err := c.Anime.Seasonal(context.Background(), 1998, mal.AnimeSeasonSpring, mal.SortSeasonalByAnimeScore)
+	if err != nil {
+		t.Fatalf("Anime.Seasonal returned error: %v", err)
+	}
+	if got, want := ids(anime), []int{1, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Anime.Seasonal returned IDs %v, want %v", got, want)
+	}
+}
+
+func TestAnimeSuggested(t *testing.T) {
+	s := newTestServer(t)
+	s.SetSuggestions("alice", 6, 1)
+	c := s.Client("alice")
+
+	anime, _, err := c.Anime.Suggested(context.Background())
+	if err != nil {
+		t.Fatalf("Anime.Suggested returned error: %v", err)
+	}
+	if got, want := ids(anime), []int{6, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Anime.Suggested returned IDs %v, want %v", got, want)
+	}
+}
+
+func TestUpdateMyAnimeListStatus(t *testing.T) {
+	s := newTestServer(t)
+	c := s.Client("alice")
+
+	ctx := context.Background()
+	st, _, err := c.Anime.UpdateMyListStatus(ctx, 1,
+		mal.AnimeStatusWatching,
+		mal.NumEpisodesWatched(3),
+		mal.Score(8),
+		mal.Tags{"space", "jazz"},
+		mal.StartDate{Year: 2022, Month: time.February, Day: 1},
+	)
+	if err != nil {
+		t.Fatalf("Anime.UpdateMyListStatus returned error: %v", err)
+	}
+	want := mal.AnimeListStatus{
+		Status:             mal.AnimeStatusWatching,
+		NumEpisodesWatched: 3,
+		Score:              8,
+		Tags:               []string{"space", "jazz"},
+		StartDate:          mal.Date{Year: 2022, Month: time.February, Day: 1},
+		UpdatedAt:          time.Date(2022, 2, 20, 10, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(*st, want) {
+		t.Errorf("Anime.UpdateMyListStatus returned\nhave: %+v\nwant: %+v", *st, want)
+	}
+	if got, _ := s.AnimeListStatus("alice", 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("server has list status\nhave: %+v\nwant: %+v", got, want)
+	}
+
+	a, _, err := c.Anime.Details(ctx, 1, mal.Fields{"my_list_status"})
+	if err != nil {
+		t.Fatalf("Anime.Details returned error: %v", err)
+	}
+	if got := a.MyListStatus; !reflect.DeepEqual(got, want) {
+		t.Errorf("Anime.Details returned my_list_status\nhave: %+v\nwant: %+v", got, want)
+	}
+
+	if _, _, err := c.Anime.UpdateMyListStatus(ctx, 1, mal.Score(11)); !errors.Is(err, mal.ErrInvalidParameters) {
+		t.Errorf("Anime.UpdateMyListStatus with invalid score returned err = %v, want %v", err, mal.ErrInvalidParameters)
+	}
+	if _, _, err := c.Anime.UpdateMyListStatus(ctx, 999); !errors.Is(err, mal.ErrNotFound) {
+		t.Errorf("Anime.UpdateMyListStatus of missing anime returned err = %v, want %v", err, mal.ErrNotFound)
+	}
+}
+
+func TestDeleteMyAnimeListItem(t *testing.T) {
+	s := newTestServer(t)
+	s.SetAnimeListStatus("alice", 1, mal.AnimeListStatus{Status: mal.AnimeStatusCompleted})
+	c := s.Client("alice")
+
+	ctx := context.Background()
+	if _, err := c.Anime.DeleteMyListItem(ctx, 1); err != nil {
+		t.Fatalf("Anime.DeleteMyListItem returned error: %v", err)
+	}
+	if _, ok := s.AnimeListStatus("alice", 1); ok {
+		t.Errorf("anime is still in the list after Anime.DeleteMyListItem")
+	}
+	if _, err := c.Anime.DeleteMyListItem(ctx, 1); !errors.Is(err, mal.ErrNotFound) {
+		t.Errorf("second Anime.DeleteMyListItem returned err = %v, want %v", err, mal.ErrNotFound)
+	}
+}
+
+func TestUserAnimeList(t *testing.T) {
+	s := newTestServer(t)
+	s.SetAnimeListStatus("alice", 1, mal.AnimeListStatus{Status: mal.AnimeStatusCompleted, Score: 7, Comments: "Great"})
+	s.SetAnimeListStatus("alice", 6, mal.AnimeListStatus{Status: mal.AnimeStatusCompleted, Score: 9})
+	s.SetAnimeListStatus("alice", 5, mal.AnimeListStatus{Status: mal.AnimeStatusPlanToWatch})
+
+	ctx := context.Background()
+	list, _, err := s.Client("").User.AnimeList(ctx, "alice", mal.AnimeStatusCompleted, mal.SortAnimeListByListScore)
+	if err != nil {
+		t.Fatalf("User.AnimeList returned error: %v", err)
+	}
+	want := []mal.UserAnime{
+		{Anime: mal.Anime{ID: 6, Title: "Trigun"}, Status: mal.AnimeListStatus{Status: mal.AnimeStatusCompleted, Score: 9}},
+		{Anime: mal.Anime{ID: 1, Title: "Cowboy Bebop"}, Status: mal.AnimeListStatus{Status: mal.AnimeStatusCompleted, Score: 7}},
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("User.AnimeList returned\nhave: %+v\nwant: %+v", list, want)
+	}
+
+	// The comments are only returned when requested.
+	list, _, err = s.Client("alice").User.AnimeList(ctx, "@me", mal.Fields{"list_status{comments}"}, mal.Limit(1))
+	if err != nil {
+		t.Fatalf("User.AnimeList returned error: %v", err)
+	}
+	if got, want := list[0].Status.Comments, "Great"; got != want {
+		t.Errorf("User.AnimeList returned comments %q, want %q", got, want)
+	}
+
+	if _, _, err := s.Client("").User.AnimeList(ctx, "bob"); !errors.Is(err, mal.ErrNotFound) {
+		t.Errorf("User.AnimeList of missing user returned err = %v, want %v", err, mal.ErrNotFound)
+	}
+}
+
+func TestMangaRoundTrip(t *testing.T) {
+	s := newTestServer(t)
+	c := s.Client("alice")
+
+	ctx := context.Background()
+	manga, _, err := c.Manga.Ranking(ctx, mal.MangaRankingManga)
+	if err != nil {
+		t.Fatalf("Manga.Ranking returned error: %v", err)
+	}
+	if got, want := mangaIDs(manga), []int{2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Manga.Ranking returned IDs %v, want %v", got, want)
+	}
+
+	if _, _, err := c.Manga.UpdateMyListStatus(ctx, 3, mal.MangaStatusReading, mal.NumVolumesRead(2)); err != nil {
+		t.Fatalf("Manga.UpdateMyListStatus returned error: %v", err)
+	}
+	list, _, err := c.User.MangaList(ctx, "@me")
+	if err != nil {
+		t.Fatalf("User.MangaList returned error: %v", err)
+	}
+	if len(list) != 1 || list[0].Manga.ID != 3 || list[0].Status.NumVolumesRead != 2 {
+		t.Errorf("User.MangaList returned %+v, want manga 3 with 2 volumes read", list)
+	}
+	if _, err := c.Manga.DeleteMyListItem(ctx, 3); err != nil {
+		t.Fatalf("Manga.DeleteMyListItem returned error: %v", err)
+	}
+	if _, ok := s.MangaListStatus("alice", 3); ok {
+		t.Errorf("manga is still in the list after Manga.DeleteMyListItem")
+	}
+}
+
+func TestMyInfo(t *testing.T) {
+	s := newTestServer(t)
+	c := s.Client("alice")
+
+	u, _, err := c.User.MyInfo(context.Background())
+	if err != nil {
+		t.Fatalf("User.MyInfo returned error: %v", err)
+	}
+	if want := (&mal.User{ID: 42, Name: "alice", Location: "Athens"}); !reflect.DeepEqual(u, want) {
+		t.Errorf("User.MyInfo returned\nhave: %+v\nwant: %+v", u, want)
+	}
+}
+
+func TestForum(t *testing.T) {
+	s := newTestServer(t)
+	s.SetBoards(mal.Forum{Categories: []mal.ForumCategory{{Title: "MyAnimeList", Boards: []mal.ForumBoard{{ID: 17, Title: "Updates"}}}}})
+	day := func(d int) time.Time { return time.Date(2022, 2, d, 0, 0, 0, 0, time.UTC) }
+	s.AddTopic(
+		Topic{Topic: mal.Topic{ID: 1, Title: "Welcome", LastPostCreatedAt: day(1)}, BoardID: 17, Posts: []mal.Post{{ID: 1, Body: "Hi"}, {ID: 2, Body: "Hello"}}},
+		Topic{Topic: mal.Topic{ID: 2, Title: "Rules", LastPostCreatedAt: day(2)}, BoardID: 17},
+		Topic{Topic: mal.Topic{ID: 3, Title: "Welcome back", LastPostCreatedAt: day(3)}, BoardID: 5},
+	)
+	c := s.Client("")
+
+	ctx := context.Background()
+	f, _, err := c.Forum.Boards(ctx)
+	if err != nil {
+		t.Fatalf("Forum.Boards returned error: %v", err)
+	}
+	if got, want := f.Categories[0].Boards[0].ID, 17; got != want {
+		t.Errorf("Forum.Boards returned board %d, want %d", got, want)
+	}
+
+	topics, _, err := c.Forum.Topics(ctx, mal.Query("welcome"))
+	if err != nil {
+		t.Fatalf("Forum.Topics returned error: %v", err)
+	}
+	if len(topics) != 2 || topics[0].ID != 3 || topics[1].ID != 1 {
+		t.Errorf("Forum.Topics returned %+v, want topics 3 and 1", topics)
+	}
+	topics, _, err = c.Forum.Topics(ctx, mal.BoardID(17))
+	if err != nil {
+		t.Fatalf("Forum.Topics returned error: %v", err)
+	}
+	if len(topics) != 2 || topics[0].ID != 2 || topics[1].ID != 1 {
+		t.Errorf("Forum.Topics returned %+v, want topics 2 and 1", topics)
+	}
+	if _, _, err := c.Forum.Topics(ctx); !errors.Is(err, mal.ErrInvalidParameters) {
+		t.Errorf("Forum.Topics without options returned err = %v, want %v", err, mal.ErrInvalidParameters)
+	}
+
+	d, resp, err := c.Forum.TopicDetails(ctx, 1, mal.Limit(1), mal.Offset(1))
+	if err != nil {
+		t.Fatalf("Forum.TopicDetails returned error: %v", err)
+	}
+	if d.Title != "Welcome" || len(d.Posts) != 1 || d.Posts[0].ID != 2 {
+		t.Errorf("Forum.TopicDetails returned %+v, want the second post of topic 1", d)
+	}
+	if got, want := resp.PrevOffset, 0; got != want {
+		t.Errorf("Forum.TopicDetails returned PrevOffset %d, want %d", got, want)
+	}
+}
+
+func TestFailNext(t *testing.T) {
+	s := newTestServer(t)
+	s.FailNext(2, http.MethodGet, "anime/1", http.StatusServiceUnavailable, "")
+	c := s.Client("", mal.WithRetryPolicy(mal.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}))
+
+	_, resp, err := c.Anime.Details(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Anime.Details returned error: %v", err)
+	}
+	if got, want := resp.Attempts, 3; got != want {
+		t.Errorf("Anime.Details took %d attempts, want %d", got, want)
+	}
+}
+
+func TestFailNextErrorBody(t *testing.T) {
+	s := newTestServer(t)
+	s.FailNext(1, http.MethodGet, "anime/1", http.StatusTooManyRequests, "")
+	c := s.Client("")
+
+	_, resp, err := c.Anime.Details(context.Background(), 1)
+	if !errors.Is(err, mal.ErrRateLimited) {
+		t.Fatalf("Anime.Details returned err = %v, want %v", err, mal.ErrRateLimited)
+	}
+	body, _ := io.ReadAll(resp.Response.Body)
+	if !strings.Contains(string(body), `"error":"too_many_requests"`) {
+		t.Errorf("error response body = %s, want error too_many_requests", body)
+	}
+}
+
+func ids(anime []mal.Anime) []int {
+	var ids []int
+	for _, a := range anime {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+func mangaIDs(manga []mal.Manga) []int {
+	var ids []int
+	for _, m := range manga {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
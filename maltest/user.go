@@ -0,0 +1,152 @@
+package maltest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// userInfo returns the information of the authenticated user. Like the real
+// API, only @me is supported.
+func (s *Server) userInfo(r *request, name string) (interface{}, *apiError) {
+	if name != "@me" {
+		return nil, errInvalidParameters(fmt.Sprintf("invalid user %q, only @me is supported", name))
+	}
+	u, e := r.me()
+	if e != nil {
+		return nil, e
+	}
+	return filterObject(toObject(u.User), r.fields(), defaultUserFields), nil
+}
+
+// listOwner returns the user whose list is requested. Name can be @me for the
+// authenticated user.
+func (s *Server) listOwner(r *request, name string) (*user, *apiError) {
+	if name == "@me" {
+		return r.me()
+	}
+	u, ok := s.users[strings.ToLower(name)]
+	if !ok {
+		return nil, errNotFound()
+	}
+	return u, nil
+}
+
+// userListEntry returns a list entry which consists of a node and its
+// list_status. The default list_status fields are always included.
+func userListEntry(node, listStatus object, fs fieldSet, defaults []string) object {
+	return object{
+		"node":        node,
+		"list_status": filterObject(listStatus, fs["list_status"], defaults),
+	}
+}
+
+func (s *Server) userAnimeList(r *request, name string) (interface{}, *apiError) {
+	owner, e := s.listOwner(r, name)
+	if e != nil {
+		return nil, e
+	}
+	status := r.query("status")
+	if status != "" && !animeStatuses[status] {
+		return nil, errInvalidParameters(fmt.Sprintf("invalid status %q", status))
+	}
+	type entry struct {
+		anime  mal.Anime
+		status mal.AnimeListStatus
+	}
+	var entries []entry
+	for id, st := range owner.animeList {
+		if status != "" && string(st.Status) != status {
+			continue
+		}
+		a, ok := s.anime[id]
+		if !ok {
+			a = mal.Anime{ID: id}
+		}
+		entries = append(entries, entry{anime: a, status: st})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].anime.ID < entries[j].anime.ID })
+
+	var less func(a, b entry) bool
+	switch sortBy := r.query("sort"); sortBy {
+	case "", "anime_id":
+	case "list_score":
+		less = func(a, b entry) bool { return a.status.Score > b.status.Score }
+	case "list_updated_at":
+		less = func(a, b entry) bool { return a.status.UpdatedAt.After(b.status.UpdatedAt) }
+	case "anime_title":
+		less = func(a, b entry) bool { return a.anime.Title < b.anime.Title }
+	case "anime_start_date":
+		less = func(a, b entry) bool { return a.anime.StartDate.After(b.anime.StartDate) }
+	default:
+		return nil, errInvalidParameters(fmt.Sprintf("invalid sort %q", sortBy))
+	}
+	if less != nil {
+		sort.SliceStable(entries, func(i, j int) bool { return less(entries[i], entries[j]) })
+	}
+
+	fs := r.fields()
+	nodeFields := fs.without("list_status")
+	items := make([]interface{}, len(entries))
+	for i, e := range entries {
+		node := s.animeObject(e.anime, r.user, nodeFields)
+		items[i] = userListEntry(node, toObject(e.status), fs, defaultAnimeListStatusFields)
+	}
+	return paginate(r, items)
+}
+
+func (s *Server) userMangaList(r *request, name string) (interface{}, *apiError) {
+	owner, e := s.listOwner(r, name)
+	if e != nil {
+		return nil, e
+	}
+	status := r.query("status")
+	if status != "" && !mangaStatuses[status] {
+		return nil, errInvalidParameters(fmt.Sprintf("invalid status %q", status))
+	}
+	type entry struct {
+		manga  mal.Manga
+		status mal.MangaListStatus
+	}
+	var entries []entry
+	for id, st := range owner.mangaList {
+		if status != "" && string(st.Status) != status {
+			continue
+		}
+		m, ok := s.manga[id]
+		if !ok {
+			m = mal.Manga{ID: id}
+		}
+		entries = append(entries, entry{manga: m, status: st})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].manga.ID < entries[j].manga.ID })
+
+	var less func(a, b entry) bool
+	switch sortBy := r.query("sort"); sortBy {
+	case "", "manga_id":
+	case "list_score":
+		less = func(a, b entry) bool { return a.status.Score > b.status.Score }
+	case "list_updated_at":
+		less = func(a, b entry) bool { return a.status.UpdatedAt.After(b.status.UpdatedAt) }
+	case "manga_title":
+		less = func(a, b entry) bool { return a.manga.Title < b.manga.Title }
+	case "manga_start_date":
+		less = func(a, b entry) bool { return a.manga.StartDate.After(b.manga.StartDate) }
+	default:
+		return nil, errInvalidParameters(fmt.Sprintf("invalid sort %q", sortBy))
+	}
+	if less != nil {
+		sort.SliceStable(entries, func(i, j int) bool { return less(entries[i], entries[j]) })
+	}
+
+	fs := r.fields()
+	nodeFields := fs.without("list_status")
+	items := make([]interface{}, len(entries))
+	for i, e := range entries {
+		node := s.mangaObject(e.manga, r.user, nodeFields)
+		items[i] = userListEntry(node, toObject(e.status), fs, defaultMangaListStatusFields)
+	}
+	return paginate(r, items)
+}
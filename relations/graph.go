@@ -0,0 +1,140 @@
+package relations
+
+import "sort"
+
+// Graph is the graph of the related entries of a franchise.
+type Graph struct {
+	// Seed is the entry the crawl started from.
+	Seed Key
+
+	// Nodes are the entries of the graph sorted by depth, kind and ID, with
+	// the seed first.
+	Nodes []*Node
+
+	// Edges are the relations between the nodes in the order they were
+	// found. Both directions of a relation are usually present, e.g. a
+	// sequel edge from A to B and a prequel edge from B to A.
+	Edges []Edge
+
+	byKey map[Key]*Node
+}
+
+func newGraph(seed Key) *Graph {
+	return &Graph{Seed: seed, byKey: make(map[Key]*Node)}
+}
+
+func (g *Graph) add(n *Node) {
+	g.Nodes = append(g.Nodes, n)
+	g.byKey[n.Key] = n
+}
+
+// sort sorts the nodes by depth, kind and ID.
+func (g *Graph) sort() {
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		a, b := g.Nodes[i], g.Nodes[j]
+		if a.Depth != b.Depth {
+			return a.Depth < b.Depth
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.ID < b.ID
+	})
+}
+
+// Node returns the node of k or nil if it is not part of the graph.
+func (g *Graph) Node(k Key) *Node { return g.byKey[k] }
+
+// Related returns the edges from k to the entries related to it.
+func (g *Graph) Related(k Key) []Edge {
+	var edges []Edge
+	for _, e := range g.Edges {
+		if e.From == k {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// WatchOrder returns the anime of the graph in a suggested watch order. Anime
+// are ordered chronologically by StartDate, with anime without a start date
+// last, except that a sequel always comes after its prequel even if it started
+// airing earlier. Anime whose sequel and prequel relations form a cycle come
+// after all the others.
+func (g *Graph) WatchOrder() []*Node {
+	var anime []*Node
+	for _, n := range g.Nodes {
+		if n.Kind == KindAnime {
+			anime = append(anime, n)
+		}
+	}
+	sort.SliceStable(anime, func(i, j int) bool { return chronological(anime[i], anime[j]) })
+
+	// after maps each anime to the anime that have to be watched before it.
+	after := make(map[Key]map[Key]bool)
+	must := func(first, then Key) {
+		if first == then || g.byKey[first] == nil || g.byKey[then] == nil {
+			return
+		}
+		if after[then] == nil {
+			after[then] = make(map[Key]bool)
+		}
+		after[then][first] = true
+	}
+	for _, e := range g.Edges {
+		if e.From.Kind != KindAnime || e.To.Kind != KindAnime {
+			continue
+		}
+		switch e.Type {
+		case Sequel:
+			must(e.From, e.To)
+		case Prequel:
+			must(e.To, e.From)
+		}
+	}
+
+	// Pick the earliest anime whose prequels have all been picked. If a
+	// cycle leaves no such anime, the earliest remaining one is picked.
+	order := make([]*Node, 0, len(anime))
+	done := make(map[Key]bool, len(anime))
+	for len(order) < len(anime) {
+		var pick *Node
+		for _, n := range anime {
+			if done[n.Key] {
+				continue
+			}
+			if pick == nil {
+				pick = n
+			}
+			if ready(after[n.Key], done) {
+				pick = n
+				break
+			}
+		}
+		done[pick.Key] = true
+		order = append(order, pick)
+	}
+	return order
+}
+
+// ready reports whether all of the anime in before are done.
+func ready(before map[Key]bool, done map[Key]bool) bool {
+	for k := range before {
+		if !done[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// chronological reports whether a started before b. Nodes without a start
+// date come last and ties are broken by ID.
+func chronological(a, b *Node) bool {
+	switch {
+	case a.StartDate.IsZero() != b.StartDate.IsZero():
+		return b.StartDate.IsZero()
+	case a.StartDate != b.StartDate:
+		return a.StartDate.Before(b.StartDate)
+	}
+	return a.ID < b.ID
+}
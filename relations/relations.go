@@ -0,0 +1,286 @@
+// Package relations crawls the related anime and manga of an entry to build
+// the graph of a whole franchise: its sequels, prequels, side stories,
+// adaptations and so on.
+//
+// The details endpoints of the API only return the entries that are directly
+// related to an anime or manga. Crawl follows those relations outwards from a
+// seed, requesting the details of every entry it finds with bounded
+// concurrency and depth:
+//
+//	c := mal.NewClient(oauth2Client)
+//	g, err := relations.Crawl(ctx, c, relations.Anime(1), relations.Options{MaxDepth: 3})
+//	// ...
+//	for _, n := range g.WatchOrder() {
+//		fmt.Println(n.StartDate, n.Title)
+//	}
+package relations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/nstratos/go-myanimelist/mal"
+)
+
+// Kind is the kind of an entry, anime or manga.
+type Kind string
+
+// The kinds of entries.
+const (
+	KindAnime Kind = "anime"
+	KindManga Kind = "manga"
+)
+
+// Key identifies an anime or manga.
+type Key struct {
+	Kind Kind
+	ID   int
+}
+
+// Anime returns the key of the anime with the given ID.
+func Anime(id int) Key { return Key{Kind: KindAnime, ID: id} }
+
+// Manga returns the key of the manga with the given ID.
+func Manga(id int) Key { return Key{Kind: KindManga, ID: id} }
+
+// String returns the key in the format "anime/1".
+func (k Key) String() string { return fmt.Sprintf("%s/%d", k.Kind, k.ID) }
+
+// RelationType is the type of the relation between two entries as reported by
+// the API.
+type RelationType string
+
+// The relation types. The type of an edge describes the target in relation to
+// the source, e.g. an edge from A to B with type Sequel means that B is the
+// sequel of A.
+const (
+	Sequel             RelationType = "sequel"
+	Prequel            RelationType = "prequel"
+	AlternativeSetting RelationType = "alternative_setting"
+	AlternativeVersion RelationType = "alternative_version"
+	SideStory          RelationType = "side_story"
+	ParentStory        RelationType = "parent_story"
+	Summary            RelationType = "summary"
+	FullStory          RelationType = "full_story"
+	SpinOff            RelationType = "spin_off"
+	Adaptation         RelationType = "adaptation"
+	Character          RelationType = "character"
+	Other              RelationType = "other"
+)
+
+// Node is an anime or manga of the graph.
+type Node struct {
+	Key
+	Title     string
+	MediaType string // For example "tv", "movie" or "light_novel".
+	StartDate mal.Date
+
+	// Depth is the number of relations between the seed and the node.
+	Depth int
+
+	// Anime holds the details of the node if it is an anime and Manga if it
+	// is a manga. They include the Fields of the crawl.
+	Anime *mal.Anime
+	Manga *mal.Manga
+}
+
+// Edge is a relation from one entry to another.
+type Edge struct {
+	From, To Key
+	Type     RelationType
+
+	// TypeFormatted is the human readable relation type, e.g. "Side story".
+	TypeFormatted string
+}
+
+// Options configure a crawl.
+type Options struct {
+	// MaxDepth is the maximum number of relations to follow from the seed. A
+	// value of zero or less means that there is no limit and the whole
+	// franchise is crawled.
+	MaxDepth int
+
+	// Concurrency is the maximum number of details requests in flight.
+	// Defaults to 4.
+	Concurrency int
+
+	// Types are the relation types to follow. If empty, all relations are
+	// followed.
+	Types []RelationType
+
+	// AnimeOnly skips the related manga, such as the source material of an
+	// anime.
+	AnimeOnly bool
+
+	// Fields are requested in addition to the fields needed for the graph
+	// and are available through Node.Anime and Node.Manga.
+	Fields mal.Fields
+}
+
+// crawlFields are the fields needed to build the graph.
+var crawlFields = mal.Fields{"media_type", "start_date", "related_anime", "related_manga"}
+
+const defaultConcurrency = 4
+
+// Crawl builds the graph of the entries that are related to seed. It requests
+// the details of the seed and then of the related entries, level by level,
+// until there are no new entries or MaxDepth is reached. Related entries that
+// do not exist anymore are left out.
+//
+// Crawl stops at the first error, which is returned together with a nil
+// Graph.
+func Crawl(ctx context.Context, c *mal.Client, seed Key, opts Options) (*Graph, error) {
+	if seed.Kind != KindAnime && seed.Kind != KindManga {
+		return nil, fmt.Errorf("relations: invalid kind %q", seed.Kind)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	cr := &crawler{
+		c:      c,
+		opts:   opts,
+		fields: append(append(mal.Fields{}, crawlFields...), opts.Fields...),
+		follow: make(map[RelationType]bool, len(opts.Types)),
+	}
+	for _, t := range opts.Types {
+		cr.follow[t] = true
+	}
+
+	g := newGraph(seed)
+	// edges are kept until the crawl ends since they can point to entries
+	// that turn out to be missing or are beyond MaxDepth.
+	var edges []Edge
+	seen := map[Key]bool{seed: true}
+	level := []Key{seed}
+	for depth := 0; len(level) > 0; depth++ {
+		nodes, err := cr.fetch(ctx, level)
+		if err != nil {
+			return nil, err
+		}
+		var next []Key
+		for i, n := range nodes {
+			if n == nil {
+				if level[i] == seed {
+					return nil, fmt.Errorf("relations: getting %s: %w", seed, mal.ErrNotFound)
+				}
+				continue
+			}
+			n.Depth = depth
+			g.add(n)
+			for _, e := range cr.edges(n) {
+				edges = append(edges, e)
+				if seen[e.To] || (opts.MaxDepth > 0 && depth >= opts.MaxDepth) {
+					continue
+				}
+				seen[e.To] = true
+				next = append(next, e.To)
+			}
+		}
+		level = next
+	}
+	for _, e := range edges {
+		if g.Node(e.To) != nil {
+			g.Edges = append(g.Edges, e)
+		}
+	}
+	g.sort()
+	return g, nil
+}
+
+type crawler struct {
+	c      *mal.Client
+	opts   Options
+	fields mal.Fields
+	follow map[RelationType]bool
+}
+
+// fetch requests the details of keys concurrently. The node of an entry that
+// does not exist is nil.
+func (cr *crawler) fetch(ctx context.Context, keys []Key) ([]*Node, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		nodes    = make([]*Node, len(keys))
+		sem      = make(chan struct{}, cr.opts.Concurrency)
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for i, k := range keys {
+		wg.Add(1)
+		go func(i int, k Key) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+			n, err := cr.details(ctx, k)
+			if err != nil && !errors.Is(err, mal.ErrNotFound) {
+				once.Do(func() {
+					firstErr = fmt.Errorf("relations: getting %s: %w", k, err)
+					cancel()
+				})
+				return
+			}
+			nodes[i] = n
+		}(i, k)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// The parent context may have been canceled before any request failed.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// details requests the details of the entry k and returns it as a node.
+func (cr *crawler) details(ctx context.Context, k Key) (*Node, error) {
+	if k.Kind == KindManga {
+		m, _, err := cr.c.Manga.Details(ctx, k.ID, cr.fields)
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Key: k, Title: m.Title, MediaType: string(m.MediaType), StartDate: m.StartDate, Manga: m}, nil
+	}
+	a, _, err := cr.c.Anime.Details(ctx, k.ID, cr.fields)
+	if err != nil {
+		return nil, err
+	}
+	return &Node{Key: k, Title: a.Title, MediaType: string(a.MediaType), StartDate: a.StartDate, Anime: a}, nil
+}
+
+// edges returns the relations of n that should be followed.
+func (cr *crawler) edges(n *Node) []Edge {
+	var relatedAnime []mal.RelatedAnime
+	var relatedManga []mal.RelatedManga
+	if n.Anime != nil {
+		relatedAnime, relatedManga = n.Anime.RelatedAnime, n.Anime.RelatedManga
+	} else {
+		relatedAnime, relatedManga = n.Manga.RelatedAnime, n.Manga.RelatedManga
+	}
+	var edges []Edge
+	add := func(to Key, typ, formatted string) {
+		t := RelationType(typ)
+		if len(cr.follow) > 0 && !cr.follow[t] {
+			return
+		}
+		edges = append(edges, Edge{From: n.Key, To: to, Type: t, TypeFormatted: formatted})
+	}
+	for _, r := range relatedAnime {
+		add(Anime(r.Node.ID), r.RelationType, r.RelationTypeFormatted)
+	}
+	if !cr.opts.AnimeOnly {
+		for _, r := range relatedManga {
+			add(Manga(r.Node.ID), r.RelationType, r.RelationTypeFormatted)
+		}
+	}
+	return edges
+}
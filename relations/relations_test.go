@@ -0,0 +1,210 @@
+package relations
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-myanimelist/mal"
+	"github.com/nstratos/go-myanimelist/maltest"
+)
+
+func relatedAnime(id int, typ RelationType) mal.RelatedAnime {
+	return mal.RelatedAnime{Node: mal.Anime{ID: id}, RelationType: string(typ)}
+}
+
+// newFranchise returns a server with a franchise of a TV series with a second
+// season, a movie sequel, a side story and the manga it is based on. The movie
+// relates to an anime which does not exist.
+func newFranchise(t *testing.T) *maltest.Server {
+	t.Helper()
+	s := maltest.NewServer()
+	t.Cleanup(s.Close)
+	s.AddAnime(
+		mal.Anime{
+			ID: 1, Title: "Season 1", MediaType: mal.AnimeMediaTypeTV,
+			StartDate:    mal.Date{Year: 2010, Month: time.April, Day: 1},
+			RelatedAnime: []mal.RelatedAnime{relatedAnime(2, Sequel), relatedAnime(4, SideStory)},
+			RelatedManga: []mal.RelatedManga{{Node: mal.Manga{ID: 10}, RelationType: "adaptation", RelationTypeFormatted: "Adaptation"}},
+		},
+		mal.Anime{
+			ID: 2, Title: "Season 2", MediaType: mal.AnimeMediaTypeTV,
+			StartDate:    mal.Date{Year: 2012, Month: time.January, Day: 10},
+			RelatedAnime: []mal.RelatedAnime{relatedAnime(1, Prequel), relatedAnime(3, Sequel)},
+		},
+		mal.Anime{
+			// The start date is wrong but the movie is still a sequel of
+			// the second season.
+			ID: 3, Title: "Movie", MediaType: mal.AnimeMediaTypeMovie,
+			StartDate:    mal.Date{Year: 2011, Month: time.December},
+			RelatedAnime: []mal.RelatedAnime{relatedAnime(2, Prequel), relatedAnime(99, Other)},
+		},
+		mal.Anime{
+			ID: 4, Title: "Side Story", MediaType: mal.AnimeMediaTypeOVA,
+			StartDate:    mal.Date{Year: 2011, Month: time.June, Day: 1},
+			RelatedAnime: []mal.RelatedAnime{relatedAnime(1, ParentStory)},
+		},
+	)
+	s.AddManga(mal.Manga{
+		ID: 10, Title: "Manga", MediaType: mal.MangaMediaTypeManga,
+		StartDate:    mal.Date{Year: 2008},
+		RelatedAnime: []mal.RelatedAnime{relatedAnime(1, Adaptation)},
+	})
+	s.AddUser(mal.User{Name: "alice"})
+	return s
+}
+
+func keys(nodes []*Node) []Key {
+	var kk []Key
+	for _, n := range nodes {
+		kk = append(kk, n.Key)
+	}
+	return kk
+}
+
+func TestCrawl(t *testing.T) {
+	s := newFranchise(t)
+	g, err := Crawl(context.Background(), s.Client("alice"), Anime(1), Options{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("Crawl returned error: %v", err)
+	}
+	want := []Key{Anime(1), Anime(2), Anime(4), Manga(10), Anime(3)}
+	if got := keys(g.Nodes); !reflect.DeepEqual(got, want) {
+		t.Errorf("Crawl returned nodes %v, want %v", got, want)
+	}
+	movie := g.Node(Anime(3))
+	if movie == nil {
+		t.Fatal("Node(anime/3) returned nil")
+	}
+	if movie.Depth != 2 || movie.Title != "Movie" || movie.MediaType != "movie" || movie.Anime == nil {
+		t.Errorf("Node(anime/3) = %+v, want depth 2 movie with details", movie)
+	}
+	if m := g.Node(Manga(10)); m == nil || m.Manga == nil || m.StartDate != (mal.Date{Year: 2008}) {
+		t.Errorf("Node(manga/10) = %+v, want manga with details", m)
+	}
+	if g.Node(Anime(99)) != nil {
+		t.Error("Node(anime/99) is not nil for an anime that does not exist")
+	}
+
+	wantRelated := []Edge{
+		{From: Anime(1), To: Anime(2), Type: Sequel},
+		{From: Anime(1), To: Anime(4), Type: SideStory},
+		{From: Anime(1), To: Manga(10), Type: Adaptation, TypeFormatted: "Adaptation"},
+	}
+	if got := g.Related(Anime(1)); !reflect.DeepEqual(got, wantRelated) {
+		t.Errorf("Related(anime/1) = %+v, want %+v", got, wantRelated)
+	}
+	if got := g.Related(Anime(3)); !reflect.DeepEqual(got, []Edge{{From: Anime(3), To: Anime(2), Type: Prequel}}) {
+		t.Errorf("Related(anime/3) = %+v, want only the prequel", got)
+	}
+
+	wantOrder := []Key{Anime(1), Anime(4), Anime(2), Anime(3)}
+	if got := keys(g.WatchOrder()); !reflect.DeepEqual(got, wantOrder) {
+		t.Errorf("WatchOrder = %v, want %v", got, wantOrder)
+	}
+}
+
+func TestCrawlOptions(t *testing.T) {
+	s := newFranchise(t)
+	c := s.Client("alice")
+	tests := []struct {
+		name string
+		seed Key
+		opts Options
+		want []Key
+	}{
+		{
+			name: "max depth",
+			seed: Anime(1),
+			opts: Options{MaxDepth: 1},
+			want: []Key{Anime(1), Anime(2), Anime(4), Manga(10)},
+		},
+		{
+			name: "anime only",
+			seed: Anime(1),
+			opts: Options{AnimeOnly: true},
+			want: []Key{Anime(1), Anime(2), Anime(4), Anime(3)},
+		},
+		{
+			name: "types",
+			seed: Anime(1),
+			opts: Options{Types: []RelationType{Sequel, Prequel}},
+			want: []Key{Anime(1), Anime(2), Anime(3)},
+		},
+		{
+			name: "manga seed",
+			seed: Manga(10),
+			opts: Options{MaxDepth: 1},
+			want: []Key{Manga(10), Anime(1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := Crawl(context.Background(), c, tt.seed, tt.opts)
+			if err != nil {
+				t.Fatalf("Crawl returned error: %v", err)
+			}
+			if got := keys(g.Nodes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Crawl returned nodes %v, want %v", got, tt.want)
+			}
+			for _, e := range g.Edges {
+				if g.Node(e.From) == nil || g.Node(e.To) == nil {
+					t.Errorf("Crawl returned edge %+v to a node outside the graph", e)
+				}
+			}
+		})
+	}
+}
+
+func TestCrawlErrors(t *testing.T) {
+	s := newFranchise(t)
+	c := s.Client("alice")
+	ctx := context.Background()
+
+	if _, err := Crawl(ctx, c, Anime(99), Options{}); !errors.Is(err, mal.ErrNotFound) {
+		t.Errorf("Crawl of missing seed returned error %v, want %v", err, mal.ErrNotFound)
+	}
+	if _, err := Crawl(ctx, c, Key{Kind: "novel", ID: 1}, Options{}); err == nil {
+		t.Error("Crawl with invalid kind expected error")
+	}
+
+	s.FailNext(1, http.MethodGet, "anime/2", http.StatusInternalServerError, "")
+	g, err := Crawl(ctx, c, Anime(1), Options{})
+	if err == nil {
+		t.Fatal("Crawl expected internal error, got no error")
+	}
+	if g != nil {
+		t.Errorf("Crawl returned graph %+v together with error", g)
+	}
+}
+
+func TestWatchOrder(t *testing.T) {
+	node := func(id int, d mal.Date) *Node { return &Node{Key: Anime(id), StartDate: d} }
+	g := newGraph(Anime(1))
+	for _, n := range []*Node{
+		node(1, mal.Date{Year: 2000}),
+		node(2, mal.Date{}), // Unknown start date.
+		node(3, mal.Date{Year: 1999}),
+		node(4, mal.Date{Year: 2001}),
+		node(5, mal.Date{Year: 2001}),
+	} {
+		g.add(n)
+	}
+	g.add(&Node{Key: Manga(1), StartDate: mal.Date{Year: 1990}})
+	g.Edges = []Edge{
+		// 3 started before 1 but is its sequel.
+		{From: Anime(1), To: Anime(3), Type: Sequel},
+		// A cycle between 4 and 5 is broken by start date and ID once
+		// no other anime is left.
+		{From: Anime(4), To: Anime(5), Type: Prequel},
+		{From: Anime(5), To: Anime(4), Type: Prequel},
+		{From: Manga(1), To: Anime(1), Type: Adaptation},
+	}
+	want := []Key{Anime(1), Anime(3), Anime(2), Anime(4), Anime(5)}
+	if got := keys(g.WatchOrder()); !reflect.DeepEqual(got, want) {
+		t.Errorf("WatchOrder = %v, want %v", got, want)
+	}
+}